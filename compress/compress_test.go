@@ -1,6 +1,9 @@
 package compress
 
 import (
+	"bytes"
+	"io"
+	"slices"
 	"testing"
 	"time"
 
@@ -46,6 +49,58 @@ func TestCompress(t *testing.T) {
 	}
 }
 
+func TestStream(t *testing.T) {
+	type testCase struct {
+		algo  CompressionAlgorithm
+		level CompressionLevel
+	}
+	testCases := []testCase{
+		{CompressionAlgoZstd, CompressionLevelZstdFastest},
+		{CompressionAlgoZstd, CompressionLevelZstdBest},
+		{CompressionAlgoZstdCgo, CompressionLevelZstdCgoFastest},
+		{CompressionAlgoZstdCgo, CompressionLevelZstdCgoBest},
+	}
+	data := bytes.Repeat(loremIpsumDolor, 100)
+
+	for _, tc := range testCases {
+		t.Run(tc.algo.String()+"-"+tc.level.String(), func(t *testing.T) {
+			c, err := New(tc.algo, tc.level)
+			require.NoError(t, err)
+			t.Cleanup(func() { _ = c.Close() })
+
+			// streams are compatible with whole buffers compression
+			var compressed bytes.Buffer
+			w, err := NewWriter(&compressed, tc.algo, tc.level)
+			require.NoError(t, err)
+			for chunk := range slices.Chunk(data, 1000) {
+				_, err := w.Write(chunk)
+				require.NoError(t, err)
+			}
+			require.NoError(t, w.Close())
+			require.Less(t, compressed.Len(), len(data))
+			decompressed, err := c.Decompress(compressed.Bytes())
+			require.NoError(t, err)
+			require.Equal(t, string(data), string(decompressed))
+
+			buffer, err := c.Compress(data)
+			require.NoError(t, err)
+			r, err := NewReader(bytes.NewReader(buffer), tc.algo)
+			require.NoError(t, err)
+			decompressed, err = io.ReadAll(r)
+			require.NoError(t, err)
+			require.NoError(t, r.Close())
+			require.Equal(t, string(data), string(decompressed))
+		})
+	}
+
+	t.Run("invalid settings", func(t *testing.T) {
+		_, err := NewWriter(io.Discard, CompressionAlgorithm(42), CompressionLevelZstdBest)
+		require.Error(t, err)
+		_, err = NewReader(bytes.NewReader(nil), CompressionAlgorithm(42))
+		require.Error(t, err)
+	})
+}
+
 func TestSerialization(t *testing.T) {
 	type testCase struct {
 		algo, level        string
@@ -0,0 +1,49 @@
+package compress
+
+import (
+	"fmt"
+	"io"
+
+	zstdcgo "github.com/DataDog/zstd"
+	"github.com/klauspost/compress/zstd"
+)
+
+// NewWriter returns a writer compressing the data written to it into w, for compressing data without holding it in
+// memory. The output can be decompressed by Decompress or NewReader.
+// Close must be called for flushing the compressed data, it doesn't close w.
+func NewWriter(w io.Writer, algo CompressionAlgorithm, level CompressionLevel) (io.WriteCloser, error) {
+	algo, level, err := NewSettings(algo.String(), level.String())
+	if err != nil {
+		return nil, err
+	}
+	switch algo {
+	case CompressionAlgoZstd:
+		encoder, err := zstd.NewWriter(w, zstd.WithEncoderLevel(zstd.EncoderLevel(level)))
+		if err != nil {
+			return nil, fmt.Errorf("cannot create zstd encoder: %w", err)
+		}
+		return encoder, nil
+	case CompressionAlgoZstdCgo:
+		return zstdcgo.NewWriterLevel(w, int(level)), nil
+	default:
+		return nil, fmt.Errorf("unknown compression algorithm: %d", algo)
+	}
+}
+
+// NewReader returns a reader decompressing the data read from r, compressed with the given algorithm, for
+// decompressing data without holding it in memory.
+// Close must be called for releasing resources, it doesn't close r.
+func NewReader(r io.Reader, algo CompressionAlgorithm) (io.ReadCloser, error) {
+	switch algo {
+	case CompressionAlgoZstd:
+		decoder, err := zstd.NewReader(r, zstd.WithDecoderConcurrency(1))
+		if err != nil {
+			return nil, fmt.Errorf("cannot create zstd decoder: %w", err)
+		}
+		return decoder.IOReadCloser(), nil
+	case CompressionAlgoZstdCgo:
+		return zstdcgo.NewReader(r), nil
+	default:
+		return nil, fmt.Errorf("unknown compression algorithm: %d", algo)
+	}
+}
@@ -2,9 +2,12 @@ package filemanager
 
 import (
 	"context"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"io"
+	"math"
+	"net/http"
 	"net/url"
 	"os"
 	"path"
@@ -12,10 +15,14 @@ import (
 	"time"
 
 	"github.com/Azure/azure-storage-blob-go/azblob"
+	"github.com/samber/lo"
 
 	"github.com/rudderlabs/rudder-go-kit/logger"
 )
 
+// azureCopyPollInterval is the interval between checks of the status of pending copies
+const azureCopyPollInterval = 100 * time.Millisecond
+
 type AzureBlobConfig struct {
 	Container      string
 	Prefix         string
@@ -57,7 +64,11 @@ func (m *AzureBlobManager) Upload(ctx context.Context, file *os.File, prefixes .
 	return m.UploadReader(ctx, objName, file)
 }
 
-func (m *AzureBlobManager) UploadReader(ctx context.Context, objName string, rdr io.Reader) (UploadedFile, error) {
+func (m *AzureBlobManager) UploadReader(ctx context.Context, objName string, rdr io.Reader, opts ...UploadOption) (UploadedFile, error) {
+	uploadOpts, err := applyUploadOptions(opts...)
+	if err != nil {
+		return UploadedFile{}, err
+	}
 	containerURL, err := m.getContainerURL()
 	if err != nil {
 		return UploadedFile{}, err
@@ -76,15 +87,38 @@ func (m *AzureBlobManager) UploadReader(ctx context.Context, objName string, rdr
 
 	// Here's how to upload a blob.
 	blobURL := containerURL.NewBlockBlobURL(objName)
+	headers := azblob.BlobHTTPHeaders{ContentType: uploadOpts.contentType}
+	var accessConditions azblob.BlobAccessConditions
+	if uploadOpts.ifNoneMatch != "" {
+		accessConditions.ModifiedAccessConditions.IfNoneMatch = azblob.ETagAny
+	}
+	if uploadOpts.ifMatch != "" {
+		accessConditions.ModifiedAccessConditions.IfMatch = azblob.ETag(quoteETag(uploadOpts.ifMatch))
+	}
 	if file, ok := rdr.(*os.File); ok {
+		blockSize, parallelism := m.transfer.uploadParts(4*1024*1024, 16)
 		_, err = azblob.UploadFileToBlockBlob(ctx, file, blobURL, azblob.UploadToBlockBlobOptions{
-			BlockSize:   4 * 1024 * 1024,
-			Parallelism: 16,
+			BlockSize:        blockSize,
+			Parallelism:      uint16(min(parallelism, math.MaxUint16)),
+			Progress:         m.progressFunc("upload", objName),
+			BlobHTTPHeaders:  headers,
+			Metadata:         uploadOpts.metadata,
+			AccessConditions: accessConditions,
 		})
 	} else {
-		_, err = azblob.UploadStreamToBlockBlob(ctx, rdr, blobURL, azblob.UploadStreamToBlockBlobOptions{})
+		bufferSize, maxBuffers := m.transfer.uploadParts(1024*1024, 1)
+		_, err = azblob.UploadStreamToBlockBlob(ctx, m.progressReader("upload", objName, rdr), blobURL, azblob.UploadStreamToBlockBlobOptions{
+			BufferSize:       int(bufferSize),
+			MaxBuffers:       maxBuffers,
+			BlobHTTPHeaders:  headers,
+			Metadata:         uploadOpts.metadata,
+			AccessConditions: accessConditions,
+		})
 	}
 	if err != nil {
+		if isAzurePreconditionFailed(err, uploadOpts) {
+			return UploadedFile{}, ErrPreConditionFailed
+		}
 		return UploadedFile{}, err
 	}
 
@@ -115,6 +149,16 @@ func (m *AzureBlobManager) Download(ctx context.Context, output io.WriterAt, key
 		}
 	}
 
+	if file, ok := output.(*os.File); ok && (m.transfer.PartSize > 0 || m.transfer.DownloadConcurrency > 0) {
+		blockSize, parallelism := m.transfer.downloadParts(azblob.BlobDefaultDownloadBlockSize, 5)
+		return azblob.DownloadBlobToFile(ctx, blobURL.BlobURL, offset, count, file, azblob.DownloadFromBlobOptions{
+			BlockSize:                  blockSize,
+			Parallelism:                uint16(min(parallelism, math.MaxUint16)),
+			Progress:                   m.progressFunc("download", key),
+			RetryReaderOptionsPerBlock: azblob.RetryReaderOptions{MaxRetryRequests: 20},
+		})
+	}
+
 	// Here's how to download the blob
 	downloadResponse, err := blobURL.Download(ctx, offset, count, azblob.BlobAccessConditions{}, false, azblob.ClientProvidedKeyOptions{})
 	if err != nil {
@@ -123,11 +167,213 @@ func (m *AzureBlobManager) Download(ctx context.Context, output io.WriterAt, key
 
 	// NOTE: automatically retries are performed if the connection fails
 	bodyStream := downloadResponse.Body(azblob.RetryReaderOptions{MaxRetryRequests: 20})
-	_, err = io.Copy(&writerAtAdapter{w: output}, bodyStream)
+	_, err = io.Copy(&writerAtAdapter{w: m.progressWriterAt("download", key, output)}, bodyStream)
 	_ = bodyStream.Close()
 	return err
 }
 
+func (m *AzureBlobManager) OpenReader(ctx context.Context, key string, opts ...DownloadOption) (io.ReadCloser, error) {
+	downloadOpts := applyDownloadOptions(opts...)
+	containerURL, err := m.getContainerURL()
+	if err != nil {
+		return nil, err
+	}
+
+	offset := int64(0)
+	count := int64(azblob.CountToEnd)
+	if downloadOpts.isRangeRequest {
+		offset = downloadOpts.offset
+		if downloadOpts.length > 0 {
+			count = downloadOpts.length
+		}
+	}
+
+	blobURL := containerURL.NewBlockBlobURL(key)
+	downloadResponse, err := blobURL.Download(ctx, offset, count, azblob.BlobAccessConditions{}, false, azblob.ClientProvidedKeyOptions{})
+	if err != nil {
+		if isAzureBlobNotFound(err) {
+			return nil, ErrKeyNotFound
+		}
+		return nil, err
+	}
+	// NOTE: automatically retries are performed if the connection fails
+	return downloadResponse.Body(azblob.RetryReaderOptions{MaxRetryRequests: 20}), nil
+}
+
+func (m *AzureBlobManager) Stat(ctx context.Context, key string) (*FileInfo, error) {
+	containerURL, err := m.getContainerURL()
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, m.getTimeout())
+	defer cancel()
+
+	props, err := containerURL.NewBlockBlobURL(key).GetProperties(ctx, azblob.BlobAccessConditions{}, azblob.ClientProvidedKeyOptions{})
+	if err != nil {
+		if isAzureBlobNotFound(err) {
+			return nil, ErrKeyNotFound
+		}
+		return nil, err
+	}
+	return &FileInfo{
+		Key:          key,
+		LastModified: props.LastModified(),
+		Size:         props.ContentLength(),
+		ETag:         trimETag(string(props.ETag())),
+		MD5:          hex.EncodeToString(props.ContentMD5()),
+		ContentType:  props.ContentType(),
+		StorageClass: props.AccessTier(),
+		Metadata:     normalizeMetadata(props.NewMetadata()),
+	}, nil
+}
+
+// Copy copies a blob server-side, waiting for the copy to complete
+func (m *AzureBlobManager) Copy(ctx context.Context, srcKey, dstKey string) error {
+	containerURL, err := m.getContainerURL()
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, m.getTimeout())
+	defer cancel()
+
+	dstURL := containerURL.NewBlockBlobURL(dstKey)
+	// the source URL carries the SAS token, if any, for the copy to be authorized
+	resp, err := dstURL.StartCopyFromURL(ctx, containerURL.NewBlobURL(srcKey).URL(), nil,
+		azblob.ModifiedAccessConditions{}, azblob.BlobAccessConditions{}, azblob.DefaultAccessTier, nil,
+	)
+	if err != nil {
+		var storageError azblob.StorageError
+		if isAzureBlobNotFound(err) ||
+			(errors.As(err, &storageError) && storageError.ServiceCode() == azblob.ServiceCodeCannotVerifyCopySource) {
+			return ErrKeyNotFound
+		}
+		return fmt.Errorf("starting copy: %w", err)
+	}
+
+	status := resp.CopyStatus()
+	for status == azblob.CopyStatusPending {
+		select {
+		case <-ctx.Done():
+			abortCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), m.getTimeout())
+			defer cancel()
+			_, _ = dstURL.AbortCopyFromURL(abortCtx, resp.CopyID(), azblob.LeaseAccessConditions{})
+			return ctx.Err()
+		case <-time.After(azureCopyPollInterval):
+		}
+		props, err := dstURL.GetProperties(ctx, azblob.BlobAccessConditions{}, azblob.ClientProvidedKeyOptions{})
+		if err != nil {
+			return fmt.Errorf("getting copy status: %w", err)
+		}
+		status = props.CopyStatus()
+		if status != azblob.CopyStatusPending && status != azblob.CopyStatusSuccess {
+			return fmt.Errorf("copy %s: %s", status, props.CopyStatusDescription())
+		}
+	}
+	if status != azblob.CopyStatusSuccess {
+		return fmt.Errorf("copy %s", status)
+	}
+	return nil
+}
+
+func (m *AzureBlobManager) Move(ctx context.Context, srcKey, dstKey string) error {
+	return moveByCopy(ctx, m, srcKey, dstKey)
+}
+
+// Compose concatenates blobs by streaming them into the destination blob
+func (m *AzureBlobManager) Compose(ctx context.Context, dstKey string, srcKeys ...string) error {
+	if err := validateCompose(dstKey, srcKeys); err != nil {
+		return err
+	}
+	return streamCompose(ctx, m, dstKey, srcKeys)
+}
+
+// PresignGet returns a blob URL carrying a SAS token with read permission.
+// Presigning requires an account key, i.e. it is not supported if the manager is configured with a SAS token.
+func (m *AzureBlobManager) PresignGet(_ context.Context, key string, ttl time.Duration) (string, error) {
+	return m.presign(http.MethodGet, key, ttl)
+}
+
+// PresignPut returns a blob URL carrying a SAS token with create and write permissions.
+// Note that Azure requires PUT requests to specify the type of the blob through the x-ms-blob-type: BlockBlob header.
+// Presigning requires an account key, i.e. it is not supported if the manager is configured with a SAS token.
+func (m *AzureBlobManager) PresignPut(_ context.Context, key string, ttl time.Duration) (string, error) {
+	return m.presign(http.MethodPut, key, ttl)
+}
+
+func (m *AzureBlobManager) presign(method, key string, ttl time.Duration) (string, error) {
+	if err := validatePresign(method, ttl); err != nil {
+		return "", err
+	}
+	if m.config.UseSASTokens {
+		return "", fmt.Errorf("%w: presigning without an account key", ErrNotSupported)
+	}
+	if m.config.Container == "" {
+		return "", errors.New("no container configured")
+	}
+	credential, err := azblob.NewSharedKeyCredential(m.config.AccountName, m.config.AccountKey)
+	if err != nil {
+		return "", err
+	}
+
+	permissions := azblob.BlobSASPermissions{Read: true}
+	if method == http.MethodPut {
+		permissions = azblob.BlobSASPermissions{Create: true, Write: true}
+	}
+	protocol := azblob.SASProtocolHTTPS
+	if m.config.DisableSSL != nil && *m.config.DisableSSL {
+		protocol = azblob.SASProtocolHTTPSandHTTP
+	}
+	sas, err := azblob.BlobSASSignatureValues{
+		Protocol:      protocol,
+		ExpiryTime:    time.Now().UTC().Add(ttl),
+		ContainerName: m.config.Container,
+		BlobName:      key,
+		Permissions:   permissions.String(),
+	}.NewSASQueryParameters(credential)
+	if err != nil {
+		return "", fmt.Errorf("creating SAS token: %w", err)
+	}
+
+	containerURL, err := m.getContainerURL()
+	if err != nil {
+		return "", err
+	}
+	blobURLParts := azblob.NewBlobURLParts(containerURL.NewBlobURL(key).URL())
+	blobURLParts.SAS = sas
+	u := blobURLParts.URL()
+	return u.String(), nil
+}
+
+// isAzurePreconditionFailed returns true if a conditional write failed because its condition wasn't met.
+// If-none-match conditions on existing blobs fail with BlobAlreadyExists, while if-match conditions on missing blobs
+// fail with BlobNotFound.
+func isAzurePreconditionFailed(err error, uploadOpts uploadOptions) bool {
+	var storageError azblob.StorageError
+	if !errors.As(err, &storageError) {
+		return false
+	}
+	switch storageError.ServiceCode() {
+	case azblob.ServiceCodeConditionNotMet, azblob.ServiceCodeBlobAlreadyExists:
+		return true
+	case azblob.ServiceCodeBlobNotFound:
+		return uploadOpts.ifMatch != ""
+	}
+	return false
+}
+
+// isAzureBlobNotFound returns true if the error is caused by a missing blob.
+// Responses to HEAD requests have no body, thus the status code is checked too.
+func isAzureBlobNotFound(err error) bool {
+	var storageError azblob.StorageError
+	if !errors.As(err, &storageError) {
+		return false
+	}
+	return storageError.ServiceCode() == azblob.ServiceCodeBlobNotFound ||
+		(storageError.Response() != nil && storageError.Response().StatusCode == http.StatusNotFound)
+}
+
 func (m *AzureBlobManager) Delete(ctx context.Context, keys []string) error {
 	containerURL, err := m.getContainerURL()
 	if err != nil {
@@ -374,10 +620,23 @@ func (l *azureBlobListSession) Next() ([]*FileInfo, error) {
 		fileObjects = make([]*FileInfo, 0)
 		for idx := range response.Segment.BlobItems {
 			if strings.Compare(response.Segment.BlobItems[idx].Name, l.startAfter) > 0 {
-				fileObjects = append(fileObjects, &FileInfo{response.Segment.BlobItems[idx].Name, response.Segment.BlobItems[idx].Properties.LastModified})
+				fileObjects = append(fileObjects, azureBlobFileInfo(&response.Segment.BlobItems[idx]))
 				maxItems--
 			}
 		}
 	}
 	return fileObjects, nil
 }
+
+func azureBlobFileInfo(item *azblob.BlobItemInternal) *FileInfo {
+	return &FileInfo{
+		Key:          item.Name,
+		LastModified: item.Properties.LastModified,
+		Size:         lo.FromPtr(item.Properties.ContentLength),
+		ETag:         trimETag(string(item.Properties.Etag)),
+		MD5:          hex.EncodeToString(item.Properties.ContentMD5),
+		ContentType:  lo.FromPtr(item.Properties.ContentType),
+		StorageClass: string(item.Properties.AccessTier),
+		Metadata:     normalizeMetadata(item.Metadata),
+	}
+}
@@ -12,6 +12,7 @@ type baseManager struct {
 	logger         logger.Logger
 	timeout        time.Duration
 	defaultTimeout func() time.Duration
+	transfer       TransferSettings
 }
 
 func (manager *baseManager) SetTimeout(timeout time.Duration) {
@@ -61,6 +61,9 @@ func GetProviderConfigFromEnv(opts ProviderConfigOpts) map[string]any {
 		providerConfig["endPoint"] = config.GetStringVar("", "DO_SPACES_ENDPOINT")
 		providerConfig["accessKeyID"] = config.GetStringVar("", "DO_SPACES_ACCESS_KEY_ID")
 		providerConfig["accessKey"] = config.GetStringVar("", "DO_SPACES_SECRET_ACCESS_KEY")
+	case "LOCAL":
+		providerConfig["directory"] = opts.Bucket
+		providerConfig["prefix"] = opts.Prefix
 	}
 
 	return providerConfig
@@ -0,0 +1,118 @@
+package filemanager
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// streamCopy copies a file by reading and uploading it again through the file manager, preserving its content type
+// and metadata. It is used by providers lacking a server-side copy.
+func streamCopy(ctx context.Context, fm FileManager, srcKey, dstKey string) error {
+	info, err := fm.Stat(ctx, srcKey)
+	if err != nil {
+		return err
+	}
+	r, err := fm.OpenReader(ctx, srcKey)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = r.Close() }()
+
+	if _, err := fm.UploadReader(ctx, dstKey, r, WithContentType(info.ContentType), WithMetadata(info.Metadata)); err != nil {
+		return fmt.Errorf("uploading copy: %w", err)
+	}
+	return nil
+}
+
+// streamCompose concatenates files by reading them one after the other while uploading the result through the file
+// manager. It is used by providers lacking a server-side compose, or whose constraints (e.g. minimum part sizes)
+// aren't met by the source files.
+func streamCompose(ctx context.Context, fm FileManager, dstKey string, srcKeys []string) error {
+	// checking that all sources exist beforehand, for not uploading a partial file
+	for _, key := range srcKeys {
+		if _, err := fm.Stat(ctx, key); err != nil {
+			return fmt.Errorf("stat %q: %w", key, err)
+		}
+	}
+	r := &concatReader{ctx: ctx, fm: fm, keys: srcKeys}
+	defer func() { _ = r.Close() }()
+
+	if _, err := fm.UploadReader(ctx, dstKey, r); err != nil {
+		return fmt.Errorf("uploading composed file: %w", err)
+	}
+	return nil
+}
+
+// moveByCopy moves a file by copying it and deleting the source afterwards.
+// Moving a file onto itself is rejected, since deleting the source would delete the file: keys must be normalized
+// the way the provider does, so that keys referring to the same file are equal.
+func moveByCopy(ctx context.Context, fm FileManager, srcKey, dstKey string) error {
+	if srcKey == dstKey {
+		return fmt.Errorf("source and destination keys are the same: %q", srcKey)
+	}
+	if err := fm.Copy(ctx, srcKey, dstKey); err != nil {
+		return err
+	}
+	if err := fm.Delete(ctx, []string{srcKey}); err != nil {
+		return fmt.Errorf("deleting source after copy: %w", err)
+	}
+	return nil
+}
+
+func validateCompose(dstKey string, srcKeys []string) error {
+	if len(srcKeys) == 0 {
+		return errors.New("no source keys to compose")
+	}
+	for _, key := range srcKeys {
+		if key == dstKey {
+			return fmt.Errorf("destination key %q cannot be one of the source keys", dstKey)
+		}
+	}
+	return nil
+}
+
+// concatReader reads the files with the given keys one after the other, opening each one only when needed
+type concatReader struct {
+	ctx  context.Context
+	fm   FileManager
+	keys []string
+
+	current io.ReadCloser
+}
+
+func (r *concatReader) Read(p []byte) (int, error) {
+	for {
+		if r.current == nil {
+			if len(r.keys) == 0 {
+				return 0, io.EOF
+			}
+			rc, err := r.fm.OpenReader(r.ctx, r.keys[0])
+			if err != nil {
+				return 0, fmt.Errorf("opening %q: %w", r.keys[0], err)
+			}
+			r.current = rc
+			r.keys = r.keys[1:]
+		}
+		n, err := r.current.Read(p)
+		if errors.Is(err, io.EOF) {
+			err = r.current.Close()
+			r.current = nil
+			if n > 0 || err != nil {
+				return n, err
+			}
+			continue
+		}
+		return n, err
+	}
+}
+
+func (r *concatReader) Close() error {
+	if r.current == nil {
+		return nil
+	}
+	err := r.current.Close()
+	r.current = nil
+	return err
+}
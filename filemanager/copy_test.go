@@ -0,0 +1,45 @@
+package filemanager
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// copyRecorder records the copies and deletions done through it
+type copyRecorder struct {
+	FileManager
+	calls []string
+}
+
+func (r *copyRecorder) Copy(_ context.Context, srcKey, dstKey string) error {
+	r.calls = append(r.calls, "copy "+srcKey+" "+dstKey)
+	return nil
+}
+
+func (r *copyRecorder) Delete(_ context.Context, keys []string) error {
+	for _, key := range keys {
+		r.calls = append(r.calls, "delete "+key)
+	}
+	return nil
+}
+
+func TestMoveByCopy(t *testing.T) {
+	t.Run("different keys", func(t *testing.T) {
+		fm := &copyRecorder{}
+		require.NoError(t, moveByCopy(context.Background(), fm, "src", "dst"))
+		require.Equal(t, []string{"copy src dst", "delete src"}, fm.calls)
+	})
+
+	t.Run("same keys", func(t *testing.T) {
+		fm := &copyRecorder{}
+		require.ErrorContains(t, moveByCopy(context.Background(), fm, "key", "key"), "source and destination keys are the same")
+		require.Empty(t, fm.calls, "the file must be neither copied onto itself nor deleted")
+	})
+
+	t.Run("keys referring to the same S3 object", func(t *testing.T) {
+		err := (&S3Manager{}).Move(context.Background(), "/key", " key")
+		require.ErrorContains(t, err, "source and destination keys are the same")
+	})
+}
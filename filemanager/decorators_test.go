@@ -0,0 +1,192 @@
+package filemanager_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/aws/smithy-go"
+	smithyhttp "github.com/aws/smithy-go/transport/http"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/api/googleapi"
+
+	"github.com/rudderlabs/rudder-go-kit/filemanager"
+	"github.com/rudderlabs/rudder-go-kit/logger"
+	"github.com/rudderlabs/rudder-go-kit/retryablehttp"
+	"github.com/rudderlabs/rudder-go-kit/stats"
+	"github.com/rudderlabs/rudder-go-kit/stats/memstats"
+)
+
+func newLocalManager(t *testing.T) filemanager.FileManager {
+	t.Helper()
+	fm, err := filemanager.New(&filemanager.Settings{
+		Provider: "LOCAL",
+		Config:   map[string]any{"directory": t.TempDir()},
+		Logger:   logger.NOP,
+	})
+	require.NoError(t, err)
+	return fm
+}
+
+func TestWithInstrumentation(t *testing.T) {
+	ctx := context.Background()
+	store, err := memstats.New()
+	require.NoError(t, err)
+	fm := filemanager.WithInstrumentation(newLocalManager(t), store)
+
+	_, err = fm.UploadReader(ctx, "some/key", strings.NewReader("hello world"))
+	require.NoError(t, err)
+	r, err := fm.OpenReader(ctx, "some/key")
+	require.NoError(t, err)
+	_, err = io.ReadAll(r)
+	require.NoError(t, err)
+	require.NoError(t, r.Close())
+	_, err = fm.Stat(ctx, "missing")
+	require.ErrorIs(t, err, filemanager.ErrKeyNotFound)
+	_, err = fm.ListFilesWithPrefix(ctx, "", "some/", 10).Next()
+	require.NoError(t, err)
+
+	durationTags := func(operation, errorClass string) stats.Tags {
+		return stats.Tags{"provider": "LOCAL", "operation": operation, "error_class": errorClass}
+	}
+	require.Len(t, store.Get("filemanager_operation_duration", durationTags("upload_reader", "none")).Durations(), 1)
+	require.Len(t, store.Get("filemanager_operation_duration", durationTags("open_reader", "none")).Durations(), 1)
+	require.Len(t, store.Get("filemanager_operation_duration", durationTags("stat", "not_found")).Durations(), 1)
+	require.Len(t, store.Get("filemanager_operation_duration", durationTags("list", "none")).Durations(), 1)
+
+	bytesTags := func(operation, direction string) stats.Tags {
+		return stats.Tags{"provider": "LOCAL", "operation": operation, "direction": direction}
+	}
+	require.EqualValues(t, 11, store.Get("filemanager_bytes_transferred", bytesTags("upload_reader", "upload")).LastValue())
+	require.EqualValues(t, 11, store.Get("filemanager_bytes_transferred", bytesTags("open_reader", "download")).LastValue())
+
+	f, err := os.CreateTemp(t.TempDir(), "download")
+	require.NoError(t, err)
+	defer func() { _ = f.Close() }()
+	require.NoError(t, fm.Download(ctx, f, "some/key"))
+	require.EqualValues(t, 11, store.Get("filemanager_bytes_transferred", bytesTags("download", "download")).LastValue())
+}
+
+func TestWithRetry(t *testing.T) {
+	ctx := context.Background()
+	conf := &retryablehttp.Config{MaxRetry: 3, InitialInterval: time.Millisecond, MaxInterval: time.Millisecond, Multiplier: 1}
+
+	t.Run("transient errors are retried", func(t *testing.T) {
+		flaky := &flakyManager{FileManager: newLocalManager(t), failures: 2, err: syscall.ECONNRESET}
+		var notified []string
+		fm := filemanager.WithRetry(flaky, conf, filemanager.WithRetryNotify(func(operation string, _ error, _ time.Duration) {
+			notified = append(notified, operation)
+		}))
+
+		_, err := fm.UploadReader(ctx, "some/key", bytes.NewReader([]byte("hello")))
+		require.NoError(t, err)
+		require.Equal(t, 3, flaky.calls)
+		require.Equal(t, []string{"upload_reader", "upload_reader"}, notified)
+
+		r, err := fm.OpenReader(ctx, "some/key")
+		require.NoError(t, err)
+		data, err := io.ReadAll(r)
+		require.NoError(t, err)
+		require.NoError(t, r.Close())
+		require.Equal(t, "hello", string(data), "the reader should be rewound before retrying")
+	})
+
+	t.Run("retries are exhausted", func(t *testing.T) {
+		flaky := &flakyManager{FileManager: newLocalManager(t), failures: 10, err: syscall.ECONNRESET}
+		fm := filemanager.WithRetry(flaky, conf)
+		_, err := fm.UploadReader(ctx, "some/key", bytes.NewReader([]byte("hello")))
+		require.ErrorIs(t, err, syscall.ECONNRESET)
+		require.Equal(t, 4, flaky.calls)
+	})
+
+	t.Run("non transient errors are not retried", func(t *testing.T) {
+		flaky := &flakyManager{FileManager: newLocalManager(t), failures: 10, err: filemanager.ErrPreConditionFailed}
+		fm := filemanager.WithRetry(flaky, conf)
+		_, err := fm.UploadReader(ctx, "some/key", bytes.NewReader([]byte("hello")))
+		require.ErrorIs(t, err, filemanager.ErrPreConditionFailed)
+		require.Equal(t, 1, flaky.calls)
+	})
+
+	t.Run("readers which can't be rewound are not retried", func(t *testing.T) {
+		flaky := &flakyManager{FileManager: newLocalManager(t), failures: 10, err: syscall.ECONNRESET}
+		fm := filemanager.WithRetry(flaky, conf)
+		_, err := fm.UploadReader(ctx, "some/key", io.LimitReader(strings.NewReader("hello"), 5))
+		require.ErrorIs(t, err, syscall.ECONNRESET)
+		require.Equal(t, 1, flaky.calls)
+	})
+
+	t.Run("instrumented retries", func(t *testing.T) {
+		store, err := memstats.New()
+		require.NoError(t, err)
+		flaky := &flakyManager{FileManager: newLocalManager(t), failures: 2, err: syscall.ECONNRESET}
+		fm := filemanager.WithInstrumentation(filemanager.WithRetry(flaky, conf), store)
+
+		r := bytes.NewReader([]byte("hello world"))
+		_, err = r.Seek(6, io.SeekStart)
+		require.NoError(t, err)
+		_, err = fm.UploadReader(ctx, "some/key", r)
+		require.NoError(t, err)
+		require.Equal(t, 3, flaky.calls, "the instrumented reader should still be rewindable")
+		tags := stats.Tags{"provider": "unknown", "operation": "upload_reader", "direction": "upload"}
+		require.EqualValues(t, 5, store.Get("filemanager_bytes_transferred", tags).LastValue())
+	})
+}
+
+func TestErrorClass(t *testing.T) {
+	s3Err := func(code string, status int) error {
+		return &smithy.OperationError{
+			ServiceID:     "S3",
+			OperationName: "PutObject",
+			Err: &smithyhttp.ResponseError{
+				Response: &smithyhttp.Response{Response: &http.Response{StatusCode: status}},
+				Err:      &smithy.GenericAPIError{Code: code},
+			},
+		}
+	}
+	for _, tc := range []struct {
+		err       error
+		class     string
+		transient bool
+	}{
+		{nil, filemanager.ErrorClassNone, false},
+		{fmt.Errorf("wrapped: %w", filemanager.ErrKeyNotFound), filemanager.ErrorClassNotFound, false},
+		{filemanager.ErrPreConditionFailed, filemanager.ErrorClassPreconditionFailed, false},
+		{context.Canceled, filemanager.ErrorClassCanceled, false},
+		{context.DeadlineExceeded, filemanager.ErrorClassTimeout, true},
+		{s3Err("SlowDown", http.StatusServiceUnavailable), filemanager.ErrorClassThrottled, true},
+		{s3Err("InternalError", http.StatusInternalServerError), filemanager.ErrorClassServer, true},
+		{s3Err("AccessDenied", http.StatusForbidden), filemanager.ErrorClassClient, false},
+		{&googleapi.Error{Code: http.StatusTooManyRequests}, filemanager.ErrorClassThrottled, true},
+		{&googleapi.Error{Code: http.StatusBadGateway}, filemanager.ErrorClassServer, true},
+		{fmt.Errorf("read: %w", syscall.ECONNRESET), filemanager.ErrorClassConnection, true},
+		{errors.New("something"), filemanager.ErrorClassUnknown, false},
+	} {
+		require.Equal(t, tc.class, filemanager.ErrorClass(tc.err), "%v", tc.err)
+		require.Equal(t, tc.transient, filemanager.IsTransientError(tc.err), "%v", tc.err)
+	}
+}
+
+// flakyManager fails uploads with err, consuming the reader, for the given number of times
+type flakyManager struct {
+	filemanager.FileManager
+	failures int
+	err      error
+	calls    int
+}
+
+func (m *flakyManager) UploadReader(ctx context.Context, objName string, rdr io.Reader, opts ...filemanager.UploadOption) (filemanager.UploadedFile, error) {
+	m.calls++
+	if m.calls <= m.failures {
+		_, _ = io.Copy(io.Discard, rdr)
+		return filemanager.UploadedFile{}, m.err
+	}
+	return m.FileManager.UploadReader(ctx, objName, rdr, opts...)
+}
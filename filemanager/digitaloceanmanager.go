@@ -15,7 +15,6 @@ import (
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
-	s3manager "github.com/aws/aws-sdk-go-v2/feature/s3/manager"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
 	"github.com/aws/aws-sdk-go-v2/service/s3/types"
 	"github.com/aws/smithy-go"
@@ -135,23 +134,18 @@ func (m *digitalOceanManager) Download(ctx context.Context, output io.WriterAt,
 		return fmt.Errorf("digitalocean client: %w", err)
 	}
 
-	downloader := s3manager.NewDownloader(client)
+	downloader := s3Downloader(client, m.transfer)
 
 	ctx, cancel := context.WithTimeout(ctx, m.getTimeout())
 	defer cancel()
+	output = m.progressWriterAt("download", key, output)
 
 	getObjectInput := &s3.GetObjectInput{
 		Bucket: aws.String(m.config.Bucket),
 		Key:    aws.String(key),
 	}
 	if downloadOpts.isRangeRequest {
-		var rangeOpt string
-		if downloadOpts.length > 0 {
-			rangeOpt = fmt.Sprintf("bytes=%d-%d", downloadOpts.offset, downloadOpts.offset+downloadOpts.length-1)
-		} else {
-			rangeOpt = fmt.Sprintf("bytes=%d-", downloadOpts.offset)
-		}
-		getObjectInput.Range = aws.String(rangeOpt)
+		getObjectInput.Range = aws.String(downloadOpts.httpRange())
 	}
 
 	_, err = downloader.Download(ctx, output, getObjectInput)
@@ -165,34 +159,76 @@ func (m *digitalOceanManager) Download(ctx context.Context, output io.WriterAt,
 	return nil
 }
 
+func (m *digitalOceanManager) OpenReader(ctx context.Context, key string, opts ...DownloadOption) (io.ReadCloser, error) {
+	downloadOpts := applyDownloadOptions(opts...)
+	client, err := m.getClient(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("digitalocean client: %w", err)
+	}
+
+	getObjectInput := &s3.GetObjectInput{
+		Bucket: aws.String(m.config.Bucket),
+		Key:    aws.String(key),
+	}
+	if downloadOpts.isRangeRequest {
+		getObjectInput.Range = aws.String(downloadOpts.httpRange())
+	}
+	output, err := client.GetObject(ctx, getObjectInput)
+	if err != nil {
+		var nsk *types.NoSuchKey
+		if errors.As(err, &nsk) {
+			return nil, ErrKeyNotFound
+		}
+		return nil, fmt.Errorf("failed to open DigitalOcean Spaces object: %w", err)
+	}
+	return output.Body, nil
+}
+
 func (m *digitalOceanManager) Upload(ctx context.Context, file *os.File, prefixes ...string) (UploadedFile, error) {
 	objName := path.Join(m.config.Prefix, path.Join(prefixes...), path.Base(file.Name()))
 	return m.UploadReader(ctx, objName, file)
 }
 
-func (m *digitalOceanManager) UploadReader(ctx context.Context, fileName string, rdr io.Reader) (UploadedFile, error) {
+func (m *digitalOceanManager) UploadReader(ctx context.Context, fileName string, rdr io.Reader, opts ...UploadOption) (UploadedFile, error) {
 	if m.config.Bucket == "" {
 		return UploadedFile{}, errors.New("no storage bucket configured to uploader")
 	}
 
+	uploadOpts, err := applyUploadOptions(opts...)
+	if err != nil {
+		return UploadedFile{}, err
+	}
 	uploadInput := &s3.PutObjectInput{
-		ACL:    types.ObjectCannedACLBucketOwnerFullControl,
-		Bucket: aws.String(m.config.Bucket),
-		Key:    aws.String(fileName),
-		Body:   rdr,
+		ACL:      types.ObjectCannedACLBucketOwnerFullControl,
+		Bucket:   aws.String(m.config.Bucket),
+		Key:      aws.String(fileName),
+		Body:     m.progressReader("upload", fileName, rdr),
+		Metadata: uploadOpts.metadata,
+	}
+	if uploadOpts.contentType != "" {
+		uploadInput.ContentType = aws.String(uploadOpts.contentType)
+	}
+	if uploadOpts.ifNoneMatch != "" {
+		uploadInput.IfNoneMatch = aws.String(uploadOpts.ifNoneMatch)
+	}
+	if uploadOpts.ifMatch != "" {
+		uploadInput.IfMatch = aws.String(quoteETag(uploadOpts.ifMatch))
 	}
 
 	client, err := m.getClient(ctx)
 	if err != nil {
 		return UploadedFile{}, fmt.Errorf("digitalocean client: %w", err)
 	}
-	uploader := s3manager.NewUploader(client)
+	uploader := s3Uploader(client, m.transfer)
 
 	ctx, cancel := context.WithTimeout(ctx, m.getTimeout())
 	defer cancel()
 
 	output, err := uploader.Upload(ctx, uploadInput)
 	if err != nil {
+		if isS3PreconditionFailed(err, uploadOpts) {
+			return UploadedFile{}, ErrPreConditionFailed
+		}
 		var regionError *aws.MissingRegionError
 		if errors.As(err, &regionError) {
 			err = fmt.Errorf(`missing region for bucket %q: %w`, m.config.Bucket, regionError)
@@ -203,6 +239,82 @@ func (m *digitalOceanManager) UploadReader(ctx context.Context, fileName string,
 	return UploadedFile{Location: output.Location, ObjectName: fileName}, nil
 }
 
+func (m *digitalOceanManager) Stat(ctx context.Context, key string) (*FileInfo, error) {
+	client, err := m.getClient(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("digitalocean client: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, m.getTimeout())
+	defer cancel()
+
+	output, err := client.HeadObject(ctx, &s3.HeadObjectInput{
+		Bucket: aws.String(m.config.Bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		var nf *types.NotFound
+		if errors.As(err, &nf) {
+			return nil, ErrKeyNotFound
+		}
+		return nil, fmt.Errorf("failed to stat DigitalOcean Spaces object: %w", err)
+	}
+	return s3HeadObjectFileInfo(key, output), nil
+}
+
+func (m *digitalOceanManager) Copy(ctx context.Context, srcKey, dstKey string) error {
+	copier, err := m.copier(ctx)
+	if err != nil {
+		return err
+	}
+	return copier.copy(ctx, srcKey, dstKey)
+}
+
+func (m *digitalOceanManager) Move(ctx context.Context, srcKey, dstKey string) error {
+	return moveByCopy(ctx, m, srcKey, dstKey)
+}
+
+func (m *digitalOceanManager) Compose(ctx context.Context, dstKey string, srcKeys ...string) error {
+	if err := validateCompose(dstKey, srcKeys); err != nil {
+		return err
+	}
+	copier, err := m.copier(ctx)
+	if err != nil {
+		return err
+	}
+	if ok, err := copier.compose(ctx, dstKey, srcKeys); ok || err != nil {
+		return err
+	}
+	return streamCompose(ctx, m, dstKey, srcKeys)
+}
+
+func (m *digitalOceanManager) copier(ctx context.Context) (*s3Copier, error) {
+	client, err := m.getClient(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("digitalocean client: %w", err)
+	}
+	return &s3Copier{client: client, bucket: m.config.Bucket, timeout: m.getTimeout()}, nil
+}
+
+func (m *digitalOceanManager) PresignGet(ctx context.Context, key string, ttl time.Duration) (string, error) {
+	return m.presign(ctx, http.MethodGet, key, ttl)
+}
+
+func (m *digitalOceanManager) PresignPut(ctx context.Context, key string, ttl time.Duration) (string, error) {
+	return m.presign(ctx, http.MethodPut, key, ttl)
+}
+
+func (m *digitalOceanManager) presign(ctx context.Context, method, key string, ttl time.Duration) (string, error) {
+	if err := validatePresign(method, ttl); err != nil {
+		return "", err
+	}
+	client, err := m.getClient(ctx)
+	if err != nil {
+		return "", fmt.Errorf("digitalocean client: %w", err)
+	}
+	return s3Presign(ctx, client, method, m.config.Bucket, key, ttl)
+}
+
 func (m *digitalOceanManager) Delete(ctx context.Context, keys []string) error {
 	client, err := m.getClient(ctx)
 	if err != nil {
@@ -333,7 +445,7 @@ func (l *digitalOceanListSession) Next() ([]*FileInfo, error) {
 	l.isTruncated = *resp.IsTruncated
 	l.continuationToken = resp.NextContinuationToken
 	for _, item := range resp.Contents {
-		fileObjects = append(fileObjects, &FileInfo{*item.Key, *item.LastModified})
+		fileObjects = append(fileObjects, s3ObjectFileInfo(item))
 	}
 	return fileObjects, nil
 }
@@ -0,0 +1,319 @@
+package filemanager
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"path"
+
+	"github.com/rudderlabs/rudder-go-kit/bytesize"
+	"github.com/rudderlabs/rudder-go-kit/compress"
+	"github.com/rudderlabs/rudder-go-kit/encrypt"
+)
+
+const (
+	// MetadataKeyCompression is the metadata key holding the compression settings of files uploaded through
+	// WithEncoding, as serialized by compress.SerializeSettings
+	MetadataKeyCompression = "rudder_compression"
+	// MetadataKeyEncryption is the metadata key holding the encryption settings of files uploaded through
+	// WithEncoding, as serialized by encrypt.SerializeSettings
+	MetadataKeyEncryption = "rudder_encryption"
+)
+
+// EncodingOption configures the file manager returned by WithEncoding
+type EncodingOption func(*encodingManager)
+
+// WithCompression compresses uploaded files with the given algorithm and level
+func WithCompression(algo compress.CompressionAlgorithm, level compress.CompressionLevel) EncodingOption {
+	return func(m *encodingManager) {
+		m.compression = compress.SerializeSettings(algo, level)
+	}
+}
+
+// WithEncryption encrypts uploaded files with the given algorithm, level and key.
+// The key is also used for decrypting downloaded files, whatever the encryption settings they were uploaded with.
+func WithEncryption(algo encrypt.EncryptionAlgorithm, level encrypt.EncryptionLevel, key string) EncodingOption {
+	return func(m *encodingManager) {
+		m.encryption = encrypt.SerializeSettings(algo, level)
+		m.encryptionKey = key
+	}
+}
+
+// WithMaxEncryptedSize sets the maximum size of the files to be encrypted or decrypted, which are held in memory,
+// defaults to DefaultMaxEncryptedSize. The size is the one of the files compressed, if compression is enabled.
+func WithMaxEncryptedSize(size int64) EncodingOption {
+	return func(m *encodingManager) {
+		if size > 0 {
+			m.maxEncryptedSize = size
+		}
+	}
+}
+
+// DefaultMaxEncryptedSize is the default maximum size of the files to be encrypted or decrypted
+const DefaultMaxEncryptedSize = 256 * bytesize.MB
+
+// aesGCMOverhead is the size added by AES-GCM encryption, i.e. the nonce and the authentication tag
+const aesGCMOverhead = 12 + 16
+
+// WithEncoding wraps a file manager so that files are compressed and/or encrypted on the client side before being
+// uploaded, recording the settings in the files' metadata (see MetadataKeyCompression and MetadataKeyEncryption).
+// Downloaded files are decrypted and decompressed according to their metadata, so files uploaded with different
+// settings, or without the wrapper, can be read as well.
+//
+// Files are compressed and decompressed while they are streamed. Encrypted files are instead held in memory while
+// being encrypted or decrypted, since AES-GCM authenticates whole files, hence their size is limited (see
+// WithMaxEncryptedSize).
+// Stat, ListFilesWithPrefix and PresignGet expose the encoded files, e.g. their size is the encoded one.
+func WithEncoding(fm FileManager, opts ...EncodingOption) (FileManager, error) {
+	m := &encodingManager{FileManager: fm, maxEncryptedSize: DefaultMaxEncryptedSize}
+	for _, opt := range opts {
+		opt(m)
+	}
+	if m.compression != "" {
+		if _, _, err := compress.DeserializeSettings(m.compression); err != nil {
+			return nil, fmt.Errorf("compression: %w", err)
+		}
+	}
+	if m.encryption != "" {
+		encryptor, err := m.encryptor(m.encryption)
+		if err != nil {
+			return nil, fmt.Errorf("encryption: %w", err)
+		}
+		// checking the key length upfront
+		if _, err := encryptor.Encrypt(nil, m.encryptionKey); err != nil {
+			return nil, fmt.Errorf("encryption: %w", err)
+		}
+	}
+	return m, nil
+}
+
+type encodingManager struct {
+	FileManager
+	compression      string
+	encryption       string
+	encryptionKey    string
+	maxEncryptedSize int64
+}
+
+func (m *encodingManager) Upload(ctx context.Context, file *os.File, prefixes ...string) (UploadedFile, error) {
+	objName := path.Join(m.Prefix(), path.Join(prefixes...), path.Base(file.Name()))
+	return m.UploadReader(ctx, objName, file)
+}
+
+func (m *encodingManager) UploadReader(ctx context.Context, objName string, rdr io.Reader, opts ...UploadOption) (UploadedFile, error) {
+	metadata := make(map[string]string, 2)
+	if m.compression != "" {
+		compressed, err := newCompressingReader(rdr, m.compression)
+		if err != nil {
+			return UploadedFile{}, err
+		}
+		defer func() { _ = compressed.Close() }()
+		rdr = compressed
+		metadata[MetadataKeyCompression] = m.compression
+	}
+	if m.encryption != "" {
+		encryptor, err := m.encryptor(m.encryption)
+		if err != nil {
+			return UploadedFile{}, err
+		}
+		data, err := readAtMost(rdr, m.maxEncryptedSize)
+		if err != nil {
+			return UploadedFile{}, fmt.Errorf("reading file: %w", err)
+		}
+		if data, err = encryptor.Encrypt(data, m.encryptionKey); err != nil {
+			return UploadedFile{}, fmt.Errorf("encrypting file: %w", err)
+		}
+		rdr = bytes.NewReader(data)
+		metadata[MetadataKeyEncryption] = m.encryption
+	}
+	opts = append(opts, withExtraMetadata(metadata))
+	return m.FileManager.UploadReader(ctx, objName, rdr, opts...)
+}
+
+func (m *encodingManager) Download(ctx context.Context, w io.WriterAt, key string, opts ...DownloadOption) error {
+	info, err := m.Stat(ctx, key)
+	if err != nil {
+		return err
+	}
+	if !isEncoded(info) {
+		return m.FileManager.Download(ctx, w, key, opts...)
+	}
+	r, err := m.openDecoded(ctx, key, info, opts)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = r.Close() }()
+	if _, err := io.Copy(io.NewOffsetWriter(w, 0), r); err != nil {
+		return fmt.Errorf("writing file: %w", err)
+	}
+	return nil
+}
+
+func (m *encodingManager) OpenReader(ctx context.Context, key string, opts ...DownloadOption) (io.ReadCloser, error) {
+	info, err := m.Stat(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	if !isEncoded(info) {
+		return m.FileManager.OpenReader(ctx, key, opts...)
+	}
+	return m.openDecoded(ctx, key, info, opts)
+}
+
+// Compose decodes the source files and uploads their concatenation encoded again, since encoded files can't be
+// concatenated as they are
+func (m *encodingManager) Compose(ctx context.Context, dstKey string, srcKeys ...string) error {
+	if err := validateCompose(dstKey, srcKeys); err != nil {
+		return err
+	}
+	return streamCompose(ctx, m, dstKey, srcKeys)
+}
+
+// openDecoded opens the encoded file, returning a reader of the requested range of its decoded content
+func (m *encodingManager) openDecoded(ctx context.Context, key string, info *FileInfo, opts []DownloadOption) (io.ReadCloser, error) {
+	r, err := m.FileManager.OpenReader(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	decoded := &decodedReader{Reader: r, closers: []io.Closer{r}}
+
+	if settings := info.Metadata[MetadataKeyEncryption]; settings != "" {
+		if m.encryptionKey == "" {
+			_ = decoded.Close()
+			return nil, errors.New("file is encrypted but no encryption key is configured")
+		}
+		encryptor, err := m.encryptor(settings)
+		if err != nil {
+			_ = decoded.Close()
+			return nil, err
+		}
+		data, err := readAtMost(decoded.Reader, m.maxEncryptedSize+aesGCMOverhead)
+		if err != nil {
+			_ = decoded.Close()
+			return nil, fmt.Errorf("reading file: %w", err)
+		}
+		if data, err = encryptor.Decrypt(data, m.encryptionKey); err != nil {
+			_ = decoded.Close()
+			return nil, fmt.Errorf("decrypting file: %w", err)
+		}
+		decoded.Reader = bytes.NewReader(data)
+	}
+	if settings := info.Metadata[MetadataKeyCompression]; settings != "" {
+		algo, _, err := compress.DeserializeSettings(settings)
+		if err != nil {
+			_ = decoded.Close()
+			return nil, err
+		}
+		decompressed, err := compress.NewReader(decoded.Reader, algo)
+		if err != nil {
+			_ = decoded.Close()
+			return nil, err
+		}
+		decoded.Reader = decompressed
+		decoded.closers = append(decoded.closers, decompressed)
+	}
+
+	downloadOpts := applyDownloadOptions(opts...)
+	if downloadOpts.isRangeRequest {
+		if _, err := io.CopyN(io.Discard, decoded.Reader, downloadOpts.offset); err != nil && !errors.Is(err, io.EOF) {
+			_ = decoded.Close()
+			return nil, fmt.Errorf("reading file: %w", err)
+		}
+		if downloadOpts.length > 0 {
+			decoded.Reader = io.LimitReader(decoded.Reader, downloadOpts.length)
+		}
+	}
+	return decoded, nil
+}
+
+// decodedReader reads decoded content, closing the underlying readers, the last opened first, when closed
+type decodedReader struct {
+	io.Reader
+	closers []io.Closer
+}
+
+func (r *decodedReader) Close() error {
+	var errs []error
+	for i := len(r.closers) - 1; i >= 0; i-- {
+		errs = append(errs, r.closers[i].Close())
+	}
+	r.closers = nil
+	return errors.Join(errs...)
+}
+
+// compressingReader compresses the data read from a reader while it is read
+type compressingReader struct {
+	*io.PipeReader
+	done chan struct{}
+}
+
+func newCompressingReader(r io.Reader, settings string) (*compressingReader, error) {
+	algo, level, err := compress.DeserializeSettings(settings)
+	if err != nil {
+		return nil, err
+	}
+	pr, pw := io.Pipe()
+	w, err := compress.NewWriter(pw, algo, level)
+	if err != nil {
+		return nil, err
+	}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_, err := io.Copy(w, r)
+		if closeErr := w.Close(); err == nil {
+			err = closeErr
+		}
+		_ = pw.CloseWithError(err) // a nil error makes the reader return io.EOF
+	}()
+	return &compressingReader{PipeReader: pr, done: done}, nil
+}
+
+// Close stops the compression, waiting for the source reader not to be read anymore
+func (r *compressingReader) Close() error {
+	err := r.PipeReader.Close()
+	<-r.done
+	return err
+}
+
+// readAtMost reads the whole reader, failing if it holds more than size bytes
+func readAtMost(r io.Reader, size int64) ([]byte, error) {
+	data, err := io.ReadAll(io.LimitReader(r, size+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > size {
+		return nil, fmt.Errorf("file is larger than %d bytes, the maximum size of files encrypted in memory", size)
+	}
+	return data, nil
+}
+
+// encryptor returns an encryptor for the given serialized settings
+func (*encodingManager) encryptor(settings string) (*encrypt.Encryptor, error) {
+	algo, level, err := encrypt.DeserializeSettings(settings)
+	if err != nil {
+		return nil, err
+	}
+	return encrypt.New(algo, level)
+}
+
+// isEncoded returns whether the file was compressed or encrypted by WithEncoding
+func isEncoded(info *FileInfo) bool {
+	return info.Metadata[MetadataKeyCompression] != "" || info.Metadata[MetadataKeyEncryption] != ""
+}
+
+// withExtraMetadata adds the given entries to the metadata set by other upload options
+func withExtraMetadata(metadata map[string]string) UploadOption {
+	return func(o *uploadOptions) {
+		merged := maps.Clone(o.metadata)
+		if merged == nil {
+			merged = make(map[string]string, len(metadata))
+		}
+		maps.Copy(merged, metadata)
+		o.metadata = merged
+	}
+}
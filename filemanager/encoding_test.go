@@ -0,0 +1,149 @@
+package filemanager_test
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/rudderlabs/rudder-go-kit/compress"
+	"github.com/rudderlabs/rudder-go-kit/encrypt"
+	"github.com/rudderlabs/rudder-go-kit/filemanager"
+	"github.com/rudderlabs/rudder-go-kit/logger"
+)
+
+func TestWithEncoding(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	local, err := filemanager.New(&filemanager.Settings{
+		Provider: "LOCAL",
+		Config:   map[string]any{"directory": dir},
+		Logger:   logger.NOP,
+	})
+	require.NoError(t, err)
+	key := "0123456789abcdef0123456789abcdef"
+	fm, err := filemanager.WithEncoding(local,
+		filemanager.WithCompression(compress.CompressionAlgoZstd, compress.CompressionLevelZstdBest),
+		filemanager.WithEncryption(encrypt.EncryptionAlgoAESGCM, encrypt.EncryptionLevelAES256, key),
+	)
+	require.NoError(t, err)
+	content := strings.Repeat("hello world ", 100)
+
+	readAll := func(t *testing.T, fm filemanager.FileManager, key string, opts ...filemanager.DownloadOption) string {
+		t.Helper()
+		r, err := fm.OpenReader(ctx, key, opts...)
+		require.NoError(t, err)
+		defer func() { _ = r.Close() }()
+		data, err := io.ReadAll(r)
+		require.NoError(t, err)
+		return string(data)
+	}
+
+	t.Run("round trip", func(t *testing.T) {
+		_, err := fm.UploadReader(ctx, "encoded", strings.NewReader(content), filemanager.WithMetadata(map[string]string{"owner": "me"}))
+		require.NoError(t, err)
+
+		info, err := local.Stat(ctx, "encoded")
+		require.NoError(t, err)
+		require.Equal(t, compress.SerializeSettings(compress.CompressionAlgoZstd, compress.CompressionLevelZstdBest), info.Metadata[filemanager.MetadataKeyCompression])
+		require.Equal(t, encrypt.SerializeSettings(encrypt.EncryptionAlgoAESGCM, encrypt.EncryptionLevelAES256), info.Metadata[filemanager.MetadataKeyEncryption])
+		require.Equal(t, "me", info.Metadata["owner"])
+		raw, err := os.ReadFile(filepath.Join(dir, "encoded"))
+		require.NoError(t, err)
+		require.NotContains(t, string(raw), "hello")
+		require.Less(t, len(raw), len(content))
+
+		require.Equal(t, content, readAll(t, fm, "encoded"))
+		require.Equal(t, "world", readAll(t, fm, "encoded", filemanager.WithDownloadOffSetAndLength(6, 5)))
+
+		f, err := os.CreateTemp(t.TempDir(), "download")
+		require.NoError(t, err)
+		defer func() { _ = f.Close() }()
+		require.NoError(t, fm.Download(ctx, f, "encoded"))
+		downloaded, err := os.ReadFile(f.Name())
+		require.NoError(t, err)
+		require.Equal(t, content, string(downloaded))
+	})
+
+	t.Run("plain files are read as they are", func(t *testing.T) {
+		_, err := local.UploadReader(ctx, "plain", strings.NewReader("plain text"))
+		require.NoError(t, err)
+		require.Equal(t, "plain text", readAll(t, fm, "plain"))
+		require.Equal(t, "text", readAll(t, fm, "plain", filemanager.WithDownloadOffSet(6)))
+	})
+
+	t.Run("compose", func(t *testing.T) {
+		_, err := fm.UploadReader(ctx, "part1", strings.NewReader("hello "))
+		require.NoError(t, err)
+		_, err = fm.UploadReader(ctx, "part2", strings.NewReader("world"))
+		require.NoError(t, err)
+		require.NoError(t, fm.Compose(ctx, "composed", "part1", "part2"))
+		require.Equal(t, "hello world", readAll(t, fm, "composed"))
+	})
+
+	t.Run("wrong key", func(t *testing.T) {
+		other, err := filemanager.WithEncoding(local,
+			filemanager.WithEncryption(encrypt.EncryptionAlgoAESGCM, encrypt.EncryptionLevelAES256, strings.Repeat("x", 32)),
+		)
+		require.NoError(t, err)
+		_, err = other.OpenReader(ctx, "encoded")
+		require.Error(t, err)
+
+		unencrypted, err := filemanager.WithEncoding(local)
+		require.NoError(t, err)
+		_, err = unencrypted.OpenReader(ctx, "encoded")
+		require.Error(t, err)
+	})
+
+	t.Run("invalid settings", func(t *testing.T) {
+		_, err := filemanager.WithEncoding(local, filemanager.WithEncryption(encrypt.EncryptionAlgoAESGCM, encrypt.EncryptionLevelAES256, "short"))
+		require.Error(t, err)
+		_, err = filemanager.WithEncoding(local, filemanager.WithCompression(compress.CompressionAlgorithm(42), compress.CompressionLevelZstdBest))
+		require.Error(t, err)
+	})
+
+	t.Run("compression only", func(t *testing.T) {
+		compressed, err := filemanager.WithEncoding(local,
+			filemanager.WithCompression(compress.CompressionAlgoZstd, compress.CompressionLevelZstdFastest),
+		)
+		require.NoError(t, err)
+		large := strings.Repeat("0123456789", 1_000_000)
+		_, err = compressed.UploadReader(ctx, "compressed", strings.NewReader(large))
+		require.NoError(t, err)
+
+		info, err := local.Stat(ctx, "compressed")
+		require.NoError(t, err)
+		require.Less(t, info.Size, int64(len(large)/10))
+		require.Empty(t, info.Metadata[filemanager.MetadataKeyEncryption])
+		require.Equal(t, large, readAll(t, compressed, "compressed"))
+		require.Equal(t, "3456", readAll(t, fm, "compressed", filemanager.WithDownloadOffSetAndLength(5_000_003, 4)))
+		require.Empty(t, readAll(t, fm, "compressed", filemanager.WithDownloadOffSet(int64(len(large)+1))))
+	})
+
+	t.Run("max encrypted size", func(t *testing.T) {
+		limited, err := filemanager.WithEncoding(local,
+			filemanager.WithEncryption(encrypt.EncryptionAlgoAESGCM, encrypt.EncryptionLevelAES256, key),
+			filemanager.WithMaxEncryptedSize(10),
+		)
+		require.NoError(t, err)
+		_, err = limited.UploadReader(ctx, "limited", strings.NewReader("0123456789"))
+		require.NoError(t, err)
+		require.Equal(t, "0123456789", readAll(t, limited, "limited"))
+
+		_, err = limited.UploadReader(ctx, "too-large", strings.NewReader("0123456789a"))
+		require.ErrorContains(t, err, "maximum size of files encrypted in memory")
+		_, err = local.Stat(ctx, "too-large")
+		require.ErrorIs(t, err, filemanager.ErrKeyNotFound)
+		_, err = limited.OpenReader(ctx, "encoded")
+		require.ErrorContains(t, err, "maximum size of files encrypted in memory")
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		_, err := fm.OpenReader(ctx, "missing")
+		require.ErrorIs(t, err, filemanager.ErrKeyNotFound)
+	})
+}
@@ -0,0 +1,142 @@
+package filemanager
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"syscall"
+
+	"github.com/Azure/azure-storage-blob-go/azblob"
+	"github.com/aws/smithy-go"
+	"github.com/minio/minio-go/v7"
+	"google.golang.org/api/googleapi"
+)
+
+// Error classes returned by ErrorClass
+const (
+	ErrorClassNone               = "none"
+	ErrorClassNotFound           = "not_found"
+	ErrorClassPreconditionFailed = "precondition_failed"
+	ErrorClassNotSupported       = "not_supported"
+	ErrorClassCanceled           = "canceled"
+	ErrorClassTimeout            = "timeout"
+	ErrorClassThrottled          = "throttled"
+	ErrorClassServer             = "server"
+	ErrorClassConnection         = "connection"
+	ErrorClassClient             = "client"
+	ErrorClassUnknown            = "unknown"
+)
+
+// throttlingErrorCodes are the error codes returned by the providers when requests are being throttled
+var throttlingErrorCodes = map[string]struct{}{
+	"SlowDown":                           {},
+	"Throttling":                         {},
+	"ThrottlingException":                {},
+	"RequestLimitExceeded":               {},
+	"TooManyRequests":                    {},
+	"TooManyRequestsException":           {},
+	"RequestThrottled":                   {},
+	string(azblob.ServiceCodeServerBusy): {},
+}
+
+// ErrorClass classifies an error returned by any file manager into one of the ErrorClass* constants, looking at the
+// HTTP status codes and error codes of the different providers.
+func ErrorClass(err error) string {
+	switch {
+	case err == nil:
+		return ErrorClassNone
+	case errors.Is(err, ErrKeyNotFound):
+		return ErrorClassNotFound
+	case errors.Is(err, ErrPreConditionFailed):
+		return ErrorClassPreconditionFailed
+	case errors.Is(err, ErrNotSupported):
+		return ErrorClassNotSupported
+	case errors.Is(err, context.Canceled):
+		return ErrorClassCanceled
+	}
+
+	code, status := providerErrorCode(err)
+	if _, ok := throttlingErrorCodes[code]; ok || status == http.StatusTooManyRequests {
+		return ErrorClassThrottled
+	}
+	switch {
+	case status >= 500 && status != http.StatusNotImplemented:
+		return ErrorClassServer
+	case status >= 400 && status < 500:
+		if status == http.StatusRequestTimeout {
+			return ErrorClassTimeout
+		}
+		return ErrorClassClient
+	}
+
+	var netErr net.Error
+	switch {
+	case errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &netErr) && netErr.Timeout()):
+		return ErrorClassTimeout
+	case errors.Is(err, syscall.ECONNRESET),
+		errors.Is(err, syscall.ECONNREFUSED),
+		errors.Is(err, syscall.ECONNABORTED),
+		errors.Is(err, syscall.EPIPE),
+		errors.Is(err, io.ErrUnexpectedEOF),
+		errors.As(err, new(*net.OpError)):
+		return ErrorClassConnection
+	}
+	return ErrorClassUnknown
+}
+
+// IsTransientError returns whether the error is likely to be transient, i.e. whether retrying the operation might
+// succeed: throttling, server errors, timeouts and connection failures are considered transient.
+func IsTransientError(err error) bool {
+	switch ErrorClass(err) {
+	case ErrorClassThrottled, ErrorClassServer, ErrorClassTimeout, ErrorClassConnection:
+		return true
+	default:
+		return false
+	}
+}
+
+// providerErrorCode extracts the error code and HTTP status code of an error returned by any of the providers' SDKs.
+// Both are zero values if not available.
+func providerErrorCode(err error) (code string, status int) {
+	// S3 and DigitalOcean Spaces
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) {
+		code = apiErr.ErrorCode()
+	}
+	var statusErr interface{ HTTPStatusCode() int }
+	if errors.As(err, &statusErr) {
+		return code, statusErr.HTTPStatusCode()
+	}
+	if code != "" {
+		return code, 0
+	}
+
+	// GCS
+	var gcsErr *googleapi.Error
+	if errors.As(err, &gcsErr) {
+		for _, e := range gcsErr.Errors {
+			if e.Reason == "rateLimitExceeded" || e.Reason == "userRateLimitExceeded" {
+				return "TooManyRequests", gcsErr.Code
+			}
+		}
+		return "", gcsErr.Code
+	}
+
+	// Azure
+	var storageErr azblob.StorageError
+	if errors.As(err, &storageErr) {
+		if resp := storageErr.Response(); resp != nil {
+			status = resp.StatusCode
+		}
+		return string(storageErr.ServiceCode()), status
+	}
+
+	// MinIO
+	var minioErr minio.ErrorResponse
+	if errors.As(err, &minioErr) {
+		return minioErr.Code, minioErr.StatusCode
+	}
+	return "", 0
+}
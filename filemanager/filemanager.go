@@ -6,7 +6,9 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"net/http"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/rudderlabs/rudder-go-kit/config"
@@ -15,10 +17,15 @@ import (
 
 const defaultTimeout = 120 * time.Second
 
+// MaxPresignExpiry is the maximum validity of presigned URLs, as allowed by S3 and GCS V4 signatures
+const MaxPresignExpiry = 7 * 24 * time.Hour
+
 var (
 	ErrKeyNotFound            = errors.New("NoSuchKey")
 	ErrInvalidServiceProvider = errors.New("service provider not supported")
 	ErrPreConditionFailed     = errors.New("precondition failed")
+	ErrNotSupported           = errors.New("operation not supported by the service provider")
+	ErrInvalidPresignExpiry   = errors.New("invalid presign expiry")
 )
 
 // Factory is a function that returns a new file manager
@@ -34,6 +41,19 @@ type UploadedFile struct {
 type FileInfo struct {
 	Key          string
 	LastModified time.Time
+	// Size is the size of the file in bytes
+	Size int64
+	// ETag is the entity tag of the file, without surrounding quotes
+	ETag string
+	// MD5 is the hex encoded MD5 digest of the file's content, if reported by the provider
+	MD5 string
+	// ContentType is the MIME type of the file
+	ContentType string
+	// StorageClass is the provider specific storage class (or access tier) of the file, if any
+	StorageClass string
+	// Metadata is the user-defined metadata of the file. Keys are lowercased, since most providers don't preserve
+	// their case.
+	Metadata map[string]string
 }
 
 type (
@@ -75,19 +95,140 @@ func applyDownloadOptions(opts ...DownloadOption) downloadOptions {
 	return downloadOpts
 }
 
+type (
+	UploadOption  func(*uploadOptions)
+	uploadOptions struct {
+		contentType string
+		metadata    map[string]string
+		ifNoneMatch string
+		ifMatch     string
+	}
+)
+
+// WithContentType sets the MIME type of the uploaded file
+func WithContentType(contentType string) UploadOption {
+	return func(o *uploadOptions) {
+		o.contentType = contentType
+	}
+}
+
+// WithMetadata sets user-defined metadata on the uploaded file.
+// Keys should be valid for all providers, i.e. lowercase letters, digits and underscores.
+func WithMetadata(metadata map[string]string) UploadOption {
+	return func(o *uploadOptions) {
+		o.metadata = metadata
+	}
+}
+
+// IfNoneMatch makes the upload fail with ErrPreConditionFailed if a file already exists with the same key.
+// Only "*" is supported as entity tag, i.e. the file is created only if it doesn't exist yet.
+func IfNoneMatch(etag string) UploadOption {
+	return func(o *uploadOptions) {
+		o.ifNoneMatch = etag
+	}
+}
+
+// IfMatch makes the upload fail with ErrPreConditionFailed unless a file exists with the same key and the given
+// entity tag (see FileInfo.ETag), i.e. the file is replaced only if it wasn't modified in the meantime.
+func IfMatch(etag string) UploadOption {
+	return func(o *uploadOptions) {
+		o.ifMatch = trimETag(etag)
+	}
+}
+
+func applyUploadOptions(opts ...UploadOption) (uploadOptions, error) {
+	uploadOpts := uploadOptions{}
+	for _, opt := range opts {
+		opt(&uploadOpts)
+	}
+	if uploadOpts.ifNoneMatch != "" && uploadOpts.ifNoneMatch != "*" {
+		return uploadOpts, fmt.Errorf("unsupported if-none-match entity tag %q: only * is supported", uploadOpts.ifNoneMatch)
+	}
+	if uploadOpts.ifNoneMatch != "" && uploadOpts.ifMatch != "" {
+		return uploadOpts, errors.New("if-match and if-none-match cannot be used together")
+	}
+	return uploadOpts, nil
+}
+
+// normalizeMetadata lowercases the keys of metadata returned by a provider
+func normalizeMetadata(metadata map[string]string) map[string]string {
+	if len(metadata) == 0 {
+		return nil
+	}
+	normalized := make(map[string]string, len(metadata))
+	for k, v := range metadata {
+		normalized[strings.ToLower(k)] = v
+	}
+	return normalized
+}
+
+// trimETag removes the quotes surrounding an entity tag
+func trimETag(etag string) string {
+	return strings.Trim(etag, `"`)
+}
+
+// quoteETag surrounds an entity tag with quotes, as expected by If-Match headers
+func quoteETag(etag string) string {
+	return `"` + etag + `"`
+}
+
+// validatePresign checks that presigning is supported for the given HTTP method and time to live
+func validatePresign(method string, ttl time.Duration) error {
+	switch method {
+	case http.MethodGet, http.MethodPut:
+	default:
+		return fmt.Errorf("%w: presigning %s requests", ErrNotSupported, method)
+	}
+	if ttl <= 0 || ttl > MaxPresignExpiry {
+		return fmt.Errorf("%w: %s is not within (0, %s]", ErrInvalidPresignExpiry, ttl, MaxPresignExpiry)
+	}
+	return nil
+}
+
+// httpRange returns the value of the HTTP Range header for a range request, e.g. bytes=10-19
+func (o downloadOptions) httpRange() string {
+	if o.length > 0 {
+		return fmt.Sprintf("bytes=%d-%d", o.offset, o.offset+o.length-1)
+	}
+	return fmt.Sprintf("bytes=%d-", o.offset)
+}
+
 // FileManager is able to manage files in a storage provider
 type FileManager interface {
-	// ListFilesWithPrefix starts a list session for files with given prefix
+	// ListFilesWithPrefix starts a list session for files with given prefix.
+	// S3, MinIO and DigitalOcean Spaces don't return the content type and metadata of listed files: use Stat for those.
 	ListFilesWithPrefix(ctx context.Context, startAfter, prefix string, maxItems int64) ListSession
 	// Download retrieves an object with the given key and writes it to the provided writer.
 	// You can Pass *os.File instead of io.WriterAt to write the downloaded data on disk.
 	Download(context.Context, io.WriterAt, string, ...DownloadOption) error
+	// OpenReader opens a streaming reader for the object with the given key, honouring range download options.
+	// The file manager's timeout is not applied, since reading can take arbitrarily long: use the context to bound it.
+	// The caller is responsible for closing the returned reader.
+	OpenReader(ctx context.Context, key string, opts ...DownloadOption) (io.ReadCloser, error)
 	// Upload uploads the passed in file to the file manager
 	Upload(context.Context, *os.File, ...string) (UploadedFile, error)
 	// UploadReader uploads the passed io.Reader to the file manager
-	UploadReader(ctx context.Context, objName string, rdr io.Reader) (UploadedFile, error)
+	UploadReader(ctx context.Context, objName string, rdr io.Reader, opts ...UploadOption) (UploadedFile, error)
 	// Delete deletes the file(s) with given key(s)
 	Delete(ctx context.Context, keys []string) error
+	// Stat returns information about the file with the given key, or ErrKeyNotFound if it doesn't exist
+	Stat(ctx context.Context, key string) (*FileInfo, error)
+	// Copy copies a file to the destination key, preserving its content type and metadata.
+	// The copy is performed server-side, unless the provider doesn't support it.
+	Copy(ctx context.Context, srcKey, dstKey string) error
+	// Move moves a file to the destination key, preserving its content type and metadata
+	Move(ctx context.Context, srcKey, dstKey string) error
+	// Compose concatenates the source files into the destination key, which can't be one of the sources.
+	// The concatenation is performed server-side, unless the provider or the source files don't support it: e.g. S3
+	// requires all sources but the last one to be at least 5MiB large.
+	Compose(ctx context.Context, dstKey string, srcKeys ...string) error
+
+	// PresignGet returns a URL allowing anyone to download the file with the given key through a GET request, during
+	// the given time to live, which can't exceed MaxPresignExpiry
+	PresignGet(ctx context.Context, key string, ttl time.Duration) (string, error)
+	// PresignPut returns a URL allowing anyone to upload a file with the given key through a PUT request, during the
+	// given time to live, which can't exceed MaxPresignExpiry
+	PresignPut(ctx context.Context, key string, ttl time.Duration) (string, error)
 
 	// Prefix returns the prefix for the file manager
 	Prefix() string
@@ -112,10 +253,26 @@ type Settings struct {
 	Config   map[string]any
 	Logger   logger.Logger
 	Conf     *config.Config
+	// Transfer tunes multipart uploads and parallel downloads, and reports their progress
+	Transfer TransferSettings
 }
 
 // New returns file manager backed by configured provider
 func New(settings *Settings) (FileManager, error) {
+	if err := settings.Transfer.validate(); err != nil {
+		return nil, fmt.Errorf("invalid transfer settings: %w", err)
+	}
+	fm, err := newProvider(settings)
+	if err != nil {
+		return nil, err
+	}
+	if ts, ok := fm.(interface{ SetTransferSettings(TransferSettings) }); ok {
+		ts.SetTransferSettings(settings.Transfer)
+	}
+	return fm, nil
+}
+
+func newProvider(settings *Settings) (FileManager, error) {
 	log := settings.Logger
 	if log == nil {
 		log = logger.NewLogger().Child("filemanager")
@@ -136,6 +293,8 @@ func New(settings *Settings) (FileManager, error) {
 		return NewMinioManager(settings.Config, log, getDefaultTimeout(conf, settings.Provider))
 	case "DIGITAL_OCEAN_SPACES":
 		return NewDigitalOceanManager(settings.Config, log, getDefaultTimeout(conf, settings.Provider))
+	case "LOCAL":
+		return NewLocalManager(settings.Config, log, getDefaultTimeout(conf, settings.Provider))
 	}
 	return nil, fmt.Errorf("%w: %s", ErrInvalidServiceProvider, settings.Provider)
 }
@@ -2,6 +2,7 @@ package filemanager_test
 
 import (
 	"bytes"
+	"compress/gzip"
 	"context"
 	"encoding/base64"
 	"flag"
@@ -221,6 +222,17 @@ func TestFileManager(t *testing.T) {
 				}
 			},
 		},
+		{
+			name:          "testing local filemanager functionality",
+			destName:      "LOCAL",
+			otherPrefixes: []string{"other-prefix-1", "other-prefix-2"},
+			config: func(t *testing.T) map[string]any {
+				return map[string]any{
+					"directory": t.TempDir(),
+					"prefix":    "some-prefix",
+				}
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -412,6 +424,117 @@ func TestFileManager(t *testing.T) {
 			require.NoError(t, filePtr.Close())
 			require.Equal(t, string(originalFile[5:]), string(downloadedFile), "downloaded file different than actual file")
 
+			// stream the file, whole and ranged
+			readAll := func(opts ...filemanager.DownloadOption) string {
+				r, err := fm.OpenReader(context.Background(), key, opts...)
+				require.NoError(t, err)
+				defer func() { require.NoError(t, r.Close()) }()
+				data, err := io.ReadAll(r)
+				require.NoError(t, err)
+				return string(data)
+			}
+			require.Equal(t, string(originalFile), readAll(), "streamed file different than actual file")
+			require.Equal(t, string(originalFile[5:15]), readAll(filemanager.WithDownloadOffSetAndLength(5, 10)))
+			require.Equal(t, string(originalFile[5:]), readAll(filemanager.WithDownloadOffSet(5)))
+			_, err = fm.OpenReader(context.Background(), key+"-missing")
+			require.ErrorIs(t, err, filemanager.ErrKeyNotFound)
+
+			// stat the file
+			info, err := fm.Stat(context.Background(), key)
+			require.NoError(t, err)
+			require.Equal(t, key, info.Key)
+			require.EqualValues(t, len(originalFile), info.Size)
+			require.NotEmpty(t, info.ETag)
+			require.False(t, info.LastModified.IsZero())
+			listed, found := lo.Find(originalFileObject, func(item *filemanager.FileInfo) bool { return item.Key == key })
+			require.True(t, found)
+			require.Equal(t, info.Size, listed.Size)
+			require.Equal(t, info.ETag, listed.ETag)
+			_, err = fm.Stat(context.Background(), key+"-missing")
+			require.ErrorIs(t, err, filemanager.ErrKeyNotFound)
+
+			// upload a file with content type and metadata, outside of the listed prefixes
+			uploaded, err := fm.UploadReader(context.Background(), "metadata/file.txt", strings.NewReader("hello"),
+				filemanager.WithContentType("text/plain"),
+				filemanager.WithMetadata(map[string]string{"owner": "filemanager_test"}),
+			)
+			require.NoError(t, err)
+			info, err = fm.Stat(context.Background(), uploaded.ObjectName)
+			require.NoError(t, err)
+			require.EqualValues(t, 5, info.Size)
+			require.Equal(t, "text/plain", info.ContentType)
+			require.Equal(t, map[string]string{"owner": "filemanager_test"}, info.Metadata)
+
+			// conditional uploads
+			_, err = fm.UploadReader(context.Background(), uploaded.ObjectName, strings.NewReader("world"), filemanager.IfNoneMatch("*"))
+			require.ErrorIs(t, err, filemanager.ErrPreConditionFailed)
+			_, err = fm.UploadReader(context.Background(), uploaded.ObjectName, strings.NewReader("world"), filemanager.IfMatch("wrong"))
+			require.ErrorIs(t, err, filemanager.ErrPreConditionFailed)
+			_, err = fm.UploadReader(context.Background(), uploaded.ObjectName, strings.NewReader("world"), filemanager.IfMatch(info.ETag))
+			require.NoError(t, err)
+			_, err = fm.UploadReader(context.Background(), "metadata/missing.txt", strings.NewReader("world"), filemanager.IfMatch(info.ETag))
+			require.ErrorIs(t, err, filemanager.ErrPreConditionFailed)
+			require.NoError(t, fm.Delete(context.Background(), []string{uploaded.ObjectName}))
+			_, err = fm.UploadReader(context.Background(), uploaded.ObjectName, strings.NewReader("world"), filemanager.IfNoneMatch("*"))
+			require.NoError(t, err)
+
+			// copy, move and compose
+			require.NoError(t, fm.Copy(context.Background(), uploaded.ObjectName, "metadata/copy.txt"))
+			require.NoError(t, fm.Move(context.Background(), "metadata/copy.txt", "metadata/moved.txt"))
+			_, err = fm.Stat(context.Background(), "metadata/copy.txt")
+			require.ErrorIs(t, err, filemanager.ErrKeyNotFound)
+			require.NoError(t, fm.Compose(context.Background(), "metadata/composed.txt", uploaded.ObjectName, "metadata/moved.txt"))
+			composed, err := fm.OpenReader(context.Background(), "metadata/composed.txt")
+			require.NoError(t, err)
+			data, err := io.ReadAll(composed)
+			require.NoError(t, err)
+			require.NoError(t, composed.Close())
+			require.Equal(t, "worldworld", string(data))
+			if tt.destName == "GCS" { // composed in batches through a temporary object
+				srcKeys := make([]string, 40)
+				for i := range srcKeys {
+					srcKeys[i] = uploaded.ObjectName
+				}
+				require.NoError(t, fm.Compose(context.Background(), "metadata/composed.txt", srcKeys...))
+				composed, err := fm.OpenReader(context.Background(), "metadata/composed.txt")
+				require.NoError(t, err)
+				data, err := io.ReadAll(composed)
+				require.NoError(t, err)
+				require.NoError(t, composed.Close())
+				require.Equal(t, strings.Repeat("world", 40), string(data))
+				files, err := fm.ListFilesWithPrefix(context.Background(), "", "metadata/composed.txt", 10).Next()
+				require.NoError(t, err)
+				require.Len(t, files, 1, "the temporary object should be deleted")
+			}
+			require.ErrorIs(t, fm.Copy(context.Background(), "metadata/missing.txt", "metadata/copy.txt"), filemanager.ErrKeyNotFound)
+			require.NoError(t, fm.Delete(context.Background(), []string{uploaded.ObjectName, "metadata/moved.txt", "metadata/composed.txt"}))
+
+			// presigned urls (the fake GCS server has no signing credentials)
+			if useSAS, _ := fmConfig["useSASTokens"].(bool); tt.destName != "LOCAL" && tt.destName != "GCS" && !useSAS {
+				putURL, err := fm.PresignPut(context.Background(), "metadata/presigned.txt", time.Minute)
+				require.NoError(t, err)
+				req, err := http.NewRequest(http.MethodPut, putURL, strings.NewReader("presigned"))
+				require.NoError(t, err)
+				req.Header.Set("x-ms-blob-type", "BlockBlob")
+				resp, err := http.DefaultClient.Do(req)
+				require.NoError(t, err)
+				_ = resp.Body.Close()
+				require.Less(t, resp.StatusCode, 300, "presigned PUT status")
+
+				getURL, err := fm.PresignGet(context.Background(), "metadata/presigned.txt", time.Minute)
+				require.NoError(t, err)
+				resp, err = http.Get(getURL)
+				require.NoError(t, err)
+				data, err = io.ReadAll(resp.Body)
+				_ = resp.Body.Close()
+				require.NoError(t, err)
+				require.Equal(t, http.StatusOK, resp.StatusCode)
+				require.Equal(t, "presigned", string(data))
+				require.NoError(t, fm.Delete(context.Background(), []string{"metadata/presigned.txt"}))
+			}
+			_, err = fm.PresignGet(context.Background(), "metadata/presigned.txt", filemanager.MaxPresignExpiry+time.Second)
+			require.Error(t, err)
+
 			// fail to delete the file with cancelled context
 			ctx, cancel = context.WithCancel(context.Background())
 			cancel()
@@ -786,6 +909,41 @@ func TestS3Manager_SelectObjects(t *testing.T) {
 	t.Run("SelectObjects with CSV output", func(t *testing.T) {
 		runSelectAndAssert(t, filemanager.SelectObjectOutputFormatCSV)
 	})
+
+	t.Run("SelectRecords with gzipped CSV input", func(t *testing.T) {
+		var buf bytes.Buffer
+		gw := gzip.NewWriter(&buf)
+		_, err := gw.Write([]byte("name;age\nalice;30\nbob;25\ncarol;41\n"))
+		require.NoError(t, err)
+		require.NoError(t, gw.Close())
+		uploaded, err := fm.UploadReader(context.Background(), "select/people.csv.gz", &buf)
+		require.NoError(t, err)
+		defer func() { _ = fm.Delete(context.Background(), []string{uploaded.ObjectName}) }()
+
+		var progress []filemanager.SelectStats
+		it, err := s3fm.SelectRecords(context.Background(), filemanager.SelectConfig{
+			SQLExpression:   "SELECT s.name FROM S3Object s WHERE CAST(s.age AS INT) > 26",
+			Key:             uploaded.ObjectName,
+			InputFormat:     filemanager.SelectObjectInputFormatCSV,
+			OutputFormat:    filemanager.SelectObjectOutputFormatJSON,
+			CompressionType: filemanager.SelectCompressionGzip,
+			CSVInput: &filemanager.SelectCSVInput{
+				FileHeaderInfo: filemanager.SelectCSVFileHeaderUse,
+				FieldDelimiter: ";",
+			},
+			OnProgress: func(s filemanager.SelectStats) { progress = append(progress, s) },
+		})
+		require.NoError(t, err)
+		defer func() { _ = it.Close() }()
+		var records []string
+		for it.Next() {
+			records = append(records, string(it.Get()))
+		}
+		require.NoError(t, it.Err())
+		require.Equal(t, []string{`{"name":"alice"}`, `{"name":"carol"}`}, records)
+		require.NotEmpty(t, progress)
+		require.Positive(t, it.Stats().BytesScanned)
+	})
 }
 
 func startMinioContainer(t *testing.T) (minioHostPort, s3Endpoint string) {
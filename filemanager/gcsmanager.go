@@ -2,6 +2,7 @@ package filemanager
 
 import (
 	"context"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"io"
@@ -13,6 +14,7 @@ import (
 	"time"
 
 	"cloud.google.com/go/storage"
+	"github.com/google/uuid"
 	"google.golang.org/api/googleapi"
 	"google.golang.org/api/iterator"
 	"google.golang.org/api/option"
@@ -21,6 +23,9 @@ import (
 	"github.com/rudderlabs/rudder-go-kit/logger"
 )
 
+// gcsMaxComposeSources is the maximum number of objects which can be composed by a single request
+const gcsMaxComposeSources = 32
+
 type GCSConfig struct {
 	Bucket           string
 	Prefix           string
@@ -87,17 +92,45 @@ func (m *GcsManager) Download(ctx context.Context, output io.WriterAt, key strin
 	}
 	defer func() { _ = rc.Close() }()
 
-	writer := &writerAtAdapter{w: output}
+	writer := &writerAtAdapter{w: m.progressWriterAt("download", key, output)}
 	_, err = io.Copy(writer, rc)
 	return err
 }
 
+func (m *GcsManager) OpenReader(ctx context.Context, key string, opts ...DownloadOption) (io.ReadCloser, error) {
+	downloadOpts := applyDownloadOptions(opts...)
+	client, err := m.getClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	offset, length := int64(0), int64(-1)
+	if downloadOpts.isRangeRequest {
+		offset = downloadOpts.offset
+		if downloadOpts.length > 0 {
+			length = downloadOpts.length
+		}
+	}
+	rc, err := client.Bucket(m.config.Bucket).Object(key).NewRangeReader(ctx, offset, length)
+	if err != nil {
+		if errors.Is(err, storage.ErrObjectNotExist) {
+			return nil, ErrKeyNotFound
+		}
+		return nil, err
+	}
+	return rc, nil
+}
+
 func (m *GcsManager) Upload(ctx context.Context, file *os.File, prefixes ...string) (UploadedFile, error) {
 	objName := path.Join(m.config.Prefix, path.Join(prefixes...), path.Base(file.Name()))
 	return m.UploadReader(ctx, objName, file)
 }
 
-func (m *GcsManager) UploadReader(ctx context.Context, objName string, rdr io.Reader) (UploadedFile, error) {
+func (m *GcsManager) UploadReader(ctx context.Context, objName string, rdr io.Reader, opts ...UploadOption) (UploadedFile, error) {
+	uploadOpts, err := applyUploadOptions(opts...)
+	if err != nil {
+		return UploadedFile{}, err
+	}
 	client, err := m.getClient(ctx)
 	if err != nil {
 		return UploadedFile{}, err
@@ -107,11 +140,35 @@ func (m *GcsManager) UploadReader(ctx context.Context, objName string, rdr io.Re
 	defer cancel()
 
 	object := client.Bucket(m.config.Bucket).Object(objName)
-	if m.config.UploadIfNotExist {
+	switch {
+	case m.config.UploadIfNotExist || uploadOpts.ifNoneMatch != "":
 		object = object.If(storage.Conditions{DoesNotExist: true})
+	case uploadOpts.ifMatch != "":
+		// GCS preconditions are based on generations rather than entity tags:
+		// the upload is conditioned on the generations of the object matching the expected entity tag
+		attrs, err := object.Attrs(ctx)
+		if err != nil {
+			if errors.Is(err, storage.ErrObjectNotExist) {
+				return UploadedFile{}, ErrPreConditionFailed
+			}
+			return UploadedFile{}, fmt.Errorf("getting object attributes: %w", err)
+		}
+		if trimETag(attrs.Etag) != uploadOpts.ifMatch {
+			return UploadedFile{}, ErrPreConditionFailed
+		}
+		object = object.If(storage.Conditions{GenerationMatch: attrs.Generation, MetagenerationMatch: attrs.Metageneration})
 	}
 
 	w := object.NewWriter(ctx)
+	w.ContentType = uploadOpts.contentType
+	w.Metadata = uploadOpts.metadata
+	if m.transfer.PartSize > 0 || m.transfer.MaxInFlightMemory > 0 {
+		w.ChunkSize = int(m.transfer.partSize(googleapi.DefaultUploadChunkSize))
+		if m.transfer.MaxInFlightMemory > 0 {
+			w.ChunkSize = min(w.ChunkSize, int(m.transfer.MaxInFlightMemory))
+		}
+	}
+	w.ProgressFunc = m.progressFunc("upload", objName)
 	if _, err := io.Copy(w, rdr); err != nil {
 		return UploadedFile{}, fmt.Errorf("copying file to writer: %w", err)
 	}
@@ -127,6 +184,126 @@ func (m *GcsManager) UploadReader(ctx context.Context, objName string, rdr io.Re
 	return UploadedFile{Location: m.objectURL(w.Attrs()), ObjectName: objName}, err
 }
 
+func (m *GcsManager) Stat(ctx context.Context, key string) (*FileInfo, error) {
+	client, err := m.getClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, m.getTimeout())
+	defer cancel()
+
+	attrs, err := client.Bucket(m.config.Bucket).Object(key).Attrs(ctx)
+	if err != nil {
+		if errors.Is(err, storage.ErrObjectNotExist) {
+			return nil, ErrKeyNotFound
+		}
+		return nil, err
+	}
+	return gcsFileInfo(attrs), nil
+}
+
+func (m *GcsManager) Copy(ctx context.Context, srcKey, dstKey string) error {
+	client, err := m.getClient(ctx)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, m.getTimeout())
+	defer cancel()
+
+	bucket := client.Bucket(m.config.Bucket)
+	if _, err := bucket.Object(dstKey).CopierFrom(bucket.Object(srcKey)).Run(ctx); err != nil {
+		if errors.Is(err, storage.ErrObjectNotExist) {
+			return ErrKeyNotFound
+		}
+		return fmt.Errorf("copying object: %w", err)
+	}
+	return nil
+}
+
+func (m *GcsManager) Move(ctx context.Context, srcKey, dstKey string) error {
+	return moveByCopy(ctx, m, srcKey, dstKey)
+}
+
+// Compose concatenates objects server-side. Since GCS composes up to 32 objects at once, more sources are composed
+// incrementally into a temporary object, appending them in batches, which is then copied to the destination object,
+// so that the latter is never left half-composed.
+func (m *GcsManager) Compose(ctx context.Context, dstKey string, srcKeys ...string) error {
+	if err := validateCompose(dstKey, srcKeys); err != nil {
+		return err
+	}
+	client, err := m.getClient(ctx)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, m.getTimeout())
+	defer cancel()
+
+	bucket := client.Bucket(m.config.Bucket)
+	dst := bucket.Object(dstKey)
+	if len(srcKeys) > gcsMaxComposeSources {
+		dst = bucket.Object(dstKey + ".compose-" + uuid.NewString())
+		defer func() {
+			deleteCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), m.getTimeout())
+			defer cancel()
+			_ = dst.Delete(deleteCtx)
+		}()
+	}
+	var srcs []*storage.ObjectHandle
+	for i, key := range srcKeys {
+		srcs = append(srcs, bucket.Object(key))
+		if len(srcs) < gcsMaxComposeSources && i < len(srcKeys)-1 {
+			continue
+		}
+		if _, err := dst.ComposerFrom(srcs...).Run(ctx); err != nil {
+			if errors.Is(err, storage.ErrObjectNotExist) {
+				return ErrKeyNotFound
+			}
+			return fmt.Errorf("composing objects: %w", err)
+		}
+		srcs = []*storage.ObjectHandle{dst}
+	}
+	if dst.ObjectName() != dstKey {
+		if _, err := bucket.Object(dstKey).CopierFrom(dst).Run(ctx); err != nil {
+			return fmt.Errorf("copying composed object: %w", err)
+		}
+	}
+	return nil
+}
+
+// PresignGet returns a V4 signed URL for downloading an object.
+// Signing requires service account credentials, or the iam.serviceAccounts.signBlob permission.
+func (m *GcsManager) PresignGet(ctx context.Context, key string, ttl time.Duration) (string, error) {
+	return m.presign(ctx, http.MethodGet, key, ttl)
+}
+
+// PresignPut returns a V4 signed URL for uploading an object.
+// Signing requires service account credentials, or the iam.serviceAccounts.signBlob permission.
+func (m *GcsManager) PresignPut(ctx context.Context, key string, ttl time.Duration) (string, error) {
+	return m.presign(ctx, http.MethodPut, key, ttl)
+}
+
+func (m *GcsManager) presign(ctx context.Context, method, key string, ttl time.Duration) (string, error) {
+	if err := validatePresign(method, ttl); err != nil {
+		return "", err
+	}
+	client, err := m.getClient(ctx)
+	if err != nil {
+		return "", err
+	}
+	signedURL, err := client.Bucket(m.config.Bucket).SignedURL(key, &storage.SignedURLOptions{
+		Scheme:  storage.SigningSchemeV4,
+		Method:  method,
+		Expires: time.Now().Add(ttl),
+	})
+	if err != nil {
+		return "", fmt.Errorf("signing %s url: %w", method, err)
+	}
+	return signedURL, nil
+}
+
 func (m *GcsManager) Delete(ctx context.Context, keys []string) error {
 	client, err := m.getClient(ctx)
 	if err != nil {
@@ -309,8 +486,21 @@ func (l *gcsListSession) Next() ([]*FileInfo, error) {
 			}
 			break
 		}
-		fileObjects = append(fileObjects, &FileInfo{attrs.Name, attrs.Updated})
+		fileObjects = append(fileObjects, gcsFileInfo(attrs))
 		maxItems--
 	}
 	return fileObjects, err
 }
+
+func gcsFileInfo(attrs *storage.ObjectAttrs) *FileInfo {
+	return &FileInfo{
+		Key:          attrs.Name,
+		LastModified: attrs.Updated,
+		Size:         attrs.Size,
+		ETag:         trimETag(attrs.Etag),
+		MD5:          hex.EncodeToString(attrs.MD5),
+		ContentType:  attrs.ContentType,
+		StorageClass: attrs.StorageClass,
+		Metadata:     normalizeMetadata(attrs.Metadata),
+	}
+}
@@ -0,0 +1,257 @@
+package filemanager
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sync/atomic"
+	"time"
+
+	"github.com/rudderlabs/rudder-go-kit/stats"
+)
+
+// WithInstrumentation wraps a file manager so that its operations report the following metrics, tagged with the
+// provider and the operation:
+//   - filemanager_operation_duration (timer): the latency of the operation, also tagged with the class of its error
+//     (see ErrorClass)
+//   - filemanager_bytes_transferred (counter): the number of bytes uploaded or downloaded, also tagged with the
+//     direction of the transfer
+//
+// Bytes read through OpenReader are reported once the reader is closed.
+func WithInstrumentation(fm FileManager, s stats.Stats) FileManager {
+	return &instrumentedManager{FileManager: fm, stats: s, provider: providerOf(fm)}
+}
+
+type instrumentedManager struct {
+	FileManager
+	stats    stats.Stats
+	provider string
+}
+
+func (m *instrumentedManager) ListFilesWithPrefix(ctx context.Context, startAfter, prefix string, maxItems int64) ListSession {
+	return &instrumentedListSession{
+		ListSession: m.FileManager.ListFilesWithPrefix(ctx, startAfter, prefix, maxItems),
+		manager:     m,
+	}
+}
+
+func (m *instrumentedManager) Download(ctx context.Context, w io.WriterAt, key string, opts ...DownloadOption) error {
+	start := time.Now()
+	cw := &countingWriterAt{WriterAt: w}
+	err := m.FileManager.Download(ctx, cw, key, opts...)
+	m.transferred("download", "download", cw.n.Load())
+	return m.observe("download", start, err)
+}
+
+func (m *instrumentedManager) OpenReader(ctx context.Context, key string, opts ...DownloadOption) (io.ReadCloser, error) {
+	start := time.Now()
+	r, err := m.FileManager.OpenReader(ctx, key, opts...)
+	if err := m.observe("open_reader", start, err); err != nil {
+		return nil, err
+	}
+	return &countingReadCloser{ReadCloser: r, onClose: func(n int64) {
+		m.transferred("open_reader", "download", n)
+	}}, nil
+}
+
+func (m *instrumentedManager) Upload(ctx context.Context, f *os.File, prefixes ...string) (UploadedFile, error) {
+	start := time.Now()
+	var size int64
+	if info, err := f.Stat(); err == nil {
+		offset, _ := f.Seek(0, io.SeekCurrent)
+		size = info.Size() - offset
+	}
+	uploaded, err := m.FileManager.Upload(ctx, f, prefixes...)
+	if err == nil {
+		m.transferred("upload", "upload", size)
+	}
+	return uploaded, m.observe("upload", start, err)
+}
+
+// UploadReader measures the size of seekable readers upfront rather than counting the bytes read, since wrapping
+// them would hide their io.Seeker and io.ReaderAt implementations, which are used for retrying uploads and for
+// uploading parts in parallel.
+func (m *instrumentedManager) UploadReader(ctx context.Context, objName string, rdr io.Reader, opts ...UploadOption) (UploadedFile, error) {
+	start := time.Now()
+	size, err := remainingSize(rdr)
+	if err != nil {
+		return UploadedFile{}, m.observe("upload_reader", start, err)
+	}
+	var cr *countingReader
+	if size < 0 {
+		cr = &countingReader{Reader: rdr}
+		rdr = cr
+	}
+	uploaded, err := m.FileManager.UploadReader(ctx, objName, rdr, opts...)
+	if err == nil {
+		if cr != nil {
+			size = cr.n
+		}
+		m.transferred("upload_reader", "upload", size)
+	}
+	return uploaded, m.observe("upload_reader", start, err)
+}
+
+// remainingSize returns the number of bytes left to read from a seekable reader, or -1 if it isn't seekable
+func remainingSize(r io.Reader) (int64, error) {
+	seeker, ok := r.(io.Seeker)
+	if !ok {
+		return -1, nil
+	}
+	offset, err := seeker.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return -1, nil // not actually seekable, e.g. a pipe
+	}
+	end, err := seeker.Seek(0, io.SeekEnd)
+	if err != nil {
+		return -1, nil
+	}
+	if _, err := seeker.Seek(offset, io.SeekStart); err != nil {
+		return 0, fmt.Errorf("rewinding reader: %w", err)
+	}
+	return end - offset, nil
+}
+
+func (m *instrumentedManager) Delete(ctx context.Context, keys []string) error {
+	start := time.Now()
+	return m.observe("delete", start, m.FileManager.Delete(ctx, keys))
+}
+
+func (m *instrumentedManager) Stat(ctx context.Context, key string) (*FileInfo, error) {
+	start := time.Now()
+	info, err := m.FileManager.Stat(ctx, key)
+	return info, m.observe("stat", start, err)
+}
+
+func (m *instrumentedManager) Copy(ctx context.Context, srcKey, dstKey string) error {
+	start := time.Now()
+	return m.observe("copy", start, m.FileManager.Copy(ctx, srcKey, dstKey))
+}
+
+func (m *instrumentedManager) Move(ctx context.Context, srcKey, dstKey string) error {
+	start := time.Now()
+	return m.observe("move", start, m.FileManager.Move(ctx, srcKey, dstKey))
+}
+
+func (m *instrumentedManager) Compose(ctx context.Context, dstKey string, srcKeys ...string) error {
+	start := time.Now()
+	return m.observe("compose", start, m.FileManager.Compose(ctx, dstKey, srcKeys...))
+}
+
+func (m *instrumentedManager) PresignGet(ctx context.Context, key string, ttl time.Duration) (string, error) {
+	start := time.Now()
+	u, err := m.FileManager.PresignGet(ctx, key, ttl)
+	return u, m.observe("presign_get", start, err)
+}
+
+func (m *instrumentedManager) PresignPut(ctx context.Context, key string, ttl time.Duration) (string, error) {
+	start := time.Now()
+	u, err := m.FileManager.PresignPut(ctx, key, ttl)
+	return u, m.observe("presign_put", start, err)
+}
+
+// observe records the duration of an operation started at the given time along with the class of its error, which
+// is returned as is
+func (m *instrumentedManager) observe(operation string, start time.Time, err error) error {
+	m.stats.NewTaggedStat("filemanager_operation_duration", stats.TimerType, stats.Tags{
+		"provider":    m.provider,
+		"operation":   operation,
+		"error_class": ErrorClass(err),
+	}).Since(start)
+	return err
+}
+
+// transferred records the number of bytes uploaded or downloaded by an operation
+func (m *instrumentedManager) transferred(operation, direction string, n int64) {
+	if n <= 0 {
+		return
+	}
+	m.stats.NewTaggedStat("filemanager_bytes_transferred", stats.CountType, stats.Tags{
+		"provider":  m.provider,
+		"operation": operation,
+		"direction": direction,
+	}).Count(int(n))
+}
+
+type instrumentedListSession struct {
+	ListSession
+	manager *instrumentedManager
+}
+
+func (l *instrumentedListSession) Next() ([]*FileInfo, error) {
+	start := time.Now()
+	files, err := l.ListSession.Next()
+	return files, l.manager.observe("list", start, err)
+}
+
+// countingWriterAt counts the bytes written, which can happen concurrently (e.g. S3 downloads)
+type countingWriterAt struct {
+	io.WriterAt
+	n atomic.Int64
+}
+
+func (w *countingWriterAt) WriteAt(p []byte, off int64) (int, error) {
+	n, err := w.WriterAt.WriteAt(p, off)
+	w.n.Add(int64(n))
+	return n, err
+}
+
+type countingReader struct {
+	io.Reader
+	n int64
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.Reader.Read(p)
+	r.n += int64(n)
+	return n, err
+}
+
+// countingReadCloser counts the bytes read, passing them to onClose once closed
+type countingReadCloser struct {
+	io.ReadCloser
+	n       int64
+	onClose func(n int64)
+	closed  bool
+}
+
+func (r *countingReadCloser) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	r.n += int64(n)
+	return n, err
+}
+
+func (r *countingReadCloser) Close() error {
+	if !r.closed {
+		r.closed = true
+		r.onClose(r.n)
+	}
+	return r.ReadCloser.Close()
+}
+
+// providerOf returns the name of the provider of a file manager, looking through decorators
+func providerOf(fm FileManager) string {
+	switch m := fm.(type) {
+	case *S3Manager:
+		return "S3"
+	case *GcsManager:
+		return "GCS"
+	case *AzureBlobManager:
+		return "AZURE_BLOB"
+	case *MinioManager:
+		return "MINIO"
+	case *digitalOceanManager:
+		return "DIGITAL_OCEAN_SPACES"
+	case *LocalManager:
+		return "LOCAL"
+	case *instrumentedManager:
+		return m.provider
+	case *retryingManager:
+		return providerOf(m.FileManager)
+	case *encodingManager:
+		return providerOf(m.FileManager)
+	default:
+		return "unknown"
+	}
+}
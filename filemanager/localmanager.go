@@ -0,0 +1,521 @@
+package filemanager
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"math"
+	"mime"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mitchellh/mapstructure"
+	"github.com/samber/lo"
+
+	obskit "github.com/rudderlabs/rudder-observability-kit/go/labels"
+
+	"github.com/rudderlabs/rudder-go-kit/jsonrs"
+	"github.com/rudderlabs/rudder-go-kit/logger"
+)
+
+const (
+	// localTempSuffix is the suffix of the hidden temporary files used while uploading
+	localTempSuffix = ".uploading"
+	// localMetadataSuffix is the suffix of the hidden files holding the content type and metadata of uploaded files
+	localMetadataSuffix = ".metadata"
+)
+
+type LocalConfig struct {
+	Directory string `mapstructure:"directory"`
+	Prefix    string `mapstructure:"prefix"`
+}
+
+// LocalManager is a file manager storing objects as files below a local directory.
+// Object keys are slash separated paths relative to the directory.
+type LocalManager struct {
+	*baseManager
+	config *LocalConfig
+
+	commitMu sync.Mutex // serialises the final step of uploads, for IfMatch conditions to be checked atomically
+}
+
+// NewLocalManager creates a new file manager for a local directory
+func NewLocalManager(config map[string]any, log logger.Logger, defaultTimeout func() time.Duration) (*LocalManager, error) {
+	var localConfig LocalConfig
+	if err := mapstructure.Decode(config, &localConfig); err != nil {
+		return nil, fmt.Errorf("failed to decode local config: %w", err)
+	}
+	if localConfig.Directory == "" {
+		return nil, errors.New("no directory configured")
+	}
+	directory, err := filepath.Abs(localConfig.Directory)
+	if err != nil {
+		return nil, fmt.Errorf("resolving directory %q: %w", localConfig.Directory, err)
+	}
+	localConfig.Directory = directory
+	localConfig.Prefix = sanitizeKey(localConfig.Prefix)
+
+	return &LocalManager{
+		baseManager: &baseManager{
+			logger:         log,
+			defaultTimeout: defaultTimeout,
+		},
+		config: &localConfig,
+	}, nil
+}
+
+func (m *LocalManager) ListFilesWithPrefix(ctx context.Context, startAfter, prefix string, maxItems int64) ListSession {
+	return &localListSession{
+		baseListSession: &baseListSession{
+			ctx:        ctx,
+			startAfter: startAfter,
+			prefix:     sanitizeKey(prefix),
+			maxItems:   maxItems,
+		},
+		manager: m,
+	}
+}
+
+// Download retrieves an object with the given key and writes it to the provided writer.
+// Pass *os.File as output to write the downloaded file on disk.
+func (m *LocalManager) Download(ctx context.Context, output io.WriterAt, key string, opts ...DownloadOption) error {
+	r, err := m.OpenReader(ctx, key, opts...)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = r.Close() }()
+
+	_, err = io.Copy(&writerAtAdapter{w: m.progressWriterAt("download", key, output)}, r)
+	return err
+}
+
+func (m *LocalManager) OpenReader(ctx context.Context, key string, opts ...DownloadOption) (io.ReadCloser, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	downloadOpts := applyDownloadOptions(opts...)
+
+	f, err := os.Open(m.filePath(key))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, ErrKeyNotFound
+		}
+		return nil, err
+	}
+	if !downloadOpts.isRangeRequest {
+		return f, nil
+	}
+	length := downloadOpts.length
+	if length <= 0 {
+		length = math.MaxInt64 - downloadOpts.offset
+	}
+	return struct {
+		io.Reader
+		io.Closer
+	}{io.NewSectionReader(f, downloadOpts.offset, length), f}, nil
+}
+
+func (m *LocalManager) Upload(ctx context.Context, file *os.File, prefixes ...string) (UploadedFile, error) {
+	objName := path.Join(m.config.Prefix, path.Join(prefixes...), path.Base(file.Name()))
+	return m.UploadReader(ctx, objName, file)
+}
+
+// UploadReader writes the reader's content to a temporary file which is then renamed to the object's path,
+// so that readers never observe a partially written object.
+// The content type, metadata and MD5 digest of the file are stored in a hidden file next to it.
+//
+// IfNoneMatch uploads are atomic even across processes sharing the directory, while IfMatch uploads are atomic only
+// with respect to other uploads of the same manager.
+func (m *LocalManager) UploadReader(ctx context.Context, objName string, rdr io.Reader, opts ...UploadOption) (UploadedFile, error) {
+	objName = cleanLocalKey(objName)
+	if objName == "" {
+		return UploadedFile{}, errors.New("object name cannot be empty")
+	}
+	if err := ctx.Err(); err != nil {
+		return UploadedFile{}, err
+	}
+	uploadOpts, err := applyUploadOptions(opts...)
+	if err != nil {
+		return UploadedFile{}, err
+	}
+
+	filePath := m.filePath(objName)
+	if err := os.MkdirAll(filepath.Dir(filePath), 0o755); err != nil {
+		return UploadedFile{}, fmt.Errorf("creating directory: %w", err)
+	}
+	hash := md5.New()
+	tmpPath, err := writeTempFile(filePath, io.TeeReader(m.progressReader("upload", objName, rdr), hash))
+	if err != nil {
+		return UploadedFile{}, err
+	}
+	defer func() { _ = os.Remove(tmpPath) }() // no-op once renamed
+
+	metadata, err := jsonrs.Marshal(localMetadata{
+		ContentType: uploadOpts.contentType,
+		Metadata:    uploadOpts.metadata,
+		MD5:         hex.EncodeToString(hash.Sum(nil)),
+	})
+	if err != nil {
+		return UploadedFile{}, fmt.Errorf("marshalling metadata: %w", err)
+	}
+	metadataTmpPath, err := writeTempFile(localMetadataPath(filePath), bytes.NewReader(metadata))
+	if err != nil {
+		return UploadedFile{}, err
+	}
+	defer func() { _ = os.Remove(metadataTmpPath) }()
+
+	m.commitMu.Lock()
+	defer m.commitMu.Unlock()
+	switch {
+	case uploadOpts.ifNoneMatch != "":
+		// contrary to renaming, linking fails if the file already exists
+		if err := os.Link(tmpPath, filePath); err != nil {
+			if errors.Is(err, fs.ErrExist) {
+				return UploadedFile{}, ErrPreConditionFailed
+			}
+			return UploadedFile{}, fmt.Errorf("linking temporary file: %w", err)
+		}
+		if err := commitLocalMetadata(metadataTmpPath, filePath); err != nil {
+			return UploadedFile{}, err
+		}
+		return UploadedFile{Location: filePath, ObjectName: objName}, nil
+	case uploadOpts.ifMatch != "":
+		info, err := os.Stat(filePath)
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return UploadedFile{}, ErrPreConditionFailed
+			}
+			return UploadedFile{}, err
+		}
+		if localETag(info) != uploadOpts.ifMatch {
+			return UploadedFile{}, ErrPreConditionFailed
+		}
+	}
+	// the data is renamed first, so that a failure leaves the previous file and its metadata untouched
+	if err := os.Rename(tmpPath, filePath); err != nil {
+		return UploadedFile{}, fmt.Errorf("renaming temporary file: %w", err)
+	}
+	if err := commitLocalMetadata(metadataTmpPath, filePath); err != nil {
+		return UploadedFile{}, err
+	}
+	return UploadedFile{Location: filePath, ObjectName: objName}, nil
+}
+
+// commitLocalMetadata renames the temporary metadata file into place, after the file it describes has been.
+// If renaming fails, the previous metadata file is removed, since it doesn't describe the new file anymore.
+func commitLocalMetadata(metadataTmpPath, filePath string) error {
+	if err := os.Rename(metadataTmpPath, localMetadataPath(filePath)); err != nil {
+		if removeErr := os.Remove(localMetadataPath(filePath)); removeErr != nil && !errors.Is(removeErr, fs.ErrNotExist) {
+			err = errors.Join(err, removeErr)
+		}
+		return fmt.Errorf("renaming temporary metadata file: %w", err)
+	}
+	return nil
+}
+
+// writeTempFile writes the reader's content to a new hidden temporary file, next to the given path, returning the
+// temporary file's path
+func writeTempFile(filePath string, r io.Reader) (string, error) {
+	tmp, err := os.CreateTemp(filepath.Dir(filePath), "."+filepath.Base(filePath)+".*"+localTempSuffix)
+	if err != nil {
+		return "", fmt.Errorf("creating temporary file: %w", err)
+	}
+	if _, err := io.Copy(tmp, r); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+		return "", fmt.Errorf("copying to temporary file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		_ = os.Remove(tmp.Name())
+		return "", fmt.Errorf("closing temporary file: %w", err)
+	}
+	if err := os.Chmod(tmp.Name(), 0o644); err != nil {
+		_ = os.Remove(tmp.Name())
+		return "", fmt.Errorf("setting file mode: %w", err)
+	}
+	return tmp.Name(), nil
+}
+
+// Stat returns information about the file with the given key
+func (m *LocalManager) Stat(ctx context.Context, key string) (*FileInfo, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	filePath := m.filePath(key)
+	info, err := os.Stat(filePath)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, ErrKeyNotFound
+		}
+		return nil, err
+	}
+	if info.IsDir() {
+		return nil, ErrKeyNotFound
+	}
+	return localFileInfo(cleanLocalKey(key), filePath, info)
+}
+
+func (m *LocalManager) Copy(ctx context.Context, srcKey, dstKey string) error {
+	return streamCopy(ctx, m, srcKey, dstKey)
+}
+
+// Move renames the file, along with its metadata file
+func (m *LocalManager) Move(ctx context.Context, srcKey, dstKey string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	srcPath, dstPath := m.filePath(srcKey), m.filePath(dstKey)
+	if info, err := os.Stat(srcPath); err != nil || info.IsDir() {
+		if err == nil || errors.Is(err, fs.ErrNotExist) {
+			return ErrKeyNotFound
+		}
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(dstPath), 0o755); err != nil {
+		return fmt.Errorf("creating directory: %w", err)
+	}
+
+	m.commitMu.Lock()
+	defer m.commitMu.Unlock()
+	// the data is renamed first, like in UploadReader, and renamed back if its metadata can't follow it
+	if err := os.Rename(srcPath, dstPath); err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return ErrKeyNotFound
+		}
+		return fmt.Errorf("renaming file: %w", err)
+	}
+	if err := moveLocalMetadata(srcPath, dstPath); err != nil {
+		if rollbackErr := os.Rename(dstPath, srcPath); rollbackErr != nil {
+			err = errors.Join(err, fmt.Errorf("renaming file back: %w", rollbackErr))
+		}
+		return err
+	}
+	return nil
+}
+
+// moveLocalMetadata renames the metadata file of srcPath to the one of dstPath, deleting the latter if srcPath has no
+// metadata
+func moveLocalMetadata(srcPath, dstPath string) error {
+	if err := os.Rename(localMetadataPath(srcPath), localMetadataPath(dstPath)); err != nil {
+		if !errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("renaming metadata file: %w", err)
+		}
+		// the source has no metadata, thus the destination mustn't keep any stale one
+		if err := os.Remove(localMetadataPath(dstPath)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("deleting stale metadata file: %w", err)
+		}
+	}
+	return nil
+}
+
+func (m *LocalManager) Compose(ctx context.Context, dstKey string, srcKeys ...string) error {
+	srcKeys = lo.Map(srcKeys, func(key string, _ int) string { return cleanLocalKey(key) })
+	if err := validateCompose(cleanLocalKey(dstKey), srcKeys); err != nil {
+		return err
+	}
+	return streamCompose(ctx, m, dstKey, srcKeys)
+}
+
+// PresignGet is not supported, since local files can't be served through URLs
+func (*LocalManager) PresignGet(context.Context, string, time.Duration) (string, error) {
+	return "", fmt.Errorf("%w: presigning local files", ErrNotSupported)
+}
+
+// PresignPut is not supported, since local files can't be served through URLs
+func (*LocalManager) PresignPut(context.Context, string, time.Duration) (string, error) {
+	return "", fmt.Errorf("%w: presigning local files", ErrNotSupported)
+}
+
+// Delete removes the files with the given keys, ignoring keys which don't exist
+func (m *LocalManager) Delete(ctx context.Context, keys []string) error {
+	for _, key := range keys {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		filePath := m.filePath(key)
+		if err := os.Remove(filePath); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("deleting %q: %w", key, err)
+		}
+		if err := os.Remove(localMetadataPath(filePath)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("deleting metadata of %q: %w", key, err)
+		}
+	}
+	return nil
+}
+
+func (m *LocalManager) Prefix() string {
+	return m.config.Prefix
+}
+
+/*
+GetObjectNameFromLocation gets the object name/key name from the object location
+
+	/directory/key1 - >> key1
+	file:///directory/key2 - >> key2
+*/
+func (m *LocalManager) GetObjectNameFromLocation(location string) (string, error) {
+	location = filepath.Clean(strings.TrimPrefix(location, "file://"))
+	rel, err := filepath.Rel(m.config.Directory, location)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("location %q is outside of directory %q", location, m.config.Directory)
+	}
+	return filepath.ToSlash(rel), nil
+}
+
+func (m *LocalManager) GetDownloadKeyFromFileLocation(location string) string {
+	key, err := m.GetObjectNameFromLocation(location)
+	if err != nil {
+		m.logger.Errorn("error while getting download key from location", obskit.Error(err))
+		return ""
+	}
+	return key
+}
+
+// filePath returns the path of the file for the given key, which can never point outside the directory
+func (m *LocalManager) filePath(key string) string {
+	return filepath.Join(m.config.Directory, filepath.FromSlash(cleanLocalKey(key)))
+}
+
+// localMetadata is the content of the hidden metadata files
+type localMetadata struct {
+	ContentType string            `json:"contentType,omitempty"`
+	Metadata    map[string]string `json:"metadata,omitempty"`
+	MD5         string            `json:"md5,omitempty"`
+}
+
+func localMetadataPath(filePath string) string {
+	return filepath.Join(filepath.Dir(filePath), "."+filepath.Base(filePath)+localMetadataSuffix)
+}
+
+// isLocalHiddenFile returns true for temporary and metadata files, which are never listed
+func isLocalHiddenFile(name string) bool {
+	return strings.HasPrefix(name, ".") &&
+		(strings.HasSuffix(name, localTempSuffix) || strings.HasSuffix(name, localMetadataSuffix))
+}
+
+// localETag derives the entity tag of a file from its modification time and size, so that it changes even if the
+// file is modified without the file manager
+func localETag(info fs.FileInfo) string {
+	return fmt.Sprintf("%x-%x", info.ModTime().UnixNano(), info.Size())
+}
+
+// localFileInfo builds the FileInfo of a file, reading its metadata file if any
+func localFileInfo(key, filePath string, info fs.FileInfo) (*FileInfo, error) {
+	fileInfo := &FileInfo{
+		Key:          key,
+		LastModified: info.ModTime(),
+		Size:         info.Size(),
+		ETag:         localETag(info),
+		ContentType:  mime.TypeByExtension(path.Ext(key)),
+	}
+	data, err := os.ReadFile(localMetadataPath(filePath))
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("reading metadata of %q: %w", key, err)
+	}
+	if err == nil {
+		var metadata localMetadata
+		if err := jsonrs.Unmarshal(data, &metadata); err != nil {
+			return nil, fmt.Errorf("unmarshalling metadata of %q: %w", key, err)
+		}
+		fileInfo.MD5 = metadata.MD5
+		fileInfo.Metadata = normalizeMetadata(metadata.Metadata)
+		if metadata.ContentType != "" {
+			fileInfo.ContentType = metadata.ContentType
+		}
+	}
+	if fileInfo.ContentType == "" {
+		fileInfo.ContentType = "application/octet-stream"
+	}
+	return fileInfo, nil
+}
+
+// cleanLocalKey returns the shortest key equivalent to the given one, resolving any .. element against the root
+// of the directory, e.g. ../a/./b becomes a/b
+func cleanLocalKey(key string) string {
+	return strings.TrimPrefix(path.Clean("/"+key), "/")
+}
+
+type localListSession struct {
+	*baseListSession
+	manager *LocalManager
+
+	files  []*FileInfo // files left to be returned, sorted lexicographically by key
+	listed bool
+}
+
+func (l *localListSession) Next() ([]*FileInfo, error) {
+	if !l.listed {
+		if err := l.list(); err != nil {
+			return nil, err
+		}
+		l.listed = true
+	}
+	if l.maxItems <= 0 || len(l.files) == 0 {
+		return nil, nil
+	}
+
+	n := min(int64(len(l.files)), l.maxItems)
+	fileObjects := l.files[:n:n]
+	l.files = l.files[n:]
+	return fileObjects, nil
+}
+
+// list walks the directory collecting all keys with the session's prefix, coming after startAfter
+func (l *localListSession) list() error {
+	root := l.manager.config.Directory
+	// start walking from the deepest directory included in the prefix
+	walkRoot := root
+	if dir := path.Dir(l.prefix); dir != "." && l.prefix != "" {
+		walkRoot = l.manager.filePath(dir)
+	}
+	err := filepath.WalkDir(walkRoot, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		if err := l.ctx.Err(); err != nil {
+			return err
+		}
+		if d.IsDir() || isLocalHiddenFile(d.Name()) {
+			return nil
+		}
+		rel, err := filepath.Rel(root, p)
+		if err != nil {
+			return err
+		}
+		key := filepath.ToSlash(rel)
+		if !strings.HasPrefix(key, l.prefix) || key <= l.startAfter {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) { // deleted while walking
+				return nil
+			}
+			return err
+		}
+		fileInfo, err := localFileInfo(key, p, info)
+		if err != nil {
+			return err
+		}
+		l.files = append(l.files, fileInfo)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("listing files: %w", err)
+	}
+	slices.SortFunc(l.files, func(a, b *FileInfo) int { return strings.Compare(a.Key, b.Key) })
+	return nil
+}
@@ -0,0 +1,318 @@
+package filemanager_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/rudderlabs/rudder-go-kit/filemanager"
+	"github.com/rudderlabs/rudder-go-kit/logger"
+)
+
+func TestLocalManager(t *testing.T) {
+	dir := t.TempDir()
+	fm, err := filemanager.New(&filemanager.Settings{
+		Provider: "LOCAL",
+		Config:   map[string]any{"directory": dir},
+		Logger:   logger.NOP,
+	})
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	upload := func(t *testing.T, key, content string) filemanager.UploadedFile {
+		t.Helper()
+		uploaded, err := fm.UploadReader(ctx, key, strings.NewReader(content))
+		require.NoError(t, err)
+		return uploaded
+	}
+
+	t.Run("listing", func(t *testing.T) {
+		for _, key := range []string{"a/3", "a/1", "a-b", "a/2/x", "b/1"} {
+			upload(t, "list/"+key, key)
+		}
+
+		list := func(startAfter, prefix string, maxItems int64) (pages [][]string) {
+			session := fm.ListFilesWithPrefix(ctx, startAfter, prefix, maxItems)
+			for {
+				files, err := session.Next()
+				require.NoError(t, err)
+				if len(files) == 0 {
+					return pages
+				}
+				var page []string
+				for _, f := range files {
+					require.False(t, f.LastModified.IsZero())
+					page = append(page, f.Key)
+				}
+				pages = append(pages, page)
+			}
+		}
+
+		require.Equal(t, [][]string{{"list/a-b", "list/a/1"}, {"list/a/2/x", "list/a/3"}, {"list/b/1"}}, list("", "list/", 2))
+		require.Equal(t, [][]string{{"list/a/1", "list/a/2/x", "list/a/3"}}, list("", "list/a/", 10))
+		require.Equal(t, [][]string{{"list/a/3", "list/b/1"}}, list("list/a/2/x", "list/", 10))
+		require.Empty(t, list("", "missing/", 10))
+	})
+
+	t.Run("ranged download", func(t *testing.T) {
+		key := upload(t, "range/file", "0123456789").ObjectName
+
+		download := func(opts ...filemanager.DownloadOption) string {
+			f, err := os.Create(filepath.Join(t.TempDir(), "download"))
+			require.NoError(t, err)
+			defer func() { _ = f.Close() }()
+			require.NoError(t, fm.Download(ctx, f, key, opts...))
+			data, err := os.ReadFile(f.Name())
+			require.NoError(t, err)
+			return string(data)
+		}
+		require.Equal(t, "0123456789", download())
+		require.Equal(t, "234", download(filemanager.WithDownloadOffSetAndLength(2, 3)))
+		require.Equal(t, "789", download(filemanager.WithDownloadOffSet(7)))
+		require.Equal(t, "89", download(filemanager.WithDownloadOffSetAndLength(8, 100)))
+
+		err := fm.Download(ctx, &os.File{}, "range/missing")
+		require.ErrorIs(t, err, filemanager.ErrKeyNotFound)
+
+		r, err := fm.OpenReader(ctx, key, filemanager.WithDownloadOffSetAndLength(3, 4))
+		require.NoError(t, err)
+		data, err := io.ReadAll(r)
+		require.NoError(t, err)
+		require.NoError(t, r.Close())
+		require.Equal(t, "3456", string(data))
+	})
+
+	t.Run("atomic upload", func(t *testing.T) {
+		upload(t, "atomic/file", "old")
+		_, err := fm.UploadReader(ctx, "atomic/file", io.MultiReader(strings.NewReader("new"), failingReader{}))
+		require.Error(t, err)
+
+		data, err := os.ReadFile(filepath.Join(dir, "atomic", "file"))
+		require.NoError(t, err)
+		require.Equal(t, "old", string(data), "a failed upload should leave the previous object untouched")
+
+		entries, err := os.ReadDir(filepath.Join(dir, "atomic"))
+		require.NoError(t, err)
+		for _, entry := range entries {
+			require.NotContains(t, entry.Name(), ".uploading", "temporary files should be cleaned up")
+		}
+
+		// renaming the metadata fails when its path is a directory
+		upload(t, "atomic/metadata", "old")
+		metadataPath := filepath.Join(dir, "atomic", ".metadata.metadata")
+		require.NoError(t, os.Remove(metadataPath))
+		require.NoError(t, os.Mkdir(metadataPath, 0o755))
+		_, err = fm.UploadReader(ctx, "atomic/metadata", strings.NewReader("newer"), filemanager.WithContentType("text/csv"))
+		require.ErrorContains(t, err, "renaming temporary metadata file")
+		require.NoFileExists(t, metadataPath)
+		require.NoDirExists(t, metadataPath, "metadata not describing the file should be removed")
+		info, err := fm.Stat(ctx, "atomic/metadata")
+		require.NoError(t, err)
+		require.EqualValues(t, 5, info.Size, "the file should be renamed before its metadata")
+	})
+
+	t.Run("stat", func(t *testing.T) {
+		_, err := fm.UploadReader(ctx, "stat/file.json", strings.NewReader("{}"),
+			filemanager.WithContentType("application/x-ndjson"),
+			filemanager.WithMetadata(map[string]string{"Owner": "test"}),
+		)
+		require.NoError(t, err)
+		upload(t, "stat/other.json", "[]")
+
+		info, err := fm.Stat(ctx, "stat/file.json")
+		require.NoError(t, err)
+		require.Equal(t, "stat/file.json", info.Key)
+		require.EqualValues(t, 2, info.Size)
+		require.Equal(t, "99914b932bd37a50b983c5e7c90ae93b", info.MD5)
+		require.NotEmpty(t, info.ETag)
+		require.Equal(t, "application/x-ndjson", info.ContentType)
+		require.Equal(t, map[string]string{"owner": "test"}, info.Metadata)
+
+		files, err := fm.ListFilesWithPrefix(ctx, "", "stat/", 10).Next()
+		require.NoError(t, err)
+		require.Len(t, files, 2, "metadata files should not be listed")
+		require.Equal(t, info, files[0])
+		require.Equal(t, "application/json", files[1].ContentType, "content type should default to the extension's one")
+		require.Empty(t, files[1].Metadata)
+
+		_, err = fm.Stat(ctx, "stat/missing")
+		require.ErrorIs(t, err, filemanager.ErrKeyNotFound)
+		_, err = fm.Stat(ctx, "stat")
+		require.ErrorIs(t, err, filemanager.ErrKeyNotFound)
+
+		require.NoError(t, fm.Delete(ctx, []string{"stat/file.json", "stat/other.json"}))
+		entries, err := os.ReadDir(filepath.Join(dir, "stat"))
+		require.NoError(t, err)
+		require.Empty(t, entries, "metadata files should be deleted along with their files")
+	})
+
+	t.Run("conditional upload", func(t *testing.T) {
+		key := "conditional/file"
+		_, err := fm.UploadReader(ctx, key, strings.NewReader("v1"), filemanager.IfNoneMatch("*"))
+		require.NoError(t, err)
+		_, err = fm.UploadReader(ctx, key, strings.NewReader("v2"), filemanager.IfNoneMatch("*"))
+		require.ErrorIs(t, err, filemanager.ErrPreConditionFailed)
+
+		info, err := fm.Stat(ctx, key)
+		require.NoError(t, err)
+		_, err = fm.UploadReader(ctx, key, strings.NewReader("v2"), filemanager.IfMatch("wrong"))
+		require.ErrorIs(t, err, filemanager.ErrPreConditionFailed)
+		_, err = fm.UploadReader(ctx, key, strings.NewReader("v2"), filemanager.IfMatch(info.ETag))
+		require.NoError(t, err)
+		_, err = fm.UploadReader(ctx, key, strings.NewReader("v3"), filemanager.IfMatch(info.ETag))
+		require.ErrorIs(t, err, filemanager.ErrPreConditionFailed, "the entity tag should have changed")
+		_, err = fm.UploadReader(ctx, "conditional/missing", strings.NewReader("v1"), filemanager.IfMatch(info.ETag))
+		require.ErrorIs(t, err, filemanager.ErrPreConditionFailed)
+
+		data, err := os.ReadFile(filepath.Join(dir, "conditional", "file"))
+		require.NoError(t, err)
+		require.Equal(t, "v2", string(data))
+
+		_, err = fm.UploadReader(ctx, key, strings.NewReader("v3"), filemanager.IfNoneMatch("etag"))
+		require.ErrorContains(t, err, "only * is supported")
+		_, err = fm.UploadReader(ctx, key, strings.NewReader("v3"), filemanager.IfNoneMatch("*"), filemanager.IfMatch(info.ETag))
+		require.Error(t, err)
+	})
+
+	t.Run("copy, move and compose", func(t *testing.T) {
+		read := func(t *testing.T, key string) string {
+			t.Helper()
+			r, err := fm.OpenReader(ctx, key)
+			require.NoError(t, err)
+			defer func() { _ = r.Close() }()
+			data, err := io.ReadAll(r)
+			require.NoError(t, err)
+			return string(data)
+		}
+		_, err := fm.UploadReader(ctx, "copy/src", strings.NewReader("hello"),
+			filemanager.WithContentType("text/plain"),
+			filemanager.WithMetadata(map[string]string{"owner": "test"}),
+		)
+		require.NoError(t, err)
+
+		require.NoError(t, fm.Copy(ctx, "copy/src", "copy/dst"))
+		require.Equal(t, "hello", read(t, "copy/src"))
+		require.Equal(t, "hello", read(t, "copy/dst"))
+		info, err := fm.Stat(ctx, "copy/dst")
+		require.NoError(t, err)
+		require.Equal(t, "text/plain", info.ContentType)
+		require.Equal(t, map[string]string{"owner": "test"}, info.Metadata)
+
+		require.NoError(t, fm.Move(ctx, "copy/dst", "copy/moved/dst"))
+		_, err = fm.Stat(ctx, "copy/dst")
+		require.ErrorIs(t, err, filemanager.ErrKeyNotFound)
+		info, err = fm.Stat(ctx, "copy/moved/dst")
+		require.NoError(t, err)
+		require.Equal(t, map[string]string{"owner": "test"}, info.Metadata)
+
+		// renaming the metadata fails when the destination's metadata path is a non-empty directory
+		blocked := filepath.Join(dir, "copy", "blocked", ".dst.metadata")
+		require.NoError(t, os.MkdirAll(filepath.Join(blocked, "child"), 0o755))
+		require.ErrorContains(t, fm.Move(ctx, "copy/moved/dst", "copy/blocked/dst"), "renaming metadata file")
+		info, err = fm.Stat(ctx, "copy/moved/dst")
+		require.NoError(t, err, "the file should be renamed back")
+		require.Equal(t, map[string]string{"owner": "test"}, info.Metadata, "the source should keep its metadata")
+		_, err = fm.Stat(ctx, "copy/blocked/dst")
+		require.ErrorIs(t, err, filemanager.ErrKeyNotFound)
+
+		upload(t, "copy/part2", " world")
+		upload(t, "copy/part3", "!")
+		require.NoError(t, fm.Compose(ctx, "copy/composed", "copy/src", "copy/part2", "copy/part3"))
+		require.Equal(t, "hello world!", read(t, "copy/composed"))
+
+		require.ErrorIs(t, fm.Copy(ctx, "copy/missing", "copy/dst"), filemanager.ErrKeyNotFound)
+		require.ErrorIs(t, fm.Move(ctx, "copy/missing", "copy/dst"), filemanager.ErrKeyNotFound)
+		require.ErrorIs(t, fm.Compose(ctx, "copy/dst", "copy/src", "copy/missing"), filemanager.ErrKeyNotFound)
+		_, err = fm.Stat(ctx, "copy/dst")
+		require.ErrorIs(t, err, filemanager.ErrKeyNotFound, "failed compose should not create the destination")
+		require.Error(t, fm.Compose(ctx, "copy/dst"))
+		require.Error(t, fm.Compose(ctx, "copy/src", "copy/src", "copy/part2"))
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		upload(t, "delete/1", "1")
+		upload(t, "delete/2", "2")
+		upload(t, "delete/3", "3")
+		require.NoError(t, fm.Delete(ctx, []string{"delete/1", "delete/2", "delete/missing"}))
+
+		files, err := fm.ListFilesWithPrefix(ctx, "", "delete/", 10).Next()
+		require.NoError(t, err)
+		require.Len(t, files, 1)
+		require.Equal(t, "delete/3", files[0].Key)
+	})
+
+	t.Run("presign", func(t *testing.T) {
+		_, err := fm.PresignGet(ctx, "presign", time.Minute)
+		require.ErrorIs(t, err, filemanager.ErrNotSupported)
+		_, err = fm.PresignPut(ctx, "presign", time.Minute)
+		require.ErrorIs(t, err, filemanager.ErrNotSupported)
+	})
+
+	t.Run("keys cannot escape the directory", func(t *testing.T) {
+		uploaded := upload(t, "../../escape", "data")
+		require.Equal(t, "escape", uploaded.ObjectName)
+		require.Equal(t, filepath.Join(dir, "escape"), uploaded.Location)
+
+		_, err := fm.GetObjectNameFromLocation(filepath.Join(dir, "..", "other"))
+		require.Error(t, err)
+	})
+}
+
+type failingReader struct{}
+
+func (failingReader) Read([]byte) (int, error) {
+	return 0, errors.New("read failure")
+}
+
+func TestTransferSettings(t *testing.T) {
+	ctx := context.Background()
+	newManager := func(transfer filemanager.TransferSettings) (filemanager.FileManager, error) {
+		return filemanager.New(&filemanager.Settings{
+			Provider: "LOCAL",
+			Config:   map[string]any{"directory": t.TempDir()},
+			Logger:   logger.NOP,
+			Transfer: transfer,
+		})
+	}
+
+	t.Run("progress", func(t *testing.T) {
+		var progress []filemanager.TransferProgress
+		fm, err := newManager(filemanager.TransferSettings{
+			PartSize:          5 * 1024 * 1024,
+			UploadConcurrency: 4,
+			MaxInFlightMemory: 10 * 1024 * 1024,
+			OnProgress:        func(p filemanager.TransferProgress) { progress = append(progress, p) },
+		})
+		require.NoError(t, err)
+
+		content := strings.Repeat("x", 100*1024)
+		_, err = fm.UploadReader(ctx, "some/key", strings.NewReader(content))
+		require.NoError(t, err)
+		require.NotEmpty(t, progress)
+		require.Equal(t, filemanager.TransferProgress{Operation: "upload", Key: "some/key", Bytes: int64(len(content))}, progress[len(progress)-1])
+
+		progress = nil
+		f, err := os.CreateTemp(t.TempDir(), "download")
+		require.NoError(t, err)
+		defer func() { _ = f.Close() }()
+		require.NoError(t, fm.Download(ctx, f, "some/key"))
+		require.NotEmpty(t, progress)
+		require.Equal(t, filemanager.TransferProgress{Operation: "download", Key: "some/key", Bytes: int64(len(content))}, progress[len(progress)-1])
+	})
+
+	t.Run("validation", func(t *testing.T) {
+		_, err := newManager(filemanager.TransferSettings{PartSize: 1024})
+		require.Error(t, err)
+		_, err = newManager(filemanager.TransferSettings{UploadConcurrency: -1})
+		require.Error(t, err)
+		_, err = newManager(filemanager.TransferSettings{MaxInFlightMemory: -1})
+		require.Error(t, err)
+	})
+}
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"net/http"
 	"net/url"
 	"os"
 	"path"
@@ -63,18 +64,12 @@ func (m *MinioManager) Download(ctx context.Context, output io.WriterAt, key str
 	ctx, cancel := context.WithTimeout(ctx, m.getTimeout())
 	defer cancel()
 
-	getObjectOpts := minio.GetObjectOptions{}
-	if downloadOpts.isRangeRequest {
-		end := int64(0)
-		if downloadOpts.length > 0 {
-			end = downloadOpts.offset + downloadOpts.length - 1
-		}
-		if err := getObjectOpts.SetRange(downloadOpts.offset, end); err != nil {
-			return fmt.Errorf("setting range for minio download: %w", err)
-		}
+	getObjectOpts, err := minioGetObjectOptions(downloadOpts)
+	if err != nil {
+		return err
 	}
 
-	if file, ok := output.(*os.File); ok {
+	if file, ok := output.(*os.File); ok && m.transfer.OnProgress == nil {
 		return minioClient.FGetObject(ctx, m.config.Bucket, key, file.Name(), getObjectOpts)
 	}
 
@@ -85,20 +80,63 @@ func (m *MinioManager) Download(ctx context.Context, output io.WriterAt, key str
 	}
 	defer func() { _ = obj.Close() }()
 
-	writer := &writerAtAdapter{w: output}
+	writer := &writerAtAdapter{w: m.progressWriterAt("download", key, output)}
 	_, err = io.Copy(writer, obj)
 	return err
 }
 
+func (m *MinioManager) OpenReader(ctx context.Context, key string, opts ...DownloadOption) (io.ReadCloser, error) {
+	minioClient, err := m.getClient()
+	if err != nil {
+		return nil, err
+	}
+	getObjectOpts, err := minioGetObjectOptions(applyDownloadOptions(opts...))
+	if err != nil {
+		return nil, err
+	}
+
+	obj, err := minioClient.GetObject(ctx, m.config.Bucket, key, getObjectOpts)
+	if err != nil {
+		return nil, err
+	}
+	// GetObject is lazy: stat the object for failing early, e.g. if it doesn't exist
+	if _, err := obj.Stat(); err != nil {
+		_ = obj.Close()
+		if minio.ToErrorResponse(err).Code == "NoSuchKey" {
+			return nil, ErrKeyNotFound
+		}
+		return nil, err
+	}
+	return obj, nil
+}
+
+func minioGetObjectOptions(downloadOpts downloadOptions) (minio.GetObjectOptions, error) {
+	getObjectOpts := minio.GetObjectOptions{}
+	if downloadOpts.isRangeRequest {
+		end := int64(0)
+		if downloadOpts.length > 0 {
+			end = downloadOpts.offset + downloadOpts.length - 1
+		}
+		if err := getObjectOpts.SetRange(downloadOpts.offset, end); err != nil {
+			return getObjectOpts, fmt.Errorf("setting range for minio download: %w", err)
+		}
+	}
+	return getObjectOpts, nil
+}
+
 func (m *MinioManager) Upload(ctx context.Context, file *os.File, prefixes ...string) (UploadedFile, error) {
 	objName := path.Join(m.config.Prefix, path.Join(prefixes...), path.Base(file.Name()))
 	return m.UploadReader(ctx, objName, file)
 }
 
-func (m *MinioManager) UploadReader(ctx context.Context, objName string, rdr io.Reader) (UploadedFile, error) {
+func (m *MinioManager) UploadReader(ctx context.Context, objName string, rdr io.Reader, opts ...UploadOption) (UploadedFile, error) {
 	if m.config.Bucket == "" {
 		return UploadedFile{}, errors.New("no storage bucket configured to uploader")
 	}
+	uploadOpts, err := applyUploadOptions(opts...)
+	if err != nil {
+		return UploadedFile{}, err
+	}
 
 	minioClient, err := m.getClient()
 	if err != nil {
@@ -118,19 +156,154 @@ func (m *MinioManager) UploadReader(ctx context.Context, objName string, rdr io.
 		}
 	}
 
+	putObjectOpts := minio.PutObjectOptions{
+		ContentType:  uploadOpts.contentType,
+		UserMetadata: uploadOpts.metadata,
+		Progress:     m.progressHook("upload", objName),
+	}
+	if m.transfer.PartSize > 0 {
+		partSize, numThreads := m.transfer.uploadParts(0, 4)
+		putObjectOpts.PartSize = uint64(partSize)
+		putObjectOpts.NumThreads = uint(numThreads)
+	} else if m.transfer.UploadConcurrency > 0 {
+		putObjectOpts.NumThreads = uint(m.transfer.UploadConcurrency)
+	}
+	if uploadOpts.ifNoneMatch != "" {
+		putObjectOpts.SetMatchETagExcept(uploadOpts.ifNoneMatch)
+	}
+	if uploadOpts.ifMatch != "" {
+		putObjectOpts.SetMatchETag(uploadOpts.ifMatch)
+	}
 	// Check if output is *os.File to use FGetObject
 	if file, ok := rdr.(*os.File); ok {
-		_, err = minioClient.FPutObject(ctx, m.config.Bucket, objName, file.Name(), minio.PutObjectOptions{})
+		_, err = minioClient.FPutObject(ctx, m.config.Bucket, objName, file.Name(), putObjectOpts)
 	} else {
-		_, err = minioClient.PutObject(ctx, m.config.Bucket, objName, rdr, -1, minio.PutObjectOptions{})
+		// buffering parts and uploading them in parallel, as minio would upload them sequentially otherwise
+		putObjectOpts.ConcurrentStreamParts = putObjectOpts.NumThreads > 1 && putObjectOpts.PartSize > 0
+		_, err = minioClient.PutObject(ctx, m.config.Bucket, objName, rdr, -1, putObjectOpts)
 	}
 	if err != nil {
+		switch minio.ToErrorResponse(err).Code {
+		case "PreconditionFailed":
+			return UploadedFile{}, ErrPreConditionFailed
+		case "NoSuchKey":
+			if uploadOpts.ifMatch != "" {
+				return UploadedFile{}, ErrPreConditionFailed
+			}
+		}
 		return UploadedFile{}, err
 	}
 
 	return UploadedFile{Location: m.objectUrl(objName), ObjectName: objName}, nil
 }
 
+func (m *MinioManager) Stat(ctx context.Context, key string) (*FileInfo, error) {
+	minioClient, err := m.getClient()
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, m.getTimeout())
+	defer cancel()
+
+	info, err := minioClient.StatObject(ctx, m.config.Bucket, key, minio.StatObjectOptions{})
+	if err != nil {
+		if minio.ToErrorResponse(err).Code == "NoSuchKey" {
+			return nil, ErrKeyNotFound
+		}
+		return nil, err
+	}
+	return minioFileInfo(&info), nil
+}
+
+func (m *MinioManager) Copy(ctx context.Context, srcKey, dstKey string) error {
+	minioClient, err := m.getClient()
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, m.getTimeout())
+	defer cancel()
+
+	_, err = minioClient.CopyObject(ctx,
+		minio.CopyDestOptions{Bucket: m.config.Bucket, Object: dstKey},
+		minio.CopySrcOptions{Bucket: m.config.Bucket, Object: srcKey},
+	)
+	if err != nil {
+		if minio.ToErrorResponse(err).Code == "NoSuchKey" {
+			return ErrKeyNotFound
+		}
+		return fmt.Errorf("copying object: %w", err)
+	}
+	return nil
+}
+
+func (m *MinioManager) Move(ctx context.Context, srcKey, dstKey string) error {
+	return moveByCopy(ctx, m, srcKey, dstKey)
+}
+
+// Compose concatenates objects server-side, falling back to streaming them if any source but the last one is smaller
+// than the minimum part size of 5MiB.
+func (m *MinioManager) Compose(ctx context.Context, dstKey string, srcKeys ...string) error {
+	if err := validateCompose(dstKey, srcKeys); err != nil {
+		return err
+	}
+	srcs := make([]minio.CopySrcOptions, len(srcKeys))
+	for i, key := range srcKeys {
+		info, err := m.Stat(ctx, key)
+		if err != nil {
+			return fmt.Errorf("stat %q: %w", key, err)
+		}
+		if i < len(srcKeys)-1 && info.Size < s3MinPartSize {
+			return streamCompose(ctx, m, dstKey, srcKeys)
+		}
+		srcs[i] = minio.CopySrcOptions{Bucket: m.config.Bucket, Object: key}
+	}
+
+	minioClient, err := m.getClient()
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, m.getTimeout())
+	defer cancel()
+
+	if _, err := minioClient.ComposeObject(ctx, minio.CopyDestOptions{Bucket: m.config.Bucket, Object: dstKey}, srcs...); err != nil {
+		return fmt.Errorf("composing objects: %w", err)
+	}
+	return nil
+}
+
+func (m *MinioManager) PresignGet(ctx context.Context, key string, ttl time.Duration) (string, error) {
+	if err := validatePresign(http.MethodGet, ttl); err != nil {
+		return "", err
+	}
+	minioClient, err := m.getClient()
+	if err != nil {
+		return "", err
+	}
+	u, err := minioClient.PresignedGetObject(ctx, m.config.Bucket, key, ttl, url.Values{})
+	if err != nil {
+		return "", fmt.Errorf("presigning GET request: %w", err)
+	}
+	return u.String(), nil
+}
+
+func (m *MinioManager) PresignPut(ctx context.Context, key string, ttl time.Duration) (string, error) {
+	if err := validatePresign(http.MethodPut, ttl); err != nil {
+		return "", err
+	}
+	minioClient, err := m.getClient()
+	if err != nil {
+		return "", err
+	}
+	u, err := minioClient.PresignedPutObject(ctx, m.config.Bucket, key, ttl)
+	if err != nil {
+		return "", fmt.Errorf("presigning PUT request: %w", err)
+	}
+	return u.String(), nil
+}
+
 func (m *MinioManager) Delete(ctx context.Context, keys []string) (err error) {
 	objectChannel := make(chan minio.ObjectInfo, len(keys))
 	for _, key := range keys {
@@ -294,7 +467,7 @@ func (l *minioListSession) Next() ([]*FileInfo, error) {
 	}
 
 	for idx := range result.Contents {
-		fileObjects = append(fileObjects, &FileInfo{result.Contents[idx].Key, result.Contents[idx].LastModified})
+		fileObjects = append(fileObjects, minioFileInfo(&result.Contents[idx]))
 	}
 
 	l.isTruncated = result.IsTruncated
@@ -302,3 +475,15 @@ func (l *minioListSession) Next() ([]*FileInfo, error) {
 
 	return fileObjects, nil
 }
+
+func minioFileInfo(info *minio.ObjectInfo) *FileInfo {
+	return &FileInfo{
+		Key:          info.Key,
+		LastModified: info.LastModified,
+		Size:         info.Size,
+		ETag:         trimETag(info.ETag),
+		ContentType:  info.ContentType,
+		StorageClass: info.StorageClass,
+		Metadata:     normalizeMetadata(info.UserMetadata),
+	}
+}
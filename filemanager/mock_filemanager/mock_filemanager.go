@@ -44,6 +44,39 @@ func (m *MockFileManager) EXPECT() *MockFileManagerMockRecorder {
 	return m.recorder
 }
 
+// Compose mocks base method.
+func (m *MockFileManager) Compose(ctx context.Context, dstKey string, srcKeys ...string) error {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, dstKey}
+	for _, a := range srcKeys {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Compose", varargs...)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Compose indicates an expected call of Compose.
+func (mr *MockFileManagerMockRecorder) Compose(ctx, dstKey any, srcKeys ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, dstKey}, srcKeys...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Compose", reflect.TypeOf((*MockFileManager)(nil).Compose), varargs...)
+}
+
+// Copy mocks base method.
+func (m *MockFileManager) Copy(ctx context.Context, srcKey, dstKey string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Copy", ctx, srcKey, dstKey)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Copy indicates an expected call of Copy.
+func (mr *MockFileManagerMockRecorder) Copy(ctx, srcKey, dstKey any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Copy", reflect.TypeOf((*MockFileManager)(nil).Copy), ctx, srcKey, dstKey)
+}
+
 // Delete mocks base method.
 func (m *MockFileManager) Delete(ctx context.Context, keys []string) error {
 	m.ctrl.T.Helper()
@@ -120,6 +153,40 @@ func (mr *MockFileManagerMockRecorder) ListFilesWithPrefix(ctx, startAfter, pref
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListFilesWithPrefix", reflect.TypeOf((*MockFileManager)(nil).ListFilesWithPrefix), ctx, startAfter, prefix, maxItems)
 }
 
+// Move mocks base method.
+func (m *MockFileManager) Move(ctx context.Context, srcKey, dstKey string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Move", ctx, srcKey, dstKey)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Move indicates an expected call of Move.
+func (mr *MockFileManagerMockRecorder) Move(ctx, srcKey, dstKey any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Move", reflect.TypeOf((*MockFileManager)(nil).Move), ctx, srcKey, dstKey)
+}
+
+// OpenReader mocks base method.
+func (m *MockFileManager) OpenReader(ctx context.Context, key string, opts ...filemanager.DownloadOption) (io.ReadCloser, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, key}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "OpenReader", varargs...)
+	ret0, _ := ret[0].(io.ReadCloser)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// OpenReader indicates an expected call of OpenReader.
+func (mr *MockFileManagerMockRecorder) OpenReader(ctx, key any, opts ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, key}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OpenReader", reflect.TypeOf((*MockFileManager)(nil).OpenReader), varargs...)
+}
+
 // Prefix mocks base method.
 func (m *MockFileManager) Prefix() string {
 	m.ctrl.T.Helper()
@@ -134,6 +201,36 @@ func (mr *MockFileManagerMockRecorder) Prefix() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Prefix", reflect.TypeOf((*MockFileManager)(nil).Prefix))
 }
 
+// PresignGet mocks base method.
+func (m *MockFileManager) PresignGet(ctx context.Context, key string, ttl time.Duration) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PresignGet", ctx, key, ttl)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PresignGet indicates an expected call of PresignGet.
+func (mr *MockFileManagerMockRecorder) PresignGet(ctx, key, ttl any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PresignGet", reflect.TypeOf((*MockFileManager)(nil).PresignGet), ctx, key, ttl)
+}
+
+// PresignPut mocks base method.
+func (m *MockFileManager) PresignPut(ctx context.Context, key string, ttl time.Duration) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PresignPut", ctx, key, ttl)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PresignPut indicates an expected call of PresignPut.
+func (mr *MockFileManagerMockRecorder) PresignPut(ctx, key, ttl any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PresignPut", reflect.TypeOf((*MockFileManager)(nil).PresignPut), ctx, key, ttl)
+}
+
 // SetTimeout mocks base method.
 func (m *MockFileManager) SetTimeout(timeout time.Duration) {
 	m.ctrl.T.Helper()
@@ -146,6 +243,21 @@ func (mr *MockFileManagerMockRecorder) SetTimeout(timeout any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetTimeout", reflect.TypeOf((*MockFileManager)(nil).SetTimeout), timeout)
 }
 
+// Stat mocks base method.
+func (m *MockFileManager) Stat(ctx context.Context, key string) (*filemanager.FileInfo, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Stat", ctx, key)
+	ret0, _ := ret[0].(*filemanager.FileInfo)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Stat indicates an expected call of Stat.
+func (mr *MockFileManagerMockRecorder) Stat(ctx, key any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Stat", reflect.TypeOf((*MockFileManager)(nil).Stat), ctx, key)
+}
+
 // Upload mocks base method.
 func (m *MockFileManager) Upload(arg0 context.Context, arg1 *os.File, arg2 ...string) (filemanager.UploadedFile, error) {
 	m.ctrl.T.Helper()
@@ -167,16 +279,21 @@ func (mr *MockFileManagerMockRecorder) Upload(arg0, arg1 any, arg2 ...any) *gomo
 }
 
 // UploadReader mocks base method.
-func (m *MockFileManager) UploadReader(ctx context.Context, objName string, rdr io.Reader) (filemanager.UploadedFile, error) {
+func (m *MockFileManager) UploadReader(ctx context.Context, objName string, rdr io.Reader, opts ...filemanager.UploadOption) (filemanager.UploadedFile, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UploadReader", ctx, objName, rdr)
+	varargs := []any{ctx, objName, rdr}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "UploadReader", varargs...)
 	ret0, _ := ret[0].(filemanager.UploadedFile)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // UploadReader indicates an expected call of UploadReader.
-func (mr *MockFileManagerMockRecorder) UploadReader(ctx, objName, rdr any) *gomock.Call {
+func (mr *MockFileManagerMockRecorder) UploadReader(ctx, objName, rdr any, opts ...any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UploadReader", reflect.TypeOf((*MockFileManager)(nil).UploadReader), ctx, objName, rdr)
+	varargs := append([]any{ctx, objName, rdr}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UploadReader", reflect.TypeOf((*MockFileManager)(nil).UploadReader), varargs...)
 }
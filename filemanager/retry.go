@@ -0,0 +1,223 @@
+package filemanager
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"time"
+
+	"github.com/cenkalti/backoff/v5"
+
+	"github.com/rudderlabs/rudder-go-kit/config"
+	"github.com/rudderlabs/rudder-go-kit/retryablehttp"
+)
+
+// RetryOption configures the file manager returned by WithRetry
+type RetryOption func(*retryingManager)
+
+// WithRetryNotify sets a function called whenever an operation fails with a transient error and is going to be
+// retried after the given wait
+func WithRetryNotify(notify func(operation string, err error, wait time.Duration)) RetryOption {
+	return func(m *retryingManager) {
+		m.notify = notify
+	}
+}
+
+// NewDefaultRetryConfig creates a new retry configuration for file managers with default settings.
+//
+//	MaxRetry: Maximum number of retries (default: 3)
+//	InitialInterval: Initial retry interval in milliseconds (default: 200ms)
+//	MaxInterval: Maximum retry interval in milliseconds (default: 5000ms)
+//	MaxElapsedTime: Maximum total elapsed time for retries in seconds (default: 60s)
+//	Multiplier: Backoff multiplier for retry intervals (default: 2)
+func NewDefaultRetryConfig() *retryablehttp.Config {
+	return &retryablehttp.Config{
+		MaxRetry:        config.GetIntVar(3, 1, "FileManager.retry.maxRetry"),
+		InitialInterval: config.GetDurationVar(200, time.Millisecond, "FileManager.retry.initialInterval"),
+		MaxInterval:     config.GetDurationVar(5000, time.Millisecond, "FileManager.retry.maxInterval"),
+		MaxElapsedTime:  config.GetDurationVar(60, time.Second, "FileManager.retry.maxElapsedTime"),
+		Multiplier:      config.GetFloat64Var(2, "FileManager.retry.multiplier"),
+	}
+}
+
+// WithRetry wraps a file manager so that operations failing with transient errors (see IsTransientError) are retried
+// with an exponential backoff, using the same configuration as retryable HTTP clients. If the configuration is nil,
+// NewDefaultRetryConfig is used.
+//
+// Uploads are retried only if the reader can be rewound, i.e. if it implements io.Seeker.
+// Since a failed attempt may have been applied by the provider anyway, retrying conditional uploads may fail with
+// ErrPreConditionFailed, and retrying moves may fail with ErrKeyNotFound.
+func WithRetry(fm FileManager, conf *retryablehttp.Config, opts ...RetryOption) FileManager {
+	if conf == nil {
+		conf = NewDefaultRetryConfig()
+	}
+	m := &retryingManager{FileManager: fm, config: conf}
+	for _, opt := range opts {
+		opt(m)
+	}
+	return m
+}
+
+type retryingManager struct {
+	FileManager
+	config *retryablehttp.Config
+	notify func(operation string, err error, wait time.Duration)
+}
+
+func (m *retryingManager) ListFilesWithPrefix(ctx context.Context, startAfter, prefix string, maxItems int64) ListSession {
+	return &retryingListSession{
+		ListSession: m.FileManager.ListFilesWithPrefix(ctx, startAfter, prefix, maxItems),
+		ctx:         ctx,
+		manager:     m,
+	}
+}
+
+func (m *retryingManager) Download(ctx context.Context, w io.WriterAt, key string, opts ...DownloadOption) error {
+	_, err := retry(ctx, m, "download", func() (struct{}, error) {
+		return struct{}{}, m.FileManager.Download(ctx, w, key, opts...)
+	})
+	return err
+}
+
+func (m *retryingManager) OpenReader(ctx context.Context, key string, opts ...DownloadOption) (io.ReadCloser, error) {
+	return retry(ctx, m, "open_reader", func() (io.ReadCloser, error) {
+		return m.FileManager.OpenReader(ctx, key, opts...)
+	})
+}
+
+func (m *retryingManager) Upload(ctx context.Context, f *os.File, prefixes ...string) (UploadedFile, error) {
+	rewind, err := rewinder(f)
+	if err != nil {
+		return m.FileManager.Upload(ctx, f, prefixes...)
+	}
+	return retry(ctx, m, "upload", func() (UploadedFile, error) {
+		if err := rewind(); err != nil {
+			return UploadedFile{}, backoff.Permanent(err)
+		}
+		return m.FileManager.Upload(ctx, f, prefixes...)
+	})
+}
+
+func (m *retryingManager) UploadReader(ctx context.Context, objName string, rdr io.Reader, opts ...UploadOption) (UploadedFile, error) {
+	rewind, err := rewinder(rdr)
+	if err != nil {
+		return m.FileManager.UploadReader(ctx, objName, rdr, opts...)
+	}
+	return retry(ctx, m, "upload_reader", func() (UploadedFile, error) {
+		if err := rewind(); err != nil {
+			return UploadedFile{}, backoff.Permanent(err)
+		}
+		return m.FileManager.UploadReader(ctx, objName, rdr, opts...)
+	})
+}
+
+func (m *retryingManager) Delete(ctx context.Context, keys []string) error {
+	_, err := retry(ctx, m, "delete", func() (struct{}, error) {
+		return struct{}{}, m.FileManager.Delete(ctx, keys)
+	})
+	return err
+}
+
+func (m *retryingManager) Stat(ctx context.Context, key string) (*FileInfo, error) {
+	return retry(ctx, m, "stat", func() (*FileInfo, error) {
+		return m.FileManager.Stat(ctx, key)
+	})
+}
+
+func (m *retryingManager) Copy(ctx context.Context, srcKey, dstKey string) error {
+	_, err := retry(ctx, m, "copy", func() (struct{}, error) {
+		return struct{}{}, m.FileManager.Copy(ctx, srcKey, dstKey)
+	})
+	return err
+}
+
+func (m *retryingManager) Move(ctx context.Context, srcKey, dstKey string) error {
+	_, err := retry(ctx, m, "move", func() (struct{}, error) {
+		return struct{}{}, m.FileManager.Move(ctx, srcKey, dstKey)
+	})
+	return err
+}
+
+func (m *retryingManager) Compose(ctx context.Context, dstKey string, srcKeys ...string) error {
+	_, err := retry(ctx, m, "compose", func() (struct{}, error) {
+		return struct{}{}, m.FileManager.Compose(ctx, dstKey, srcKeys...)
+	})
+	return err
+}
+
+func (m *retryingManager) PresignGet(ctx context.Context, key string, ttl time.Duration) (string, error) {
+	return retry(ctx, m, "presign_get", func() (string, error) {
+		return m.FileManager.PresignGet(ctx, key, ttl)
+	})
+}
+
+func (m *retryingManager) PresignPut(ctx context.Context, key string, ttl time.Duration) (string, error) {
+	return retry(ctx, m, "presign_put", func() (string, error) {
+		return m.FileManager.PresignPut(ctx, key, ttl)
+	})
+}
+
+type retryingListSession struct {
+	ListSession
+	ctx     context.Context
+	manager *retryingManager
+}
+
+func (l *retryingListSession) Next() ([]*FileInfo, error) {
+	return retry(l.ctx, l.manager, "list", l.ListSession.Next)
+}
+
+// retry runs the operation until it succeeds, fails with an error which is not transient, or the retries are
+// exhausted
+func retry[T any](ctx context.Context, m *retryingManager, operation string, fn func() (T, error)) (T, error) {
+	var maxTries uint
+	if m.config.MaxRetry >= 0 {
+		maxTries = uint(m.config.MaxRetry) + 1
+	}
+	res, err := backoff.Retry(
+		ctx,
+		func() (T, error) {
+			res, err := fn()
+			if err != nil && !IsTransientError(err) {
+				return res, backoff.Permanent(err)
+			}
+			return res, err
+		},
+		backoff.WithBackOff(&backoff.ExponentialBackOff{
+			InitialInterval:     m.config.InitialInterval,
+			RandomizationFactor: backoff.DefaultRandomizationFactor,
+			Multiplier:          m.config.Multiplier,
+			MaxInterval:         m.config.MaxInterval,
+		}),
+		backoff.WithNotify(func(err error, wait time.Duration) {
+			if m.notify != nil {
+				m.notify(operation, err, wait)
+			}
+		}),
+		backoff.WithMaxElapsedTime(m.config.MaxElapsedTime),
+		backoff.WithMaxTries(maxTries),
+	)
+	// backoff returns permanent errors as is when the last try fails with one
+	var permanent *backoff.PermanentError
+	if errors.As(err, &permanent) {
+		err = permanent.Unwrap()
+	}
+	return res, err
+}
+
+// rewinder returns a function seeking the reader back to its current position, or an error if it can't seek
+func rewinder(r io.Reader) (func() error, error) {
+	seeker, ok := r.(io.Seeker)
+	if !ok {
+		return nil, errors.New("reader is not seekable")
+	}
+	offset, err := seeker.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return nil, err
+	}
+	return func() error {
+		_, err := seeker.Seek(offset, io.SeekStart)
+		return err
+	}, nil
+}
@@ -0,0 +1,194 @@
+package filemanager
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+const (
+	// s3MinPartSize is the minimum size of all parts but the last one of a multipart upload
+	s3MinPartSize = 5 * 1024 * 1024
+	// s3MaxCopySize is the maximum size which can be copied by a single CopyObject or UploadPartCopy request
+	s3MaxCopySize = 5 * 1024 * 1024 * 1024
+	// s3MaxParts is the maximum number of parts of a multipart upload
+	s3MaxParts = 10000
+)
+
+// s3Copier performs server-side copies through the S3 API, for both S3 and DigitalOcean Spaces
+type s3Copier struct {
+	client  *s3.Client
+	bucket  string
+	sse     bool
+	timeout time.Duration
+}
+
+// copy copies an object, using a multipart copy for objects too large for a single CopyObject request
+func (c *s3Copier) copy(ctx context.Context, srcKey, dstKey string) error {
+	head, err := c.head(ctx, srcKey)
+	if err != nil {
+		return err
+	}
+	if aws.ToInt64(head.ContentLength) > s3MaxCopySize {
+		return c.multipartCopy(ctx, dstKey, []string{srcKey}, []*s3.HeadObjectOutput{head}, head)
+	}
+
+	input := &s3.CopyObjectInput{
+		ACL:        types.ObjectCannedACLBucketOwnerFullControl,
+		Bucket:     aws.String(c.bucket),
+		Key:        aws.String(dstKey),
+		CopySource: s3CopySource(c.bucket, srcKey),
+	}
+	if c.sse {
+		input.ServerSideEncryption = types.ServerSideEncryptionAes256
+	}
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+	if _, err := c.client.CopyObject(ctx, input); err != nil {
+		return fmt.Errorf("copying object: %w", err)
+	}
+	return nil
+}
+
+// compose concatenates objects through a multipart copy. It returns false without composing anything if the
+// sources don't meet the constraints of multipart uploads, i.e. if any source but the last one is smaller than 5MiB.
+func (c *s3Copier) compose(ctx context.Context, dstKey string, srcKeys []string) (bool, error) {
+	if len(srcKeys) == 1 {
+		return true, c.copy(ctx, srcKeys[0], dstKey)
+	}
+	heads := make([]*s3.HeadObjectOutput, len(srcKeys))
+	var parts int64
+	for i, key := range srcKeys {
+		head, err := c.head(ctx, key)
+		if err != nil {
+			return false, err
+		}
+		size := aws.ToInt64(head.ContentLength)
+		if i < len(srcKeys)-1 && size < s3MinPartSize {
+			return false, nil
+		}
+		heads[i] = head
+		parts += int64(len(s3CopyPartRanges(size)))
+	}
+	if parts > s3MaxParts {
+		return false, nil
+	}
+	return true, c.multipartCopy(ctx, dstKey, srcKeys, heads, nil)
+}
+
+// multipartCopy copies the sources into the destination object through a multipart upload, splitting them into parts
+// of up to 5GiB (see s3CopyPartRanges). The content type and metadata of the destination are copied from metadataFrom, if not nil.
+func (c *s3Copier) multipartCopy(
+	ctx context.Context, dstKey string, srcKeys []string, heads []*s3.HeadObjectOutput, metadataFrom *s3.HeadObjectOutput,
+) error {
+	create := &s3.CreateMultipartUploadInput{
+		ACL:    types.ObjectCannedACLBucketOwnerFullControl,
+		Bucket: aws.String(c.bucket),
+		Key:    aws.String(dstKey),
+	}
+	if metadataFrom != nil {
+		create.ContentType = metadataFrom.ContentType
+		create.Metadata = metadataFrom.Metadata
+	}
+	if c.sse {
+		create.ServerSideEncryption = types.ServerSideEncryptionAes256
+	}
+	createCtx, cancel := context.WithTimeout(ctx, c.timeout)
+	upload, err := c.client.CreateMultipartUpload(createCtx, create)
+	cancel()
+	if err != nil {
+		return fmt.Errorf("creating multipart upload: %w", err)
+	}
+
+	err = func() error {
+		var parts []types.CompletedPart
+		partNumber := int32(1)
+		for i, key := range srcKeys {
+			for _, r := range s3CopyPartRanges(aws.ToInt64(heads[i].ContentLength)) {
+				offset, end := r[0], r[1]
+				partCtx, cancel := context.WithTimeout(ctx, c.timeout)
+				res, err := c.client.UploadPartCopy(partCtx, &s3.UploadPartCopyInput{
+					Bucket:          aws.String(c.bucket),
+					Key:             aws.String(dstKey),
+					UploadId:        upload.UploadId,
+					PartNumber:      aws.Int32(partNumber),
+					CopySource:      s3CopySource(c.bucket, key),
+					CopySourceRange: aws.String(fmt.Sprintf("bytes=%d-%d", offset, end)),
+				})
+				cancel()
+				if err != nil {
+					return fmt.Errorf("copying part %d from %q: %w", partNumber, key, err)
+				}
+				parts = append(parts, types.CompletedPart{ETag: res.CopyPartResult.ETag, PartNumber: aws.Int32(partNumber)})
+				partNumber++
+			}
+		}
+
+		completeCtx, cancel := context.WithTimeout(ctx, c.timeout)
+		defer cancel()
+		_, err := c.client.CompleteMultipartUpload(completeCtx, &s3.CompleteMultipartUploadInput{
+			Bucket:          aws.String(c.bucket),
+			Key:             aws.String(dstKey),
+			UploadId:        upload.UploadId,
+			MultipartUpload: &types.CompletedMultipartUpload{Parts: parts},
+		})
+		if err != nil {
+			return fmt.Errorf("completing multipart upload: %w", err)
+		}
+		return nil
+	}()
+	if err != nil {
+		abortCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), c.timeout)
+		defer cancel()
+		_, _ = c.client.AbortMultipartUpload(abortCtx, &s3.AbortMultipartUploadInput{
+			Bucket:   aws.String(c.bucket),
+			Key:      aws.String(dstKey),
+			UploadId: upload.UploadId,
+		})
+	}
+	return err
+}
+
+// s3CopyPartRanges splits an object of the given size into the inclusive byte ranges of the fewest parts of up to
+// 5GiB. The size is spread evenly over the parts, so that none of them is smaller than 5MiB if the object isn't,
+// as all parts but the last one of a multipart upload must be.
+func s3CopyPartRanges(size int64) [][2]int64 {
+	if size <= 0 {
+		return nil
+	}
+	n := (size + s3MaxCopySize - 1) / s3MaxCopySize
+	partSize := (size + n - 1) / n
+	ranges := make([][2]int64, 0, n)
+	for offset := int64(0); offset < size; offset += partSize {
+		ranges = append(ranges, [2]int64{offset, min(offset+partSize, size) - 1})
+	}
+	return ranges
+}
+
+func (c *s3Copier) head(ctx context.Context, key string) (*s3.HeadObjectOutput, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+	head, err := c.client.HeadObject(ctx, &s3.HeadObjectInput{
+		Bucket: aws.String(c.bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		var nf *types.NotFound
+		if errors.As(err, &nf) {
+			return nil, ErrKeyNotFound
+		}
+		return nil, fmt.Errorf("head object %q: %w", key, err)
+	}
+	return head, nil
+}
+
+// s3CopySource returns the URL encoded copy source of an object
+func s3CopySource(bucket, key string) *string {
+	return aws.String(bucket + "/" + (&url.URL{Path: key}).EscapedPath())
+}
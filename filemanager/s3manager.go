@@ -14,6 +14,7 @@ import (
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
 	s3manager "github.com/aws/aws-sdk-go-v2/feature/s3/manager"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
 	"github.com/aws/aws-sdk-go-v2/service/s3/types"
@@ -108,23 +109,18 @@ func (m *S3Manager) Download(ctx context.Context, output io.WriterAt, key string
 		return fmt.Errorf("s3 client: %w", err)
 	}
 
-	downloader := s3manager.NewDownloader(client)
+	downloader := s3Downloader(client, m.transfer)
 
 	ctx, cancel := context.WithTimeout(ctx, m.getTimeout())
 	defer cancel()
+	output = m.progressWriterAt("download", key, output)
 
 	getObjectInput := &s3.GetObjectInput{
 		Bucket: aws.String(m.config.Bucket),
 		Key:    aws.String(key),
 	}
 	if downloadOpts.isRangeRequest {
-		var rangeOpt string
-		if downloadOpts.length > 0 {
-			rangeOpt = fmt.Sprintf("bytes=%d-%d", downloadOpts.offset, downloadOpts.offset+downloadOpts.length-1)
-		} else {
-			rangeOpt = fmt.Sprintf("bytes=%d-", downloadOpts.offset)
-		}
-		getObjectInput.Range = aws.String(rangeOpt)
+		getObjectInput.Range = aws.String(downloadOpts.httpRange())
 	}
 
 	_, err = downloader.Download(ctx, output, getObjectInput)
@@ -138,6 +134,33 @@ func (m *S3Manager) Download(ctx context.Context, output io.WriterAt, key string
 	return fmt.Errorf("failed to download from S3: %w", err)
 }
 
+// OpenReader opens a streaming reader for the object with the given key.
+func (m *S3Manager) OpenReader(ctx context.Context, key string, opts ...DownloadOption) (io.ReadCloser, error) {
+	key = sanitizeKey(key)
+	downloadOpts := applyDownloadOptions(opts...)
+	client, err := m.getClient(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("s3 client: %w", err)
+	}
+
+	getObjectInput := &s3.GetObjectInput{
+		Bucket: aws.String(m.config.Bucket),
+		Key:    aws.String(key),
+	}
+	if downloadOpts.isRangeRequest {
+		getObjectInput.Range = aws.String(downloadOpts.httpRange())
+	}
+	output, err := client.GetObject(ctx, getObjectInput)
+	if err != nil {
+		var nsk *types.NoSuchKey
+		if errors.As(err, &nsk) {
+			return nil, ErrKeyNotFound
+		}
+		return nil, fmt.Errorf("failed to open S3 object: %w", err)
+	}
+	return output.Body, nil
+}
+
 // Upload uploads a file to S3 and returns the uploaded file info.
 func (m *S3Manager) Upload(ctx context.Context, file *os.File, prefixes ...string) (UploadedFile, error) {
 	fileName := path.Join(m.config.Prefix, path.Join(prefixes...), path.Base(file.Name()))
@@ -146,16 +169,30 @@ func (m *S3Manager) Upload(ctx context.Context, file *os.File, prefixes ...strin
 }
 
 // UploadReader uploads data from an io.Reader to S3 with the given object name.
-func (m *S3Manager) UploadReader(ctx context.Context, objName string, rdr io.Reader) (UploadedFile, error) {
+func (m *S3Manager) UploadReader(ctx context.Context, objName string, rdr io.Reader, opts ...UploadOption) (UploadedFile, error) {
 	if objName == "" {
 		return UploadedFile{}, errors.New("object name cannot be empty")
 	}
 	objName = sanitizeKey(objName)
+	uploadOpts, err := applyUploadOptions(opts...)
+	if err != nil {
+		return UploadedFile{}, err
+	}
 	uploadInput := &s3.PutObjectInput{
-		ACL:    types.ObjectCannedACLBucketOwnerFullControl,
-		Bucket: aws.String(m.config.Bucket),
-		Key:    aws.String(objName),
-		Body:   rdr,
+		ACL:      types.ObjectCannedACLBucketOwnerFullControl,
+		Bucket:   aws.String(m.config.Bucket),
+		Key:      aws.String(objName),
+		Body:     m.progressReader("upload", objName, rdr),
+		Metadata: uploadOpts.metadata,
+	}
+	if uploadOpts.contentType != "" {
+		uploadInput.ContentType = aws.String(uploadOpts.contentType)
+	}
+	if uploadOpts.ifNoneMatch != "" {
+		uploadInput.IfNoneMatch = aws.String(uploadOpts.ifNoneMatch)
+	}
+	if uploadOpts.ifMatch != "" {
+		uploadInput.IfMatch = aws.String(quoteETag(uploadOpts.ifMatch))
 	}
 
 	if m.config.EnableSSE {
@@ -166,7 +203,7 @@ func (m *S3Manager) UploadReader(ctx context.Context, objName string, rdr io.Rea
 	if err != nil {
 		return UploadedFile{}, fmt.Errorf("s3 client: %w", err)
 	}
-	uploader := s3manager.NewUploader(client)
+	uploader := s3Uploader(client, m.transfer)
 
 	ctx, cancel := context.WithTimeout(ctx, m.getTimeout())
 	defer cancel()
@@ -175,6 +212,9 @@ func (m *S3Manager) UploadReader(ctx context.Context, objName string, rdr io.Rea
 	if err == nil {
 		return UploadedFile{Location: output.Location, ObjectName: objName}, nil
 	}
+	if isS3PreconditionFailed(err, uploadOpts) {
+		return UploadedFile{}, ErrPreConditionFailed
+	}
 	var regionError *aws.MissingRegionError
 	if errors.As(err, &regionError) {
 		err = fmt.Errorf(`missing region for bucket %q: %w`, m.config.Bucket, regionError)
@@ -182,6 +222,112 @@ func (m *S3Manager) UploadReader(ctx context.Context, objName string, rdr io.Rea
 	return UploadedFile{}, err
 }
 
+// Stat returns information about the S3 object with the given key.
+func (m *S3Manager) Stat(ctx context.Context, key string) (*FileInfo, error) {
+	key = sanitizeKey(key)
+	client, err := m.getClient(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("s3 client: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, m.getTimeout())
+	defer cancel()
+
+	output, err := client.HeadObject(ctx, &s3.HeadObjectInput{
+		Bucket: aws.String(m.config.Bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		var nf *types.NotFound
+		if errors.As(err, &nf) {
+			return nil, ErrKeyNotFound
+		}
+		return nil, fmt.Errorf("failed to stat S3 object: %w", err)
+	}
+	return s3HeadObjectFileInfo(key, output), nil
+}
+
+// Copy copies an S3 object server-side.
+func (m *S3Manager) Copy(ctx context.Context, srcKey, dstKey string) error {
+	copier, err := m.copier(ctx)
+	if err != nil {
+		return err
+	}
+	return copier.copy(ctx, sanitizeKey(srcKey), sanitizeKey(dstKey))
+}
+
+// Move moves an S3 object by copying it server-side and deleting the source.
+func (m *S3Manager) Move(ctx context.Context, srcKey, dstKey string) error {
+	// sanitizing the keys, since different keys can refer to the same object, e.g. "/a" and "a"
+	return moveByCopy(ctx, m, sanitizeKey(srcKey), sanitizeKey(dstKey))
+}
+
+// Compose concatenates S3 objects server-side through a multipart copy, falling back to streaming them if any
+// source but the last one is smaller than the minimum part size.
+func (m *S3Manager) Compose(ctx context.Context, dstKey string, srcKeys ...string) error {
+	dstKey = sanitizeKey(dstKey)
+	srcKeys = lo.Map(srcKeys, func(key string, _ int) string { return sanitizeKey(key) })
+	if err := validateCompose(dstKey, srcKeys); err != nil {
+		return err
+	}
+	copier, err := m.copier(ctx)
+	if err != nil {
+		return err
+	}
+	if ok, err := copier.compose(ctx, dstKey, srcKeys); ok || err != nil {
+		return err
+	}
+	return streamCompose(ctx, m, dstKey, srcKeys)
+}
+
+func (m *S3Manager) copier(ctx context.Context) (*s3Copier, error) {
+	client, err := m.getClient(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("s3 client: %w", err)
+	}
+	return &s3Copier{client: client, bucket: m.config.Bucket, sse: m.config.EnableSSE, timeout: m.getTimeout()}, nil
+}
+
+// PresignGet returns a presigned URL for downloading an S3 object.
+func (m *S3Manager) PresignGet(ctx context.Context, key string, ttl time.Duration) (string, error) {
+	return m.presign(ctx, http.MethodGet, sanitizeKey(key), ttl)
+}
+
+// PresignPut returns a presigned URL for uploading an S3 object.
+func (m *S3Manager) PresignPut(ctx context.Context, key string, ttl time.Duration) (string, error) {
+	return m.presign(ctx, http.MethodPut, sanitizeKey(key), ttl)
+}
+
+func (m *S3Manager) presign(ctx context.Context, method, key string, ttl time.Duration) (string, error) {
+	if err := validatePresign(method, ttl); err != nil {
+		return "", err
+	}
+	client, err := m.getClient(ctx)
+	if err != nil {
+		return "", fmt.Errorf("s3 client: %w", err)
+	}
+	return s3Presign(ctx, client, method, m.config.Bucket, key, ttl)
+}
+
+// s3Presign presigns a GET or PUT request for an object through the S3 API
+func s3Presign(ctx context.Context, client *s3.Client, method, bucket, key string, ttl time.Duration) (string, error) {
+	presignClient := s3.NewPresignClient(client, s3.WithPresignExpires(ttl))
+	var (
+		req *v4.PresignedHTTPRequest
+		err error
+	)
+	switch method {
+	case http.MethodGet:
+		req, err = presignClient.PresignGetObject(ctx, &s3.GetObjectInput{Bucket: aws.String(bucket), Key: aws.String(key)})
+	case http.MethodPut:
+		req, err = presignClient.PresignPutObject(ctx, &s3.PutObjectInput{Bucket: aws.String(bucket), Key: aws.String(key)})
+	}
+	if err != nil {
+		return "", fmt.Errorf("presigning %s request: %w", method, err)
+	}
+	return req.URL, nil
+}
+
 // Delete removes the specified keys from S3.
 func (m *S3Manager) Delete(ctx context.Context, keys []string) error {
 	client, err := m.getClient(ctx)
@@ -370,61 +516,66 @@ func (l *s3ListSession) Next() (fileObjects []*FileInfo, err error) {
 	l.isTruncated = *resp.IsTruncated
 	l.continuationToken = resp.NextContinuationToken
 	for _, item := range resp.Contents {
-		fileObjects = append(fileObjects, &FileInfo{*item.Key, *item.LastModified})
+		fileObjects = append(fileObjects, s3ObjectFileInfo(item))
 	}
 	return fileObjects, nil
 }
 
+// s3ObjectFileInfo converts an object returned by ListObjectsV2 into a FileInfo
+func s3ObjectFileInfo(item types.Object) *FileInfo {
+	return &FileInfo{
+		Key:          aws.ToString(item.Key),
+		LastModified: aws.ToTime(item.LastModified),
+		Size:         aws.ToInt64(item.Size),
+		ETag:         trimETag(aws.ToString(item.ETag)),
+		StorageClass: string(item.StorageClass),
+	}
+}
+
+// s3HeadObjectFileInfo converts the output of HeadObject into a FileInfo
+func s3HeadObjectFileInfo(key string, output *s3.HeadObjectOutput) *FileInfo {
+	return &FileInfo{
+		Key:          key,
+		LastModified: aws.ToTime(output.LastModified),
+		Size:         aws.ToInt64(output.ContentLength),
+		ETag:         trimETag(aws.ToString(output.ETag)),
+		ContentType:  aws.ToString(output.ContentType),
+		StorageClass: string(output.StorageClass),
+		Metadata:     normalizeMetadata(output.Metadata),
+	}
+}
+
 func (m *S3Manager) SelectObjects(ctx context.Context, selectConfig SelectConfig) (<-chan SelectResult, func()) {
 	s := async.SingleSender[SelectResult]{}
 	ctx, selectResultChan, leave := s.Begin(ctx)
 
 	go func() {
 		defer s.Close()
-		client, err := m.getClient(ctx)
-		if err != nil {
-			s.Send(SelectResult{Error: fmt.Errorf("selecting objects: %w", err)})
-			return
-		}
-
-		inputSerialization, outputSerialization, err := createS3SelectSerializationV2(selectConfig.InputFormat, selectConfig.OutputFormat)
-		if err != nil {
-			s.Send(SelectResult{Error: fmt.Errorf("error extracting input/output serialization: %w", err)})
-			return
-		}
-
-		selectObject, err := client.SelectObjectContent(ctx, &s3.SelectObjectContentInput{
-			Bucket:              aws.String(m.config.Bucket),
-			Key:                 aws.String(selectConfig.Key),
-			Expression:          aws.String(selectConfig.SQLExpression),
-			ExpressionType:      types.ExpressionTypeSql,
-			InputSerialization:  inputSerialization,
-			OutputSerialization: outputSerialization,
-		})
+		it, err := m.SelectRecords(ctx, selectConfig)
 		if err != nil {
-			s.Send(SelectResult{Error: fmt.Errorf("selecting object: %w", err)})
+			s.Send(SelectResult{Error: err})
 			return
 		}
-
-		stream := selectObject.GetStream()
-		defer func() {
-			if err := stream.Err(); err != nil && ctx.Err() == nil {
-				s.Send(SelectResult{Error: err})
-			}
-			stream.Close()
-		}()
+		defer func() { _ = it.Close() }()
 		for {
 			select {
 			case <-ctx.Done():
 				s.Send(SelectResult{Error: ctx.Err()})
 				return
-			case event, ok := <-stream.Events():
+			case event, ok := <-it.events:
 				if !ok {
+					if err := it.streamErr(); err != nil && ctx.Err() == nil {
+						s.Send(SelectResult{Error: err})
+					}
 					return
 				}
 				switch e := event.(type) {
 				case *types.SelectObjectContentEventStreamMemberRecords:
 					s.Send(SelectResult{Data: e.Value.Payload})
+				case *types.SelectObjectContentEventStreamMemberProgress:
+					it.progress(e.Value.Details.BytesScanned, e.Value.Details.BytesProcessed, e.Value.Details.BytesReturned)
+				case *types.SelectObjectContentEventStreamMemberStats:
+					it.progress(e.Value.Details.BytesScanned, e.Value.Details.BytesProcessed, e.Value.Details.BytesReturned)
 				case *types.SelectObjectContentEventStreamMemberEnd:
 					return
 				}
@@ -434,35 +585,74 @@ func (m *S3Manager) SelectObjects(ctx context.Context, selectConfig SelectConfig
 	return selectResultChan, leave
 }
 
-func createS3SelectSerializationV2(inputFormat SelectObjectInputFormat, outputFormat SelectObjectOutputFormat) (*types.InputSerialization, *types.OutputSerialization, error) {
-	var inputSerialization *types.InputSerialization
-	switch inputFormat {
-	case SelectObjectInputFormatParquet:
-		inputSerialization = &types.InputSerialization{
-			Parquet: &types.ParquetInput{},
-		}
-	default:
-		return nil, nil, fmt.Errorf("invalid input format: %s", inputFormat)
+// SelectRecords runs an S3 Select query, returning an iterator streaming the resulting records one at a time.
+// The iterator must be closed once done with it.
+func (m *S3Manager) SelectRecords(ctx context.Context, selectConfig SelectConfig) (*SelectRecordIterator, error) {
+	client, err := m.getClient(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("selecting objects: %w", err)
 	}
 
-	var outputSerialization *types.OutputSerialization
-	switch outputFormat {
-	case SelectObjectOutputFormatCSV:
-		outputSerialization = &types.OutputSerialization{
-			CSV: &types.CSVOutput{
-				RecordDelimiter: aws.String("\n"),
-				FieldDelimiter:  aws.String(","),
-			},
-		}
-	case SelectObjectOutputFormatJSON:
-		outputSerialization = &types.OutputSerialization{
-			JSON: &types.JSONOutput{},
-		}
-	default:
-		return nil, nil, fmt.Errorf("invalid output format: %s", outputFormat)
+	inputSerialization, outputSerialization, err := createS3SelectSerializationV2(selectConfig)
+	if err != nil {
+		return nil, fmt.Errorf("error extracting input/output serialization: %w", err)
 	}
 
-	return inputSerialization, outputSerialization, nil
+	input := &s3.SelectObjectContentInput{
+		Bucket:              aws.String(m.config.Bucket),
+		Key:                 aws.String(selectConfig.Key),
+		Expression:          aws.String(selectConfig.SQLExpression),
+		ExpressionType:      types.ExpressionTypeSql,
+		InputSerialization:  inputSerialization,
+		OutputSerialization: outputSerialization,
+	}
+	if selectConfig.OnProgress != nil {
+		input.RequestProgress = &types.RequestProgress{Enabled: aws.Bool(true)}
+	}
+	selectObject, err := client.SelectObjectContent(ctx, input)
+	if err != nil {
+		return nil, fmt.Errorf("selecting object: %w", err)
+	}
+
+	stream := selectObject.GetStream()
+	return &SelectRecordIterator{
+		ctx:        ctx,
+		events:     stream.Events(),
+		streamErr:  stream.Err,
+		close:      stream.Close,
+		onProgress: selectConfig.OnProgress,
+		csvOutput:  selectConfig.OutputFormat == SelectObjectOutputFormatCSV,
+	}, nil
+}
+
+// isS3PreconditionFailed returns true if a conditional write failed, either because its condition wasn't met or
+// because of a concurrent conditional write. If-match conditions on missing keys fail with NoSuchKey instead.
+func isS3PreconditionFailed(err error, uploadOpts uploadOptions) bool {
+	var apiErr smithy.APIError
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+	switch apiErr.ErrorCode() {
+	case "PreconditionFailed", "ConditionalRequestConflict":
+		return true
+	case "NoSuchKey":
+		return uploadOpts.ifMatch != ""
+	}
+	return false
+}
+
+// s3Uploader returns an upload manager tuned with the transfer settings
+func s3Uploader(client *s3.Client, transfer TransferSettings) *s3manager.Uploader {
+	return s3manager.NewUploader(client, func(u *s3manager.Uploader) {
+		u.PartSize, u.Concurrency = transfer.uploadParts(s3manager.DefaultUploadPartSize, s3manager.DefaultUploadConcurrency)
+	})
+}
+
+// s3Downloader returns a download manager tuned with the transfer settings
+func s3Downloader(client *s3.Client, transfer TransferSettings) *s3manager.Downloader {
+	return s3manager.NewDownloader(client, func(d *s3manager.Downloader) {
+		d.PartSize, d.Concurrency = transfer.downloadParts(s3manager.DefaultDownloadPartSize, s3manager.DefaultDownloadConcurrency)
+	})
 }
 
 func sanitizeKey(key string) string {
@@ -1,17 +1,46 @@
 package filemanager
 
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
 type (
 	SelectObjectInputFormat  string
 	SelectObjectOutputFormat string
+	SelectCompressionType    string
+	SelectCSVFileHeaderInfo  string
 )
 
 const (
 	// input formats
-	SelectObjectInputFormatParquet SelectObjectInputFormat = "parquet"
+	SelectObjectInputFormatParquet   SelectObjectInputFormat = "parquet"
+	SelectObjectInputFormatCSV       SelectObjectInputFormat = "csv"
+	SelectObjectInputFormatJSONLines SelectObjectInputFormat = "jsonl"
 
 	// output formats
 	SelectObjectOutputFormatCSV  SelectObjectOutputFormat = "csv"
 	SelectObjectOutputFormatJSON SelectObjectOutputFormat = "json"
+
+	// compression types of CSV and JSON Lines inputs
+	SelectCompressionNone  SelectCompressionType = ""
+	SelectCompressionGzip  SelectCompressionType = "gzip"
+	SelectCompressionBzip2 SelectCompressionType = "bzip2"
+
+	// SelectCSVFileHeaderNone means that the first line of the CSV input is a record
+	SelectCSVFileHeaderNone SelectCSVFileHeaderInfo = "NONE"
+	// SelectCSVFileHeaderIgnore means that the first line of the CSV input is a header, which is skipped
+	SelectCSVFileHeaderIgnore SelectCSVFileHeaderInfo = "IGNORE"
+	// SelectCSVFileHeaderUse means that the first line of the CSV input is a header, whose column names can be used
+	// in the SQL expression
+	SelectCSVFileHeaderUse SelectCSVFileHeaderInfo = "USE"
 )
 
 type SelectConfig struct {
@@ -19,9 +48,273 @@ type SelectConfig struct {
 	Key           string
 	InputFormat   SelectObjectInputFormat
 	OutputFormat  SelectObjectOutputFormat
+	// CSVInput configures CSV inputs, using comma separated records with no header if nil
+	CSVInput *SelectCSVInput
+	// CompressionType is the compression of CSV and JSON Lines inputs
+	CompressionType SelectCompressionType
+	// OnProgress, if set, is called with the progress of the query while the object is being scanned, and with its
+	// final statistics
+	OnProgress func(SelectStats)
+}
+
+// SelectCSVInput describes the format of CSV inputs
+type SelectCSVInput struct {
+	// FileHeaderInfo describes the first line of the input, defaulting to SelectCSVFileHeaderNone
+	FileHeaderInfo SelectCSVFileHeaderInfo
+	// FieldDelimiter separates the fields of a record, defaulting to a comma
+	FieldDelimiter string
+	// RecordDelimiter separates records, defaulting to a newline
+	RecordDelimiter string
+	// QuoteCharacter is the character used for escaping delimiters in fields, defaulting to a double quote
+	QuoteCharacter string
+	// Comments is the character prefixing lines to be ignored
+	Comments string
+	// AllowQuotedRecordDelimiter allows quoted fields to contain record delimiters, which makes scanning slower
+	AllowQuotedRecordDelimiter bool
+}
+
+// SelectStats reports the progress of a query
+type SelectStats struct {
+	// BytesScanned is the number of bytes of the object scanned so far
+	BytesScanned int64
+	// BytesProcessed is the number of uncompressed bytes processed so far
+	BytesProcessed int64
+	// BytesReturned is the number of bytes of records returned so far
+	BytesReturned int64
 }
 
 type SelectResult struct {
 	Data  []byte
 	Error error
 }
+
+// SelectRecordIterator iterates over the records returned by a query, one at a time.
+// JSON records are separated by newlines, while CSV records are parsed with encoding/csv, since quoted fields can
+// contain newlines.
+type SelectRecordIterator struct {
+	ctx        context.Context
+	events     <-chan types.SelectObjectContentEventStream
+	streamErr  func() error
+	close      func() error
+	onProgress func(SelectStats)
+	csvOutput  bool
+
+	buf    []byte
+	record []byte
+	stats  SelectStats
+	ended  bool
+	err    error
+
+	csvReader *csv.Reader
+	csvRead   int // number of bytes of buf already read by csvReader
+	fields    []string
+}
+
+// Next advances to the next record, returning false when there are no more records or an error occurred
+func (it *SelectRecordIterator) Next() bool {
+	if it.csvOutput {
+		return it.nextCSV()
+	}
+	for {
+		if i := bytes.IndexByte(it.buf, '\n'); i >= 0 {
+			it.record, it.buf = it.buf[:i], it.buf[i+1:]
+			return true
+		}
+		if !it.fill() {
+			if len(it.buf) > 0 && it.err == nil {
+				it.record, it.buf = it.buf, nil
+				return true
+			}
+			it.record = nil
+			return false
+		}
+	}
+}
+
+// nextCSV advances to the next CSV record, keeping both its fields and its raw bytes
+func (it *SelectRecordIterator) nextCSV() bool {
+	if it.csvReader == nil {
+		it.csvReader = csv.NewReader(selectCSVStream{it})
+		it.csvReader.FieldsPerRecord = -1
+		it.csvReader.ReuseRecord = true
+	}
+	// the buffer starts right after the previous record, i.e. at the input offset of csvReader
+	start := it.csvReader.InputOffset()
+	fields, err := it.csvReader.Read()
+	if err != nil {
+		if !errors.Is(err, io.EOF) && it.err == nil {
+			it.err = fmt.Errorf("parsing CSV record: %w", err)
+		}
+		it.record, it.fields = nil, nil
+		return false
+	}
+	n := int(it.csvReader.InputOffset() - start)
+	record := it.buf[:n]
+	it.buf, it.csvRead = it.buf[n:], it.csvRead-n
+	// csvReader skips empty lines
+	it.record = bytes.TrimSuffix(bytes.TrimLeft(record, "\n"), []byte("\n"))
+	it.fields = fields
+	return true
+}
+
+// selectCSVStream reads the records payloads of the iterator, keeping them in its buffer until consumed by nextCSV
+type selectCSVStream struct{ it *SelectRecordIterator }
+
+func (s selectCSVStream) Read(p []byte) (int, error) {
+	it := s.it
+	for it.csvRead == len(it.buf) {
+		if !it.fill() {
+			if it.err != nil {
+				return 0, it.err
+			}
+			return 0, io.EOF
+		}
+	}
+	n := copy(p, it.buf[it.csvRead:])
+	it.csvRead += n
+	return n, nil
+}
+
+// fill appends the next records payload to the buffer, returning false once the stream ended or failed
+func (it *SelectRecordIterator) fill() bool {
+	if len(it.buf) == 0 {
+		it.buf = it.buf[:0:0] // releasing the consumed payloads
+	}
+	for !it.ended && it.err == nil {
+		select {
+		case <-it.ctx.Done():
+			it.err = it.ctx.Err()
+		case event, ok := <-it.events:
+			if !ok {
+				if it.err = it.streamErr(); it.err == nil {
+					it.err = errors.New("select stream ended unexpectedly")
+				}
+				continue
+			}
+			switch e := event.(type) {
+			case *types.SelectObjectContentEventStreamMemberRecords:
+				it.buf = append(it.buf, e.Value.Payload...)
+				return true
+			case *types.SelectObjectContentEventStreamMemberProgress:
+				it.progress(e.Value.Details.BytesScanned, e.Value.Details.BytesProcessed, e.Value.Details.BytesReturned)
+			case *types.SelectObjectContentEventStreamMemberStats:
+				it.progress(e.Value.Details.BytesScanned, e.Value.Details.BytesProcessed, e.Value.Details.BytesReturned)
+			case *types.SelectObjectContentEventStreamMemberEnd:
+				it.ended = true
+			}
+		}
+	}
+	return false
+}
+
+// Get returns the current record, without its trailing newline.
+// The record is only valid until the next call to Next.
+func (it *SelectRecordIterator) Get() []byte {
+	return it.record
+}
+
+// Fields returns the fields of the current record if the output format is CSV, nil otherwise.
+// The fields are only valid until the next call to Next.
+func (it *SelectRecordIterator) Fields() []string {
+	return it.fields
+}
+
+// Stats returns the latest statistics reported by the query
+func (it *SelectRecordIterator) Stats() SelectStats {
+	return it.stats
+}
+
+// Err returns the error which stopped the iteration, if any
+func (it *SelectRecordIterator) Err() error {
+	return it.err
+}
+
+// Close releases the underlying stream. It must be called once done with the iterator.
+func (it *SelectRecordIterator) Close() error {
+	return it.close()
+}
+
+func (it *SelectRecordIterator) progress(scanned, processed, returned *int64) {
+	it.stats = SelectStats{
+		BytesScanned:   aws.ToInt64(scanned),
+		BytesProcessed: aws.ToInt64(processed),
+		BytesReturned:  aws.ToInt64(returned),
+	}
+	if it.onProgress != nil {
+		it.onProgress(it.stats)
+	}
+}
+
+func createS3SelectSerializationV2(selectConfig SelectConfig) (*types.InputSerialization, *types.OutputSerialization, error) {
+	var inputSerialization *types.InputSerialization
+	switch selectConfig.InputFormat {
+	case SelectObjectInputFormatParquet:
+		if selectConfig.CompressionType != SelectCompressionNone {
+			return nil, nil, fmt.Errorf("compression type %q is not supported for parquet", selectConfig.CompressionType)
+		}
+		inputSerialization = &types.InputSerialization{
+			Parquet: &types.ParquetInput{},
+		}
+	case SelectObjectInputFormatCSV:
+		csvInput := &types.CSVInput{FileHeaderInfo: types.FileHeaderInfoNone}
+		if c := selectConfig.CSVInput; c != nil {
+			switch c.FileHeaderInfo {
+			case "", SelectCSVFileHeaderNone:
+			case SelectCSVFileHeaderIgnore, SelectCSVFileHeaderUse:
+				csvInput.FileHeaderInfo = types.FileHeaderInfo(c.FileHeaderInfo)
+			default:
+				return nil, nil, fmt.Errorf("invalid CSV file header info: %s", c.FileHeaderInfo)
+			}
+			if c.FieldDelimiter != "" {
+				csvInput.FieldDelimiter = aws.String(c.FieldDelimiter)
+			}
+			if c.RecordDelimiter != "" {
+				csvInput.RecordDelimiter = aws.String(c.RecordDelimiter)
+			}
+			if c.QuoteCharacter != "" {
+				csvInput.QuoteCharacter = aws.String(c.QuoteCharacter)
+			}
+			if c.Comments != "" {
+				csvInput.Comments = aws.String(c.Comments)
+			}
+			if c.AllowQuotedRecordDelimiter {
+				csvInput.AllowQuotedRecordDelimiter = aws.Bool(true)
+			}
+		}
+		inputSerialization = &types.InputSerialization{CSV: csvInput}
+	case SelectObjectInputFormatJSONLines:
+		inputSerialization = &types.InputSerialization{
+			JSON: &types.JSONInput{Type: types.JSONTypeLines},
+		}
+	default:
+		return nil, nil, fmt.Errorf("invalid input format: %s", selectConfig.InputFormat)
+	}
+	switch selectConfig.CompressionType {
+	case SelectCompressionNone:
+	case SelectCompressionGzip:
+		inputSerialization.CompressionType = types.CompressionTypeGzip
+	case SelectCompressionBzip2:
+		inputSerialization.CompressionType = types.CompressionTypeBzip2
+	default:
+		return nil, nil, fmt.Errorf("invalid compression type: %s", selectConfig.CompressionType)
+	}
+
+	var outputSerialization *types.OutputSerialization
+	switch selectConfig.OutputFormat {
+	case SelectObjectOutputFormatCSV:
+		outputSerialization = &types.OutputSerialization{
+			CSV: &types.CSVOutput{
+				RecordDelimiter: aws.String("\n"),
+				FieldDelimiter:  aws.String(","),
+			},
+		}
+	case SelectObjectOutputFormatJSON:
+		outputSerialization = &types.OutputSerialization{
+			JSON: &types.JSONOutput{RecordDelimiter: aws.String("\n")},
+		}
+	default:
+		return nil, nil, fmt.Errorf("invalid output format: %s", selectConfig.OutputFormat)
+	}
+
+	return inputSerialization, outputSerialization, nil
+}
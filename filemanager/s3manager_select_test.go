@@ -0,0 +1,134 @@
+package filemanager
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateS3SelectSerialization(t *testing.T) {
+	t.Run("csv", func(t *testing.T) {
+		input, output, err := createS3SelectSerializationV2(SelectConfig{
+			InputFormat:     SelectObjectInputFormatCSV,
+			OutputFormat:    SelectObjectOutputFormatJSON,
+			CompressionType: SelectCompressionGzip,
+			CSVInput: &SelectCSVInput{
+				FileHeaderInfo: SelectCSVFileHeaderUse,
+				FieldDelimiter: ";",
+			},
+		})
+		require.NoError(t, err)
+		require.Equal(t, types.CompressionTypeGzip, input.CompressionType)
+		require.Equal(t, types.FileHeaderInfoUse, input.CSV.FileHeaderInfo)
+		require.Equal(t, ";", aws.ToString(input.CSV.FieldDelimiter))
+		require.Nil(t, input.CSV.RecordDelimiter)
+		require.NotNil(t, output.JSON)
+	})
+
+	t.Run("json lines", func(t *testing.T) {
+		input, output, err := createS3SelectSerializationV2(SelectConfig{
+			InputFormat:     SelectObjectInputFormatJSONLines,
+			OutputFormat:    SelectObjectOutputFormatCSV,
+			CompressionType: SelectCompressionBzip2,
+		})
+		require.NoError(t, err)
+		require.Equal(t, types.CompressionTypeBzip2, input.CompressionType)
+		require.Equal(t, types.JSONTypeLines, input.JSON.Type)
+		require.NotNil(t, output.CSV)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		for _, c := range []SelectConfig{
+			{InputFormat: "xml", OutputFormat: SelectObjectOutputFormatJSON},
+			{InputFormat: SelectObjectInputFormatCSV, OutputFormat: "xml"},
+			{InputFormat: SelectObjectInputFormatCSV, OutputFormat: SelectObjectOutputFormatJSON, CompressionType: "zip"},
+			{InputFormat: SelectObjectInputFormatParquet, OutputFormat: SelectObjectOutputFormatJSON, CompressionType: SelectCompressionGzip},
+			{InputFormat: SelectObjectInputFormatCSV, OutputFormat: SelectObjectOutputFormatJSON, CSVInput: &SelectCSVInput{FileHeaderInfo: "MAYBE"}},
+		} {
+			_, _, err := createS3SelectSerializationV2(c)
+			require.Error(t, err, "%+v", c)
+		}
+	})
+}
+
+func TestSelectRecordIterator(t *testing.T) {
+	newIterator := func(events ...types.SelectObjectContentEventStream) (*SelectRecordIterator, *[]SelectStats) {
+		ch := make(chan types.SelectObjectContentEventStream, len(events))
+		for _, e := range events {
+			ch <- e
+		}
+		close(ch)
+		var progress []SelectStats
+		return &SelectRecordIterator{
+			ctx:        context.Background(),
+			events:     ch,
+			streamErr:  func() error { return nil },
+			close:      func() error { return nil },
+			onProgress: func(s SelectStats) { progress = append(progress, s) },
+		}, &progress
+	}
+	records := func(payload string) types.SelectObjectContentEventStream {
+		return &types.SelectObjectContentEventStreamMemberRecords{Value: types.RecordsEvent{Payload: []byte(payload)}}
+	}
+
+	t.Run("records split across payloads", func(t *testing.T) {
+		it, progress := newIterator(
+			records(`{"a":1}`+"\n"+`{"a"`),
+			&types.SelectObjectContentEventStreamMemberProgress{Value: types.ProgressEvent{Details: &types.Progress{
+				BytesScanned: aws.Int64(10), BytesProcessed: aws.Int64(20), BytesReturned: aws.Int64(5),
+			}}},
+			records(`:2}`+"\n"),
+			records(`{"a":3}`),
+			&types.SelectObjectContentEventStreamMemberStats{Value: types.StatsEvent{Details: &types.Stats{
+				BytesScanned: aws.Int64(100), BytesProcessed: aws.Int64(200), BytesReturned: aws.Int64(21),
+			}}},
+			&types.SelectObjectContentEventStreamMemberEnd{},
+		)
+		var got []string
+		for it.Next() {
+			got = append(got, string(it.Get()))
+		}
+		require.NoError(t, it.Err())
+		require.Equal(t, []string{`{"a":1}`, `{"a":2}`, `{"a":3}`}, got)
+		require.Equal(t, SelectStats{BytesScanned: 100, BytesProcessed: 200, BytesReturned: 21}, it.Stats())
+		require.Equal(t, []SelectStats{{10, 20, 5}, {100, 200, 21}}, *progress)
+	})
+
+	t.Run("csv records with quoted newlines", func(t *testing.T) {
+		it, _ := newIterator(
+			records("a,\"multi\nli"),
+			records("ne\"\n\"quoted \"\"x\"\"\",b\n"),
+			records("\nlast,record"),
+			&types.SelectObjectContentEventStreamMemberEnd{},
+		)
+		it.csvOutput = true
+		var got []string
+		var fields [][]string
+		for it.Next() {
+			got = append(got, string(it.Get()))
+			fields = append(fields, slices.Clone(it.Fields()))
+		}
+		require.NoError(t, it.Err())
+		require.Equal(t, []string{"a,\"multi\nline\"", `"quoted ""x""",b`, "last,record"}, got)
+		require.Equal(t, [][]string{{"a", "multi\nline"}, {`quoted "x"`, "b"}, {"last", "record"}}, fields)
+	})
+
+	t.Run("invalid csv records", func(t *testing.T) {
+		it, _ := newIterator(records("a,\"b\n"), &types.SelectObjectContentEventStreamMemberEnd{})
+		it.csvOutput = true
+		require.False(t, it.Next())
+		require.ErrorContains(t, it.Err(), "parsing CSV record")
+	})
+
+	t.Run("stream ending without end event", func(t *testing.T) {
+		it, _ := newIterator(records("a\n"))
+		require.True(t, it.Next())
+		require.Equal(t, "a", string(it.Get()))
+		require.False(t, it.Next())
+		require.Error(t, it.Err())
+	})
+}
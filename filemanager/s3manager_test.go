@@ -10,6 +10,8 @@ import (
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
+	s3manager "github.com/aws/aws-sdk-go-v2/feature/s3/manager"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
 	"github.com/minio/minio-go/v7"
 	"github.com/ory/dockertest/v3"
 	"github.com/stretchr/testify/assert"
@@ -26,6 +28,50 @@ func TestNewS3ManagerWithNil(t *testing.T) {
 	assert.Nil(t, s3Manager)
 }
 
+func TestS3ManagerPresign(t *testing.T) {
+	s3Manager, err := NewS3Manager(config.Default, map[string]any{
+		"bucketName":  "someBucket",
+		"region":      "us-east-1",
+		"accessKeyID": "someAccessKeyId",
+		"accessKey":   "someSecretAccessKey",
+	}, logger.NOP, func() time.Duration { return time.Minute })
+	require.NoError(t, err)
+
+	getURL, err := s3Manager.PresignGet(context.Background(), "some/key", time.Hour)
+	require.NoError(t, err)
+	require.Contains(t, getURL, "some/key")
+	require.Contains(t, getURL, "X-Amz-Expires=3600")
+
+	putURL, err := s3Manager.PresignPut(context.Background(), "some/key", MaxPresignExpiry)
+	require.NoError(t, err)
+	require.Contains(t, putURL, "X-Amz-Expires=604800")
+
+	_, err = s3Manager.PresignGet(context.Background(), "some/key", 0)
+	require.ErrorIs(t, err, ErrInvalidPresignExpiry)
+	_, err = s3Manager.PresignPut(context.Background(), "some/key", MaxPresignExpiry+time.Second)
+	require.ErrorIs(t, err, ErrInvalidPresignExpiry)
+	_, err = s3Manager.presign(context.Background(), "DELETE", "some/key", time.Hour)
+	require.ErrorIs(t, err, ErrNotSupported)
+}
+
+func TestS3TransferTuning(t *testing.T) {
+	uploader := s3Uploader(s3.New(s3.Options{}), TransferSettings{})
+	require.EqualValues(t, s3manager.DefaultUploadPartSize, uploader.PartSize)
+	require.Equal(t, s3manager.DefaultUploadConcurrency, uploader.Concurrency)
+
+	uploader = s3Uploader(s3.New(s3.Options{}), TransferSettings{
+		PartSize:          8 * 1024 * 1024,
+		UploadConcurrency: 10,
+		MaxInFlightMemory: 32 * 1024 * 1024,
+	})
+	require.EqualValues(t, 8*1024*1024, uploader.PartSize)
+	require.Equal(t, 4, uploader.Concurrency, "concurrency should be capped by the max in-flight memory")
+
+	downloader := s3Downloader(s3.New(s3.Options{}), TransferSettings{DownloadConcurrency: 2, MaxInFlightMemory: 1})
+	require.EqualValues(t, s3manager.DefaultDownloadPartSize, downloader.PartSize)
+	require.Equal(t, 1, downloader.Concurrency)
+}
+
 func TestNewS3ManagerWithAccessKeys(t *testing.T) {
 	s3Manager, err := NewS3Manager(config.Default, map[string]any{
 		"bucketName":  "someBucket",
@@ -175,3 +221,23 @@ func TestEmptyRegion(t *testing.T) {
 		require.Equal(t, expectedRegionError, err.Error())
 	})
 }
+
+func TestS3CopyPartRanges(t *testing.T) {
+	require.Empty(t, s3CopyPartRanges(0))
+	require.Equal(t, [][2]int64{{0, 9}}, s3CopyPartRanges(10))
+	require.Equal(t, [][2]int64{{0, s3MaxCopySize - 1}}, s3CopyPartRanges(s3MaxCopySize))
+
+	for _, size := range []int64{s3MaxCopySize + 1, 2*s3MaxCopySize + s3MinPartSize/2, 7*s3MaxCopySize - 3} {
+		ranges := s3CopyPartRanges(size)
+		require.Len(t, ranges, int((size+s3MaxCopySize-1)/s3MaxCopySize))
+		var next int64
+		for _, r := range ranges {
+			require.Equal(t, next, r[0], "ranges should be contiguous")
+			partSize := r[1] - r[0] + 1
+			require.LessOrEqual(t, partSize, int64(s3MaxCopySize))
+			require.GreaterOrEqual(t, partSize, int64(s3MinPartSize), "a trailing part must not be too small")
+			next = r[1] + 1
+		}
+		require.Equal(t, size, next)
+	}
+}
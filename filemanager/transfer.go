@@ -0,0 +1,198 @@
+package filemanager
+
+import (
+	"fmt"
+	"io"
+	"sync/atomic"
+)
+
+// TransferSettings tunes multipart uploads and parallel downloads. Zero values keep the defaults of each provider.
+//
+//   - S3 and DigitalOcean Spaces: mapped to the part size and concurrency of the S3 upload and download managers.
+//   - GCS: the part size is the chunk size of resumable uploads, buffered in memory. Uploads and downloads are
+//     sequential, hence concurrency settings are ignored.
+//   - Azure Blob Storage: mapped to the block size and parallelism of block uploads, and of parallel downloads into
+//     files (other downloads are sequential).
+//   - MinIO: mapped to the part size and number of threads of uploads, streaming parts concurrently when uploading
+//     from a reader. Downloads are sequential.
+//   - Local: ignored, apart from progress callbacks.
+type TransferSettings struct {
+	// PartSize is the size of the parts uploaded or downloaded at a time, at least 5MiB
+	PartSize int64
+	// UploadConcurrency is the number of parts uploaded in parallel
+	UploadConcurrency int
+	// DownloadConcurrency is the number of parts downloaded in parallel
+	DownloadConcurrency int
+	// MaxInFlightMemory caps the memory used for buffering parts, lowering the concurrency so that concurrency times
+	// the part size (either PartSize or the provider's default) doesn't exceed it. GCS chunks are capped by it too.
+	// For MinIO, which sizes parts automatically, it is applied only if PartSize is set.
+	MaxInFlightMemory int64
+	// OnProgress is called while files are uploaded or downloaded, reporting the bytes transferred so far.
+	// It can be called concurrently by parallel transfers, hence it must be safe for concurrent use and fast.
+	OnProgress func(TransferProgress)
+}
+
+// TransferProgress reports the progress of an upload or download
+type TransferProgress struct {
+	// Operation is either "upload" or "download"
+	Operation string
+	// Key is the key of the file being transferred
+	Key string
+	// Bytes is the number of bytes transferred so far.
+	// It may go down if a part is retried, depending on the provider.
+	Bytes int64
+}
+
+func (s TransferSettings) validate() error {
+	if s.PartSize != 0 && (s.PartSize < s3MinPartSize || s.PartSize > s3MaxCopySize) {
+		return fmt.Errorf("part size must be between %d and %d bytes: %d", s3MinPartSize, int64(s3MaxCopySize), s.PartSize)
+	}
+	if s.UploadConcurrency < 0 || s.DownloadConcurrency < 0 {
+		return fmt.Errorf("concurrency cannot be negative")
+	}
+	if s.MaxInFlightMemory < 0 {
+		return fmt.Errorf("max in-flight memory cannot be negative")
+	}
+	return nil
+}
+
+// uploadParts returns the part size and concurrency of uploads, given the provider's defaults
+func (s TransferSettings) uploadParts(defaultPartSize int64, defaultConcurrency int) (int64, int) {
+	partSize := s.partSize(defaultPartSize)
+	return partSize, s.concurrency(s.UploadConcurrency, defaultConcurrency, partSize)
+}
+
+// downloadParts returns the part size and concurrency of downloads, given the provider's defaults
+func (s TransferSettings) downloadParts(defaultPartSize int64, defaultConcurrency int) (int64, int) {
+	partSize := s.partSize(defaultPartSize)
+	return partSize, s.concurrency(s.DownloadConcurrency, defaultConcurrency, partSize)
+}
+
+func (s TransferSettings) partSize(defaultPartSize int64) int64 {
+	if s.PartSize > 0 {
+		return s.PartSize
+	}
+	return defaultPartSize
+}
+
+func (s TransferSettings) concurrency(concurrency, defaultConcurrency int, partSize int64) int {
+	if concurrency <= 0 {
+		concurrency = defaultConcurrency
+	}
+	if s.MaxInFlightMemory > 0 && partSize > 0 {
+		concurrency = min(concurrency, max(1, int(s.MaxInFlightMemory/partSize)))
+	}
+	return concurrency
+}
+
+// SetTransferSettings overrides the transfer settings of the file manager
+func (manager *baseManager) SetTransferSettings(settings TransferSettings) {
+	manager.transfer = settings
+}
+
+// progressFunc returns a function reporting the total bytes transferred so far, or nil if progress isn't reported
+func (manager *baseManager) progressFunc(operation, key string) func(bytes int64) {
+	onProgress := manager.transfer.OnProgress
+	if onProgress == nil {
+		return nil
+	}
+	return func(bytes int64) {
+		onProgress(TransferProgress{Operation: operation, Key: key, Bytes: bytes})
+	}
+}
+
+// progressReader wraps the reader for reporting the bytes read, preserving its io.Seeker and io.ReaderAt
+// implementations which SDKs use for reading parts in parallel
+func (manager *baseManager) progressReader(operation, key string, r io.Reader) io.Reader {
+	progress := manager.progressFunc(operation, key)
+	if progress == nil {
+		return r
+	}
+	pr := &progressReader{Reader: r, progress: progress}
+	rsa, ok := r.(interface {
+		io.ReadSeeker
+		io.ReaderAt
+	})
+	if !ok {
+		return pr
+	}
+	return &progressReadSeekerAt{progressReader: pr, rsa: rsa}
+}
+
+// progressWriterAt wraps the writer for reporting the bytes written
+func (manager *baseManager) progressWriterAt(operation, key string, w io.WriterAt) io.WriterAt {
+	progress := manager.progressFunc(operation, key)
+	if progress == nil {
+		return w
+	}
+	return &progressWriterAt{WriterAt: w, progress: progress}
+}
+
+// progressHook returns a reader receiving the bytes transferred, as expected by MinIO, or nil if progress isn't
+// reported
+func (manager *baseManager) progressHook(operation, key string) io.Reader {
+	progress := manager.progressFunc(operation, key)
+	if progress == nil {
+		return nil
+	}
+	return &progressHook{progress: progress}
+}
+
+type progressReader struct {
+	io.Reader
+	n        atomic.Int64
+	progress func(int64)
+}
+
+func (r *progressReader) Read(p []byte) (int, error) {
+	n, err := r.Reader.Read(p)
+	if n > 0 {
+		r.progress(r.n.Add(int64(n)))
+	}
+	return n, err
+}
+
+type progressReadSeekerAt struct {
+	*progressReader
+	rsa interface {
+		io.ReadSeeker
+		io.ReaderAt
+	}
+}
+
+func (r *progressReadSeekerAt) Seek(offset int64, whence int) (int64, error) {
+	return r.rsa.Seek(offset, whence)
+}
+
+func (r *progressReadSeekerAt) ReadAt(p []byte, off int64) (int, error) {
+	n, err := r.rsa.ReadAt(p, off)
+	if n > 0 {
+		r.progress(r.n.Add(int64(n)))
+	}
+	return n, err
+}
+
+type progressWriterAt struct {
+	io.WriterAt
+	n        atomic.Int64
+	progress func(int64)
+}
+
+func (w *progressWriterAt) WriteAt(p []byte, off int64) (int, error) {
+	n, err := w.WriterAt.WriteAt(p, off)
+	if n > 0 {
+		w.progress(w.n.Add(int64(n)))
+	}
+	return n, err
+}
+
+// progressHook is passed as progress reader to MinIO, which reads from it as many bytes as it transfers
+type progressHook struct {
+	n        atomic.Int64
+	progress func(int64)
+}
+
+func (h *progressHook) Read(p []byte) (int, error) {
+	h.progress(h.n.Add(int64(len(p))))
+	return len(p), nil
+}
@@ -0,0 +1,45 @@
+package health
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/go-redis/redis/v8"
+	"go.etcd.io/etcd/api/v3/v3rpc/rpctypes"
+	clientv3 "go.etcd.io/etcd/client/v3"
+)
+
+// Pinger is implemented by clients exposing a context-aware Ping, e.g. *kafkaclient.Client
+type Pinger interface {
+	Ping(ctx context.Context) error
+}
+
+// Ping returns a check pinging the provided client, e.g. a *kafkaclient.Client
+func Ping(p Pinger) CheckFunc {
+	return p.Ping
+}
+
+// SQL returns a check pinging the provided database
+func SQL(db *sql.DB) CheckFunc {
+	return db.PingContext
+}
+
+// Redis returns a check sending a PING command to the provided redis client
+func Redis(rc redis.UniversalClient) CheckFunc {
+	return func(ctx context.Context) error {
+		return rc.Ping(ctx).Err()
+	}
+}
+
+// Etcd returns a check reading a key from etcd, the same way etcdctl checks the health of an endpoint.
+// A permission denied error is considered healthy, since it means that the cluster is able to serve requests.
+func Etcd(client *clientv3.Client) CheckFunc {
+	return func(ctx context.Context) error {
+		_, err := client.Get(ctx, "health")
+		if err == nil || errors.Is(err, rpctypes.ErrPermissionDenied) {
+			return nil
+		}
+		return err
+	}
+}
@@ -0,0 +1,290 @@
+// Package health provides a registry of named health checks, exposed as liveness and readiness HTTP endpoints and
+// reported through stats.
+//
+// Components register their checks once, at startup:
+//
+//	h := health.New(stats.Default)
+//	_ = h.Register("postgres", health.SQL(db), health.WithTimeout(2*time.Second))
+//	_ = h.Register("kafka", health.Ping(kafkaClient), health.WithCritical(false), health.WithCacheTTL(10*time.Second))
+//
+//	srv := &http.Server{Addr: ":8080", Handler: h.Handler()} // serves /health and /ready
+//	_ = httputil.ListenAndServe(ctx, srv)
+package health
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/errgroup"
+
+	"github.com/rudderlabs/rudder-go-kit/jsonrs"
+	"github.com/rudderlabs/rudder-go-kit/stats"
+)
+
+const (
+	// LivenessPath is the path where Handler serves the liveness endpoint
+	LivenessPath = "/health"
+	// ReadinessPath is the path where Handler serves the readiness endpoint
+	ReadinessPath = "/ready"
+
+	defaultTimeout = 5 * time.Second
+)
+
+// Status is the status of a single check or of the whole registry
+type Status string
+
+const (
+	// StatusUp means that all checks are passing
+	StatusUp Status = "UP"
+	// StatusDegraded means that at least one non-critical check is failing, while all critical ones are passing
+	StatusDegraded Status = "DEGRADED"
+	// StatusDown means that at least one critical check is failing
+	StatusDown Status = "DOWN"
+)
+
+// ErrAlreadyRegistered is returned when registering a check with a name that is already in use
+var ErrAlreadyRegistered = errors.New("health check already registered")
+
+// CheckFunc is a function checking the health of a component, returning a non-nil error if the component is unhealthy
+type CheckFunc func(ctx context.Context) error
+
+// CheckResult is the result of a single check
+type CheckResult struct {
+	Status    Status        `json:"status"`
+	Critical  bool          `json:"critical"`
+	Error     string        `json:"error,omitempty"`
+	Latency   time.Duration `json:"latency"`
+	CheckedAt time.Time     `json:"checkedAt"`
+}
+
+// Report is the result of running all the registered checks
+type Report struct {
+	Status Status                 `json:"status"`
+	Checks map[string]CheckResult `json:"checks,omitempty"`
+}
+
+// Option is a function used to configure a check
+type Option func(*check)
+
+// WithTimeout sets the maximum time a check is allowed to run for (default 5s)
+func WithTimeout(timeout time.Duration) Option {
+	return func(c *check) {
+		c.timeout = timeout
+	}
+}
+
+// WithCritical sets whether a failure of the check makes the whole service not ready (default true).
+// A failing non-critical check only degrades the overall status.
+func WithCritical(critical bool) Option {
+	return func(c *check) {
+		c.critical = critical
+	}
+}
+
+// WithCacheTTL caches the result of the check for the given duration, so that expensive checks are not run on every
+// probe (default 0, i.e. no caching)
+func WithCacheTTL(ttl time.Duration) Option {
+	return func(c *check) {
+		c.cacheTTL = ttl
+	}
+}
+
+type check struct {
+	name     string
+	fn       CheckFunc
+	timeout  time.Duration
+	critical bool
+	cacheTTL time.Duration
+
+	statusStat  stats.Measurement
+	latencyStat stats.Measurement
+
+	mu   sync.Mutex // protects last, serialising concurrent runs of the same check
+	last *CheckResult
+}
+
+// Registry holds the registered health checks
+type Registry struct {
+	stats stats.Stats
+	now   func() time.Time
+
+	mu     sync.RWMutex
+	checks map[string]*check
+}
+
+// New creates a new Registry reporting each check's status and latency through the provided stats
+func New(s stats.Stats) *Registry {
+	return &Registry{
+		stats:  s,
+		now:    time.Now,
+		checks: make(map[string]*check),
+	}
+}
+
+// Register registers a new named check
+func (r *Registry) Register(name string, fn CheckFunc, opts ...Option) error {
+	c := &check{
+		name:     name,
+		fn:       fn,
+		timeout:  defaultTimeout,
+		critical: true,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.checks[name]; ok {
+		return fmt.Errorf("%w: %s", ErrAlreadyRegistered, name)
+	}
+	tags := stats.Tags{"check": name, "critical": strconv.FormatBool(c.critical)}
+	c.statusStat = r.stats.NewTaggedStat("health_check_status", stats.GaugeType, tags)
+	c.latencyStat = r.stats.NewTaggedStat("health_check_latency", stats.TimerType, tags)
+	r.checks[name] = c
+	return nil
+}
+
+// Unregister removes a previously registered check
+func (r *Registry) Unregister(name string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.checks, name)
+}
+
+// Check runs all the registered checks concurrently and returns their report
+func (r *Registry) Check(ctx context.Context) Report {
+	r.mu.RLock()
+	checks := make([]*check, 0, len(r.checks))
+	for _, c := range r.checks {
+		checks = append(checks, c)
+	}
+	r.mu.RUnlock()
+	sort.Slice(checks, func(i, j int) bool { return checks[i].name < checks[j].name })
+
+	results := make([]CheckResult, len(checks))
+	var g errgroup.Group
+	for i, c := range checks {
+		g.Go(func() error {
+			results[i] = r.run(ctx, c)
+			return nil
+		})
+	}
+	_ = g.Wait()
+
+	report := Report{Status: StatusUp, Checks: make(map[string]CheckResult, len(checks))}
+	for i, c := range checks {
+		report.Checks[c.name] = results[i]
+		if results[i].Status == StatusUp {
+			continue
+		}
+		if c.critical {
+			report.Status = StatusDown
+		} else if report.Status == StatusUp {
+			report.Status = StatusDegraded
+		}
+	}
+	return report
+}
+
+// Run runs all the registered checks every interval, so that their stats are kept up to date even if no probes are
+// received. It blocks until the context is canceled.
+func (r *Registry) Run(ctx context.Context, interval time.Duration) {
+	for {
+		r.Check(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(interval):
+		}
+	}
+}
+
+func (r *Registry) run(ctx context.Context, c *check) CheckResult {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.last != nil && c.cacheTTL > 0 && r.now().Sub(c.last.CheckedAt) < c.cacheTTL {
+		return *c.last
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	start := r.now()
+	err := runCheck(ctx, c.fn)
+	res := CheckResult{
+		Status:    StatusUp,
+		Critical:  c.critical,
+		Latency:   r.now().Sub(start),
+		CheckedAt: start,
+	}
+	if err != nil {
+		res.Status = StatusDown
+		res.Error = err.Error()
+		c.statusStat.Gauge(0)
+	} else {
+		c.statusStat.Gauge(1)
+	}
+	c.latencyStat.SendTiming(res.Latency)
+	c.last = &res
+	return res
+}
+
+// runCheck runs the check making sure that the timeout is honoured even if the check ignores its context
+func runCheck(ctx context.Context, fn CheckFunc) (err error) {
+	done := make(chan error, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				done <- fmt.Errorf("check panicked: %v", r)
+			}
+		}()
+		done <- fn(ctx)
+	}()
+	select {
+	case err = <-done:
+		return err
+	case <-ctx.Done():
+		return fmt.Errorf("check timed out: %w", ctx.Err())
+	}
+}
+
+// ServeHTTP serves the readiness report: 200 if the status is UP or DEGRADED, 503 if it is DOWN
+func (r *Registry) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	report := r.Check(req.Context())
+	code := http.StatusOK
+	if report.Status == StatusDown {
+		code = http.StatusServiceUnavailable
+	}
+	writeJSON(w, code, report)
+}
+
+// Handler returns an http.Handler serving the liveness endpoint on LivenessPath, which always reports UP as long as
+// the process is able to serve requests, and the readiness endpoint on ReadinessPath, which runs all the checks
+// (see ServeHTTP).
+func (r *Registry) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc(LivenessPath, func(w http.ResponseWriter, _ *http.Request) {
+		writeJSON(w, http.StatusOK, Report{Status: StatusUp})
+	})
+	mux.Handle(ReadinessPath, r)
+	return mux
+}
+
+func writeJSON(w http.ResponseWriter, code int, report Report) {
+	body, err := jsonrs.Marshal(report)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_, _ = w.Write(body)
+}
@@ -0,0 +1,182 @@
+package health_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rudderlabs/rudder-go-kit/health"
+	"github.com/rudderlabs/rudder-go-kit/jsonrs"
+	"github.com/rudderlabs/rudder-go-kit/stats"
+	"github.com/rudderlabs/rudder-go-kit/stats/memstats"
+)
+
+func TestRegistry(t *testing.T) {
+	up := func(context.Context) error { return nil }
+	down := func(context.Context) error { return errors.New("boom") }
+
+	t.Run("all checks up", func(t *testing.T) {
+		s, err := memstats.New()
+		require.NoError(t, err)
+		h := health.New(s)
+		require.NoError(t, h.Register("a", up))
+		require.NoError(t, h.Register("b", up, health.WithCritical(false)))
+
+		report := h.Check(context.Background())
+		require.Equal(t, health.StatusUp, report.Status)
+		require.Len(t, report.Checks, 2)
+		require.Equal(t, health.StatusUp, report.Checks["a"].Status)
+		require.True(t, report.Checks["a"].Critical)
+		require.False(t, report.Checks["b"].Critical)
+
+		require.EqualValues(t, 1, s.Get("health_check_status", stats.Tags{"check": "a", "critical": "true"}).LastValue())
+		require.EqualValues(t, 1, s.Get("health_check_status", stats.Tags{"check": "b", "critical": "false"}).LastValue())
+		require.Len(t, s.Get("health_check_latency", stats.Tags{"check": "a", "critical": "true"}).Durations(), 1)
+	})
+
+	t.Run("non-critical check down", func(t *testing.T) {
+		s, err := memstats.New()
+		require.NoError(t, err)
+		h := health.New(s)
+		require.NoError(t, h.Register("a", up))
+		require.NoError(t, h.Register("b", down, health.WithCritical(false)))
+
+		report := h.Check(context.Background())
+		require.Equal(t, health.StatusDegraded, report.Status)
+		require.Equal(t, health.StatusDown, report.Checks["b"].Status)
+		require.Equal(t, "boom", report.Checks["b"].Error)
+		require.EqualValues(t, 0, s.Get("health_check_status", stats.Tags{"check": "b", "critical": "false"}).LastValue())
+	})
+
+	t.Run("critical check down", func(t *testing.T) {
+		h := health.New(stats.NOP)
+		require.NoError(t, h.Register("a", down))
+		require.NoError(t, h.Register("b", down, health.WithCritical(false)))
+		require.Equal(t, health.StatusDown, h.Check(context.Background()).Status)
+	})
+
+	t.Run("duplicate registration", func(t *testing.T) {
+		h := health.New(stats.NOP)
+		require.NoError(t, h.Register("a", up))
+		require.ErrorIs(t, h.Register("a", up), health.ErrAlreadyRegistered)
+		h.Unregister("a")
+		require.NoError(t, h.Register("a", up))
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		h := health.New(stats.NOP)
+		blocked := make(chan struct{})
+		defer close(blocked)
+		require.NoError(t, h.Register("a", func(context.Context) error {
+			<-blocked // ignoring the context on purpose
+			return nil
+		}, health.WithTimeout(10*time.Millisecond)))
+
+		report := h.Check(context.Background())
+		require.Equal(t, health.StatusDown, report.Status)
+		require.Contains(t, report.Checks["a"].Error, "timed out")
+	})
+
+	t.Run("panic", func(t *testing.T) {
+		h := health.New(stats.NOP)
+		require.NoError(t, h.Register("a", func(context.Context) error { panic("oops") }))
+		report := h.Check(context.Background())
+		require.Equal(t, health.StatusDown, report.Status)
+		require.Contains(t, report.Checks["a"].Error, "oops")
+	})
+
+	t.Run("cache", func(t *testing.T) {
+		h := health.New(stats.NOP)
+		var calls atomic.Int64
+		require.NoError(t, h.Register("cached", func(context.Context) error {
+			calls.Add(1)
+			return nil
+		}, health.WithCacheTTL(time.Hour)))
+		require.NoError(t, h.Register("uncached", func(context.Context) error {
+			calls.Add(1)
+			return nil
+		}))
+		for range 3 {
+			h.Check(context.Background())
+		}
+		require.EqualValues(t, 1+3, calls.Load())
+	})
+
+	t.Run("run", func(t *testing.T) {
+		h := health.New(stats.NOP)
+		var calls atomic.Int64
+		require.NoError(t, h.Register("a", func(context.Context) error {
+			calls.Add(1)
+			return nil
+		}))
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			h.Run(ctx, time.Millisecond)
+		}()
+		require.Eventually(t, func() bool { return calls.Load() >= 3 }, time.Second, time.Millisecond)
+		cancel()
+		<-done
+	})
+}
+
+func TestHandler(t *testing.T) {
+	h := health.New(stats.NOP)
+	var failing atomic.Bool
+	require.NoError(t, h.Register("a", func(context.Context) error {
+		if failing.Load() {
+			return errors.New("boom")
+		}
+		return nil
+	}))
+	srv := httptest.NewServer(h.Handler())
+	defer srv.Close()
+
+	get := func(t *testing.T, path string) (int, health.Report) {
+		t.Helper()
+		resp, err := http.Get(srv.URL + path)
+		require.NoError(t, err)
+		defer func() { _ = resp.Body.Close() }()
+		require.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+		var report health.Report
+		require.NoError(t, jsonrs.NewDecoder(resp.Body).Decode(&report))
+		return resp.StatusCode, report
+	}
+
+	code, report := get(t, health.ReadinessPath)
+	require.Equal(t, http.StatusOK, code)
+	require.Equal(t, health.StatusUp, report.Status)
+	require.Equal(t, health.StatusUp, report.Checks["a"].Status)
+
+	failing.Store(true)
+	code, report = get(t, health.ReadinessPath)
+	require.Equal(t, http.StatusServiceUnavailable, code)
+	require.Equal(t, health.StatusDown, report.Status)
+	require.Equal(t, "boom", report.Checks["a"].Error)
+
+	code, report = get(t, health.LivenessPath)
+	require.Equal(t, http.StatusOK, code)
+	require.Equal(t, health.StatusUp, report.Status)
+	require.Empty(t, report.Checks)
+}
+
+func TestSQL(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.MonitorPingsOption(true))
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	check := health.SQL(db)
+	mock.ExpectPing()
+	require.NoError(t, check(context.Background()))
+	mock.ExpectPing().WillReturnError(errors.New("connection refused"))
+	require.EqualError(t, check(context.Background()), "connection refused")
+	require.NoError(t, mock.ExpectationsWereMet())
+}
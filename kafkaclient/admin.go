@@ -0,0 +1,385 @@
+package client
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+)
+
+var (
+	// ErrTopicAlreadyExists is returned by CreateTopics for topics which already exist
+	ErrTopicAlreadyExists = kafka.TopicAlreadyExists
+	// ErrUnknownTopic is returned for topics which don't exist
+	ErrUnknownTopic = kafka.UnknownTopicOrPartition
+)
+
+// TopicConfig describes a topic to create
+type TopicConfig struct {
+	Topic             string
+	NumPartitions     int
+	ReplicationFactor int
+	// Configs are the topic-level configuration overrides, e.g. "retention.ms" or "cleanup.policy"
+	Configs map[string]string
+}
+
+// TopicDescription describes an existing topic
+type TopicDescription struct {
+	Topic      string
+	Internal   bool
+	Partitions []PartitionDescription
+}
+
+// PartitionDescription describes a partition of a topic, identifying brokers by ID
+type PartitionDescription struct {
+	ID       int
+	Leader   int
+	Replicas []int
+	ISR      []int
+}
+
+// GroupOffset is the offset committed by a consumer group for a partition
+type GroupOffset struct {
+	Topic     string
+	Partition int
+	// CommittedOffset is -1 if the group didn't commit any offset for the partition
+	CommittedOffset int64
+	// HighWatermark is the offset of the next message to be published to the partition
+	HighWatermark int64
+	// Lag is the number of messages not consumed by the group yet, -1 if no offset was committed
+	Lag int64
+}
+
+// CreateTopics creates the given topics. Topics which could not be created are reported in the returned error,
+// e.g. ErrTopicAlreadyExists, while the others are created anyway.
+func (c *Client) CreateTopics(ctx context.Context, topics ...TopicConfig) error {
+	req := &kafka.CreateTopicsRequest{Topics: make([]kafka.TopicConfig, len(topics))}
+	for i, t := range topics {
+		req.Topics[i] = kafka.TopicConfig{
+			Topic:             t.Topic,
+			NumPartitions:     t.NumPartitions,
+			ReplicationFactor: t.ReplicationFactor,
+		}
+		for name, value := range t.Configs {
+			req.Topics[i].ConfigEntries = append(req.Topics[i].ConfigEntries, kafka.ConfigEntry{
+				ConfigName: name, ConfigValue: value,
+			})
+		}
+	}
+	return c.admin(func(client *kafka.Client) error {
+		res, err := client.CreateTopics(ctx, req)
+		if err != nil {
+			return fmt.Errorf("creating topics: %w", err)
+		}
+		return topicErrors("creating topic", res.Errors)
+	})
+}
+
+// DeleteTopics deletes the given topics. Topics which could not be deleted are reported in the returned error,
+// e.g. ErrUnknownTopic, while the others are deleted anyway.
+func (c *Client) DeleteTopics(ctx context.Context, topics ...string) error {
+	return c.admin(func(client *kafka.Client) error {
+		res, err := client.DeleteTopics(ctx, &kafka.DeleteTopicsRequest{Topics: topics})
+		if err != nil {
+			return fmt.Errorf("deleting topics: %w", err)
+		}
+		return topicErrors("deleting topic", res.Errors)
+	})
+}
+
+// DescribeTopics returns the partitions of the given topics, sorted by ID, or of all the topics if none is given
+func (c *Client) DescribeTopics(ctx context.Context, topics ...string) ([]TopicDescription, error) {
+	var descriptions []TopicDescription
+	err := c.admin(func(client *kafka.Client) error {
+		res, err := client.Metadata(ctx, &kafka.MetadataRequest{Topics: topics})
+		if err != nil {
+			return fmt.Errorf("getting metadata: %w", err)
+		}
+		var errs []error
+		for _, t := range res.Topics {
+			if t.Error != nil {
+				errs = append(errs, fmt.Errorf("describing topic %q: %w", t.Name, t.Error))
+				continue
+			}
+			description := TopicDescription{
+				Topic:      t.Name,
+				Internal:   t.Internal,
+				Partitions: make([]PartitionDescription, len(t.Partitions)),
+			}
+			for i, p := range t.Partitions {
+				description.Partitions[i] = PartitionDescription{
+					ID:       p.ID,
+					Leader:   p.Leader.ID,
+					Replicas: brokerIDs(p.Replicas),
+					ISR:      brokerIDs(p.Isr),
+				}
+			}
+			slices.SortFunc(description.Partitions, func(a, b PartitionDescription) int { return a.ID - b.ID })
+			descriptions = append(descriptions, description)
+		}
+		return errors.Join(errs...)
+	})
+	return descriptions, err
+}
+
+// ListConsumerGroups returns the IDs of the consumer groups known by the cluster, sorted
+func (c *Client) ListConsumerGroups(ctx context.Context) ([]string, error) {
+	var groups []string
+	err := c.admin(func(client *kafka.Client) error {
+		res, err := client.ListGroups(ctx, &kafka.ListGroupsRequest{})
+		if err != nil {
+			return fmt.Errorf("listing groups: %w", err)
+		}
+		if res.Error != nil {
+			return fmt.Errorf("listing groups: %w", res.Error)
+		}
+		for _, g := range res.Groups {
+			// groups used only for committing offsets have no protocol type
+			if g.ProtocolType == "consumer" || g.ProtocolType == "" {
+				groups = append(groups, g.GroupID)
+			}
+		}
+		return nil
+	})
+	slices.Sort(groups)
+	return groups, err
+}
+
+// DescribeGroupOffsets returns the offsets committed by a consumer group, along with its lag, for all the partitions
+// of the given topics, or for the partitions the group committed offsets for if no topic is given.
+// Offsets are sorted by topic and partition.
+func (c *Client) DescribeGroupOffsets(ctx context.Context, groupID string, topics ...string) ([]GroupOffset, error) {
+	var offsets []GroupOffset
+	err := c.admin(func(client *kafka.Client) error {
+		req := &kafka.OffsetFetchRequest{GroupID: groupID}
+		if len(topics) > 0 {
+			partitions, err := topicPartitions(ctx, client, topics...)
+			if err != nil {
+				return err
+			}
+			req.Topics = partitions
+		}
+		res, err := client.OffsetFetch(ctx, req)
+		if err != nil {
+			return fmt.Errorf("fetching offsets of group %q: %w", groupID, err)
+		}
+		if res.Error != nil {
+			return fmt.Errorf("fetching offsets of group %q: %w", groupID, res.Error)
+		}
+
+		listReq := &kafka.ListOffsetsRequest{Topics: make(map[string][]kafka.OffsetRequest, len(res.Topics))}
+		for topic, partitions := range res.Topics {
+			for _, p := range partitions {
+				if p.Error != nil {
+					return fmt.Errorf("fetching offset of group %q for %s[%d]: %w", groupID, topic, p.Partition, p.Error)
+				}
+				offsets = append(offsets, GroupOffset{Topic: topic, Partition: p.Partition, CommittedOffset: p.CommittedOffset})
+				listReq.Topics[topic] = append(listReq.Topics[topic], kafka.LastOffsetOf(p.Partition))
+			}
+		}
+		if len(offsets) == 0 {
+			return nil
+		}
+
+		highWatermarks, err := listOffsets(ctx, client, listReq)
+		if err != nil {
+			return err
+		}
+		for i := range offsets {
+			o := &offsets[i]
+			o.HighWatermark = highWatermarks[topicPartition{topic: o.Topic, partition: o.Partition}].LastOffset
+			o.Lag = -1
+			if o.CommittedOffset >= 0 {
+				o.Lag = max(o.HighWatermark-o.CommittedOffset, 0)
+			}
+		}
+		slices.SortFunc(offsets, func(a, b GroupOffset) int {
+			return cmp.Or(strings.Compare(a.Topic, b.Topic), a.Partition-b.Partition)
+		})
+		return nil
+	})
+	return offsets, err
+}
+
+// ResetOffsets commits the first or last offsets of all the partitions of a topic for a consumer group, returning
+// the committed offsets by partition.
+// The group must have no active members, otherwise the commit is rejected by the group coordinator.
+func (c *Client) ResetOffsets(
+	ctx context.Context, groupID, topic string, to ConsumerStartOffset,
+) (map[int]int64, error) {
+	timestamp := kafka.LastOffset
+	if to == FirstOffset {
+		timestamp = kafka.FirstOffset
+	}
+	return c.resetOffsets(ctx, groupID, topic, timestamp)
+}
+
+// ResetOffsetsToTime commits, for all the partitions of a topic, the offsets of the first messages published at or
+// after the given time for a consumer group, returning the committed offsets by partition.
+// Partitions without such messages are reset to their last offsets.
+// The group must have no active members, otherwise the commit is rejected by the group coordinator.
+func (c *Client) ResetOffsetsToTime(ctx context.Context, groupID, topic string, t time.Time) (map[int]int64, error) {
+	return c.resetOffsets(ctx, groupID, topic, t.UnixMilli())
+}
+
+// timestampOffset returns the offset listed for a timestamp, if any. Brokers answer with offset -1 when no message
+// was published at or after the timestamp, which kafka-go reports as such (keyed by the requested timestamp).
+func timestampOffset(po kafka.PartitionOffsets) (int64, bool) {
+	for offset := range po.Offsets {
+		if offset >= 0 {
+			return offset, true
+		}
+	}
+	return 0, false
+}
+
+func (c *Client) resetOffsets(ctx context.Context, groupID, topic string, timestamp int64) (map[int]int64, error) {
+	offsets := make(map[int]int64)
+	err := c.admin(func(client *kafka.Client) error {
+		partitions, err := topicPartitions(ctx, client, topic)
+		if err != nil {
+			return err
+		}
+
+		listReq := &kafka.ListOffsetsRequest{Topics: map[string][]kafka.OffsetRequest{topic: nil}}
+		for _, p := range partitions[topic] {
+			listReq.Topics[topic] = append(listReq.Topics[topic], kafka.OffsetRequest{Partition: p, Timestamp: timestamp})
+		}
+		listed, err := listOffsets(ctx, client, listReq)
+		if err != nil {
+			return err
+		}
+
+		// partitions without messages after the timestamp are reset to their last offset
+		latestReq := &kafka.ListOffsetsRequest{Topics: map[string][]kafka.OffsetRequest{}}
+		for _, p := range partitions[topic] {
+			po := listed[topicPartition{topic: topic, partition: p}]
+			switch timestamp {
+			case kafka.FirstOffset:
+				offsets[p] = po.FirstOffset
+			case kafka.LastOffset:
+				offsets[p] = po.LastOffset
+			default:
+				if offset, ok := timestampOffset(po); ok {
+					offsets[p] = offset
+				} else {
+					latestReq.Topics[topic] = append(latestReq.Topics[topic], kafka.LastOffsetOf(p))
+				}
+			}
+		}
+		if len(latestReq.Topics) > 0 {
+			latest, err := listOffsets(ctx, client, latestReq)
+			if err != nil {
+				return err
+			}
+			for tp, po := range latest {
+				offsets[tp.partition] = po.LastOffset
+			}
+		}
+
+		commitReq := &kafka.OffsetCommitRequest{
+			GroupID:      groupID,
+			GenerationID: -1, // committing outside a generation, as a simple consumer
+			Topics:       map[string][]kafka.OffsetCommit{topic: nil},
+		}
+		for p, offset := range offsets {
+			commitReq.Topics[topic] = append(commitReq.Topics[topic], kafka.OffsetCommit{Partition: p, Offset: offset})
+		}
+		res, err := client.OffsetCommit(ctx, commitReq)
+		if err != nil {
+			return fmt.Errorf("committing offsets of group %q: %w", groupID, err)
+		}
+		var errs []error
+		for _, p := range res.Topics[topic] {
+			if p.Error != nil {
+				errs = append(errs, fmt.Errorf("committing offset of group %q for %s[%d]: %w", groupID, topic, p.Partition, p.Error))
+			}
+		}
+		return errors.Join(errs...)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return offsets, nil
+}
+
+// admin calls fn with a kafka.Client using the same dialer, TLS and SASL configuration as the client.
+// A new transport is used for each operation, so that the cached cluster metadata is never stale.
+func (c *Client) admin(fn func(client *kafka.Client) error) error {
+	transport, err := c.transport("")
+	if err != nil {
+		return err
+	}
+	defer transport.CloseIdleConnections()
+	return fn(&kafka.Client{
+		Addr:      kafka.TCP(c.addresses...),
+		Transport: transport,
+	})
+}
+
+// topicPartitions returns the partitions of the given topics
+func topicPartitions(ctx context.Context, client *kafka.Client, topics ...string) (map[string][]int, error) {
+	res, err := client.Metadata(ctx, &kafka.MetadataRequest{Topics: topics})
+	if err != nil {
+		return nil, fmt.Errorf("getting metadata: %w", err)
+	}
+	partitions := make(map[string][]int, len(res.Topics))
+	for _, t := range res.Topics {
+		if t.Error != nil {
+			return nil, fmt.Errorf("getting metadata of topic %q: %w", t.Name, t.Error)
+		}
+		for _, p := range t.Partitions {
+			partitions[t.Name] = append(partitions[t.Name], p.ID)
+		}
+	}
+	for _, topic := range topics {
+		if _, ok := partitions[topic]; !ok {
+			return nil, fmt.Errorf("getting metadata of topic %q: %w", topic, ErrUnknownTopic)
+		}
+	}
+	return partitions, nil
+}
+
+// listOffsets lists the requested offsets, returning them by topic partition
+func listOffsets(
+	ctx context.Context, client *kafka.Client, req *kafka.ListOffsetsRequest,
+) (map[topicPartition]kafka.PartitionOffsets, error) {
+	res, err := client.ListOffsets(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("listing offsets: %w", err)
+	}
+	offsets := make(map[topicPartition]kafka.PartitionOffsets)
+	for topic, partitions := range res.Topics {
+		for _, p := range partitions {
+			if p.Error != nil {
+				return nil, fmt.Errorf("listing offsets of %s[%d]: %w", topic, p.Partition, p.Error)
+			}
+			offsets[topicPartition{topic: topic, partition: p.Partition}] = p
+		}
+	}
+	return offsets, nil
+}
+
+// topicErrors joins the non-nil errors of a response, by topic
+func topicErrors(op string, errs map[string]error) error {
+	var joined []error
+	for topic, err := range errs {
+		if err != nil {
+			joined = append(joined, fmt.Errorf("%s %q: %w", op, topic, err))
+		}
+	}
+	return errors.Join(joined...)
+}
+
+func brokerIDs(brokers []kafka.Broker) []int {
+	ids := make([]int, len(brokers))
+	for i, b := range brokers {
+		ids[i] = b.ID
+	}
+	return ids
+}
@@ -8,6 +8,8 @@ import (
 
 	"github.com/segmentio/kafka-go"
 	"golang.org/x/crypto/ssh"
+
+	"github.com/rudderlabs/rudder-go-kit/awsutil"
 )
 
 // Logger specifies a logger used to report internal changes within the consumer
@@ -95,6 +97,15 @@ func New(network string, addresses []string, conf Config) (*Client, error) {
 		}
 	}
 
+	if conf.TLS != nil && conf.TLS.verifiesDialedHost() {
+		dial := dialer.DialFunc
+		if dial == nil {
+			dial = (&net.Dialer{Timeout: conf.DialTimeout}).DialContext
+		}
+		dialer.DialFunc = dialTLS(dial, dialer.TLS)
+		dialer.TLS = nil
+	}
+
 	return &Client{
 		network:   network,
 		addresses: addresses,
@@ -131,6 +142,19 @@ func NewAzureEventHubs(addresses []string, connectionString string, conf Config)
 	return New("tcp", addresses, conf)
 }
 
+// NewAWSMSK returns a Kafka client pre-configured to connect to Amazon MSK with IAM access control, using the
+// credentials of the given AWS session configuration.
+// addresses should be in the form of "host:port" where the port is usually 9098 for IAM access control on MSK
+func NewAWSMSK(addresses []string, sessionConfig *awsutil.SessionConfig, conf Config) (*Client, error) {
+	conf.SASL = &SASL{
+		AWSMSKIAM: &AWSMSKIAM{SessionConfig: sessionConfig},
+	}
+	conf.TLS = &TLS{
+		WithSystemCertPool: true,
+	}
+	return New("tcp", addresses, conf)
+}
+
 // Ping is used to check the connectivity only, then it discards the connection
 // Ping ensures that at least one of the provided addresses is reachable.
 func (c *Client) Ping(ctx context.Context) error {
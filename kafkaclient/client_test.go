@@ -7,6 +7,7 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"sync"
 	"sync/atomic"
@@ -610,6 +611,15 @@ func TestWriteErrors(t *testing.T) {
 	require.True(t, IsProducerErrTemporary(err))
 }
 
+func TestConsumerReceiveBatchValidation(t *testing.T) {
+	c := &Consumer{}
+	for _, maxMessages := range []int{0, -1} {
+		batch, err := c.ReceiveBatch(context.Background(), maxMessages, time.Second)
+		require.ErrorContains(t, err, "maxMessages must be greater than 0")
+		require.Nil(t, batch)
+	}
+}
+
 func TestConfluentCloud(t *testing.T) {
 	kafkaHost := os.Getenv("TEST_KAFKA_CONFLUENT_CLOUD_HOST")
 	confluentCloudKey := os.Getenv("TEST_KAFKA_CONFLUENT_CLOUD_KEY")
@@ -813,6 +823,439 @@ func TestConsumerACK(t *testing.T) {
 	require.Equal(t, noOfMessages, count)
 }
 
+func TestTransactionalProducer(t *testing.T) {
+	// Prepare cluster - Zookeeper + 1 Kafka brokers
+	pool, err := dockertest.NewPool("")
+	require.NoError(t, err)
+
+	kafkaContainer, err := dockerKafka.Setup(pool, t,
+		dockerKafka.WithBrokers(1))
+	require.NoError(t, err)
+
+	kafkaHost := kafkaContainer.Brokers[0]
+	kafkaClient, err := New("tcp", []string{kafkaHost}, Config{ClientID: "some-client", DialTimeout: 5 * time.Second})
+	require.NoError(t, err)
+
+	var (
+		ctx, cancel = context.WithCancel(context.Background())
+		tc          = testutil.NewWithDialer(kafkaClient.dialer, kafkaClient.network, kafkaClient.addresses...)
+		inputTopic  = t.Name() + "-input"
+		outputTopic = t.Name() + "-output"
+	)
+	t.Cleanup(cancel)
+
+	require.NoError(t, kafkaClient.Ping(ctx))
+	for _, topic := range []string{inputTopic, outputTopic} {
+		require.Eventually(t, func() bool {
+			err := tc.CreateTopic(ctx, topic, 2, 1) // partitions = 2, replication factor = 1
+			if err != nil {
+				t.Logf("Could not create topic: %v", err)
+			}
+			return err == nil
+		}, defaultTestTimeout, time.Second)
+	}
+
+	messages := func(prefix string, n int) []Message {
+		msgs := make([]Message, n)
+		for i := range msgs {
+			msgs[i] = Message{
+				Key:   fmt.Appendf(nil, "%s-key-%d", prefix, i),
+				Value: fmt.Appendf(nil, "%s-value-%d", prefix, i),
+				Topic: outputTopic,
+			}
+		}
+		return msgs
+	}
+
+	var producer *TransactionalProducer
+	require.Eventually(t, func() bool {
+		// the transaction coordinator may take a while to be available
+		producer, err = kafkaClient.NewTransactionalProducer(ctx, TransactionalProducerConfig{
+			ClientID:        "producer-01",
+			TransactionalID: "txn-01",
+		})
+		if err != nil {
+			t.Logf("Could not create transactional producer: %v", err)
+		}
+		return err == nil
+	}, defaultTestTimeout, time.Second)
+
+	// aborted transaction
+	require.NoError(t, producer.BeginTxn())
+	require.Eventually(t, func() bool {
+		err := producer.Publish(ctx, messages("aborted", 3)...)
+		if err != nil {
+			t.Logf("Could not publish: %v", err)
+			require.NoError(t, producer.AbortTxn(ctx))
+			require.NoError(t, producer.BeginTxn())
+		}
+		return err == nil
+	}, defaultTestTimeout, time.Second)
+	require.NoError(t, producer.AbortTxn(ctx))
+
+	// committed transaction, along with the offsets of consumed messages
+	require.NoError(t, producer.BeginTxn())
+	require.NoError(t, producer.Publish(ctx, messages("committed", 3)...))
+	require.NoError(t, producer.SendOffsetsToTxn(ctx, "group-01",
+		Message{Topic: inputTopic, Partition: 0, Offset: 3},
+		Message{Topic: inputTopic, Partition: 0, Offset: 4},
+		Message{Topic: inputTopic, Partition: 1, Offset: 7},
+	))
+	require.NoError(t, producer.CommitTxn(ctx))
+
+	// only committed messages are read
+	consumer := kafkaClient.NewConsumer(outputTopic, ConsumerConfig{
+		GroupID:       "group-02",
+		StartOffset:   FirstOffset,
+		ReadCommitted: true,
+		Logger:        newKafkaLogger(t, false),
+		ErrorLogger:   newKafkaLogger(t, true),
+	})
+	t.Cleanup(func() { _ = consumer.Close(context.Background()) })
+	received := make([]string, 0, 3)
+	for range 3 {
+		receiveCtx, receiveCancel := context.WithTimeout(ctx, defaultTestTimeout)
+		msg, err := consumer.Receive(receiveCtx)
+		receiveCancel()
+		require.NoError(t, err)
+		received = append(received, string(msg.Value))
+	}
+	require.ElementsMatch(t, []string{"committed-value-0", "committed-value-1", "committed-value-2"}, received)
+	receiveCtx, receiveCancel := context.WithTimeout(ctx, 5*time.Second)
+	_, err = consumer.Receive(receiveCtx)
+	receiveCancel()
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+
+	// offsets sent to the transaction are committed
+	offsets, err := (&kafka.Client{Addr: kafka.TCP(kafkaHost)}).OffsetFetch(ctx, &kafka.OffsetFetchRequest{
+		GroupID: "group-01",
+		Topics:  map[string][]int{inputTopic: {0, 1}},
+	})
+	require.NoError(t, err)
+	require.NoError(t, offsets.Error)
+	committed := make(map[int]int64)
+	for _, partition := range offsets.Topics[inputTopic] {
+		require.NoError(t, partition.Error)
+		committed[partition.Partition] = partition.CommittedOffset
+	}
+	require.Equal(t, map[int]int64{0: 5, 1: 8}, committed)
+
+	// a new producer with the same transactional ID fences the previous one
+	require.NoError(t, producer.BeginTxn())
+	newProducer, err := kafkaClient.NewTransactionalProducer(ctx, TransactionalProducerConfig{TransactionalID: "txn-01"})
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = newProducer.Close(context.Background()) })
+	require.Error(t, producer.Publish(ctx, messages("fenced", 1)...))
+
+	// idempotent producer
+	idempotentProducer, err := kafkaClient.NewTransactionalProducer(ctx, TransactionalProducerConfig{})
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = idempotentProducer.Close(context.Background()) })
+	require.ErrorIs(t, idempotentProducer.BeginTxn(), ErrNotTransactional)
+	require.NoError(t, idempotentProducer.Publish(ctx, messages("idempotent", 3)...))
+	require.NoError(t, idempotentProducer.Publish(ctx, messages("idempotent", 3)...))
+	received = received[:0]
+	for range 6 {
+		receiveCtx, receiveCancel := context.WithTimeout(ctx, defaultTestTimeout)
+		msg, err := consumer.Receive(receiveCtx)
+		receiveCancel()
+		require.NoError(t, err)
+		received = append(received, string(msg.Value))
+	}
+	require.ElementsMatch(t, []string{
+		"idempotent-value-0", "idempotent-value-1", "idempotent-value-2",
+		"idempotent-value-0", "idempotent-value-1", "idempotent-value-2",
+	}, received)
+}
+
+func TestConsumerGroup(t *testing.T) {
+	// Prepare cluster - Zookeeper + 1 Kafka brokers
+	pool, err := dockertest.NewPool("")
+	require.NoError(t, err)
+
+	kafkaContainer, err := dockerKafka.Setup(pool, t,
+		dockerKafka.WithBrokers(1))
+	require.NoError(t, err)
+
+	kafkaHost := kafkaContainer.Brokers[0]
+	kafkaClient, err := New("tcp", []string{kafkaHost}, Config{ClientID: "some-client", DialTimeout: 5 * time.Second})
+	require.NoError(t, err)
+
+	var (
+		noOfMessages = 20
+		topic        = t.Name()
+		ctx, cancel  = context.WithCancel(context.Background())
+		tc           = testutil.NewWithDialer(kafkaClient.dialer, kafkaClient.network, kafkaClient.addresses...)
+	)
+	t.Cleanup(cancel)
+
+	require.NoError(t, kafkaClient.Ping(ctx))
+	require.Eventually(t, func() bool {
+		err := tc.CreateTopic(ctx, topic, 3, 1) // partitions = 3, replication factor = 1
+		if err != nil {
+			t.Logf("Could not create topic: %v", err)
+		}
+		return err == nil
+	}, defaultTestTimeout, time.Second)
+
+	producer, err := kafkaClient.NewProducer(ProducerConfig{
+		ClientID:     "producer-01",
+		WriteTimeout: 5 * time.Second,
+		ReadTimeout:  5 * time.Second,
+	})
+	require.NoError(t, err)
+	publishMessages(ctx, t, producer, noOfMessages)
+
+	t.Run("receive batch", func(t *testing.T) {
+		consumer := kafkaClient.NewConsumer(topic, ConsumerConfig{
+			GroupID:     "batch-group",
+			StartOffset: FirstOffset,
+		})
+		t.Cleanup(func() { _ = consumer.Close(context.Background()) })
+
+		var received []Message
+		require.Eventually(t, func() bool {
+			batch, err := consumer.ReceiveBatch(ctx, 7, time.Second)
+			require.NoError(t, err)
+			require.LessOrEqual(t, len(batch), 7)
+			if len(batch) > 0 {
+				require.NoError(t, consumer.Ack(ctx, batch...))
+			}
+			received = append(received, batch...)
+			return len(received) == noOfMessages
+		}, defaultTestTimeout, time.Millisecond)
+
+		batch, err := consumer.ReceiveBatch(ctx, 7, time.Second)
+		require.NoError(t, err)
+		require.Empty(t, batch)
+	})
+
+	t.Run("per-partition workers", func(t *testing.T) {
+		var (
+			mu        sync.Mutex
+			processed = make(map[int32][]int64)
+			assigned  = make(chan map[string][]int, 1)
+			revoked   = make(chan map[string][]int, 1)
+			failed    atomic.Bool
+		)
+		newGroup := func() *ConsumerGroup {
+			group, err := kafkaClient.NewConsumerGroup([]string{topic}, ConsumerGroupConfig{
+				GroupID:        "workers-group",
+				StartOffset:    FirstOffset,
+				CommitInterval: 100 * time.Millisecond,
+				OnAssigned: func(_ context.Context, partitions map[string][]int) {
+					assigned <- partitions
+				},
+				OnRevoked: func(_ context.Context, partitions map[string][]int) {
+					revoked <- partitions
+				},
+				Logger:      newKafkaLogger(t, false),
+				ErrorLogger: newKafkaLogger(t, true),
+			})
+			require.NoError(t, err)
+			return group
+		}
+
+		// the handler fails once, after processing half of the messages
+		handlerErr := errors.New("some error")
+		group := newGroup()
+		err := group.Run(ctx, func(_ context.Context, msg Message) error {
+			mu.Lock()
+			defer mu.Unlock()
+			count := 0
+			for _, offsets := range processed {
+				count += len(offsets)
+			}
+			if count == noOfMessages/2 && !failed.Swap(true) {
+				return handlerErr
+			}
+			processed[msg.Partition] = append(processed[msg.Partition], msg.Offset)
+			return nil
+		})
+		require.ErrorIs(t, err, handlerErr)
+		require.Equal(t, map[string][]int{topic: {0, 1, 2}}, sortedPartitions(<-assigned))
+		require.Equal(t, map[string][]int{topic: {0, 1, 2}}, sortedPartitions(<-revoked))
+
+		// a new member resumes from the committed offsets
+		runCtx, runCancel := context.WithCancel(ctx)
+		group = newGroup()
+		done := make(chan error, 1)
+		go func() {
+			done <- group.Run(runCtx, func(_ context.Context, msg Message) error {
+				mu.Lock()
+				defer mu.Unlock()
+				processed[msg.Partition] = append(processed[msg.Partition], msg.Offset)
+				return nil
+			})
+		}()
+		<-assigned
+		require.Eventually(t, func() bool {
+			mu.Lock()
+			defer mu.Unlock()
+			count := 0
+			for _, offsets := range processed {
+				count += len(offsets)
+			}
+			return count == noOfMessages
+		}, defaultTestTimeout, 100*time.Millisecond)
+		runCancel()
+		require.NoError(t, <-done)
+		<-revoked
+
+		// every message was processed exactly once, in order within its partition
+		mu.Lock()
+		defer mu.Unlock()
+		for partition, offsets := range processed {
+			for i := range offsets {
+				require.EqualValues(t, i, offsets[i], "partition %d", partition)
+			}
+		}
+	})
+}
+
+func TestTimestampOffset(t *testing.T) {
+	offset, ok := timestampOffset(kafka.PartitionOffsets{Offsets: map[int64]time.Time{42: time.UnixMilli(1000)}})
+	require.True(t, ok)
+	require.EqualValues(t, 42, offset)
+
+	_, ok = timestampOffset(kafka.PartitionOffsets{Offsets: map[int64]time.Time{-1: time.UnixMilli(1000)}})
+	require.False(t, ok, "no message published at or after the timestamp")
+	_, ok = timestampOffset(kafka.PartitionOffsets{})
+	require.False(t, ok)
+}
+
+func TestAdmin(t *testing.T) {
+	pool, err := dockertest.NewPool("")
+	require.NoError(t, err)
+
+	kafkaContainer, err := dockerKafka.Setup(pool, t,
+		dockerKafka.WithBrokers(1))
+	require.NoError(t, err)
+
+	kafkaHost := kafkaContainer.Brokers[0]
+	kafkaClient, err := New("tcp", []string{kafkaHost}, Config{ClientID: "some-client", DialTimeout: 5 * time.Second})
+	require.NoError(t, err)
+
+	var (
+		noOfMessages = 30
+		topic        = t.Name()
+		groupID      = "admin-group"
+		ctx, cancel  = context.WithCancel(context.Background())
+	)
+	t.Cleanup(cancel)
+
+	require.Eventually(t, func() bool {
+		err := kafkaClient.CreateTopics(ctx, TopicConfig{
+			Topic:             topic,
+			NumPartitions:     3,
+			ReplicationFactor: 1,
+			Configs:           map[string]string{"retention.ms": "3600000"},
+		})
+		if err != nil {
+			t.Logf("Could not create topic: %v", err)
+		}
+		return err == nil
+	}, defaultTestTimeout, time.Second)
+	require.ErrorIs(t, kafkaClient.CreateTopics(ctx, TopicConfig{
+		Topic: topic, NumPartitions: 3, ReplicationFactor: 1,
+	}), ErrTopicAlreadyExists)
+
+	descriptions, err := kafkaClient.DescribeTopics(ctx, topic)
+	require.NoError(t, err)
+	require.Len(t, descriptions, 1)
+	require.Equal(t, topic, descriptions[0].Topic)
+	require.Len(t, descriptions[0].Partitions, 3)
+	for i, p := range descriptions[0].Partitions {
+		require.Equal(t, i, p.ID)
+		require.Equal(t, []int{p.Leader}, p.Replicas)
+		require.Equal(t, []int{p.Leader}, p.ISR)
+	}
+
+	producer, err := kafkaClient.NewProducer(ProducerConfig{
+		ClientID:     "producer-01",
+		WriteTimeout: 5 * time.Second,
+		ReadTimeout:  5 * time.Second,
+	})
+	require.NoError(t, err)
+	beforePublish := time.Now()
+	publishMessages(ctx, t, producer, noOfMessages)
+
+	offsets, err := kafkaClient.DescribeGroupOffsets(ctx, groupID, topic)
+	require.NoError(t, err)
+	require.Len(t, offsets, 3)
+	var total int64
+	for i, o := range offsets {
+		require.Equal(t, i, o.Partition)
+		require.EqualValues(t, -1, o.CommittedOffset)
+		require.EqualValues(t, -1, o.Lag)
+		total += o.HighWatermark
+	}
+	require.EqualValues(t, noOfMessages, total)
+
+	t.Run("reset to latest", func(t *testing.T) {
+		reset, err := kafkaClient.ResetOffsets(ctx, groupID, topic, LastOffset)
+		require.NoError(t, err)
+		require.Len(t, reset, 3)
+
+		offsets, err := kafkaClient.DescribeGroupOffsets(ctx, groupID)
+		require.NoError(t, err)
+		require.Len(t, offsets, 3)
+		for _, o := range offsets {
+			require.Equal(t, reset[o.Partition], o.CommittedOffset)
+			require.Equal(t, o.HighWatermark, o.CommittedOffset)
+			require.Zero(t, o.Lag)
+		}
+
+		groups, err := kafkaClient.ListConsumerGroups(ctx)
+		require.NoError(t, err)
+		require.Contains(t, groups, groupID)
+	})
+
+	t.Run("reset to earliest", func(t *testing.T) {
+		reset, err := kafkaClient.ResetOffsets(ctx, groupID, topic, FirstOffset)
+		require.NoError(t, err)
+		require.Equal(t, map[int]int64{0: 0, 1: 0, 2: 0}, reset)
+
+		offsets, err := kafkaClient.DescribeGroupOffsets(ctx, groupID, topic)
+		require.NoError(t, err)
+		var lag int64
+		for _, o := range offsets {
+			require.Zero(t, o.CommittedOffset)
+			lag += o.Lag
+		}
+		require.EqualValues(t, noOfMessages, lag)
+	})
+
+	t.Run("reset to time", func(t *testing.T) {
+		reset, err := kafkaClient.ResetOffsetsToTime(ctx, groupID, topic, beforePublish.Add(-time.Minute))
+		require.NoError(t, err)
+		require.Equal(t, map[int]int64{0: 0, 1: 0, 2: 0}, reset)
+
+		// no message was published after now, hence offsets are reset to the latest ones
+		reset, err = kafkaClient.ResetOffsetsToTime(ctx, groupID, topic, time.Now().Add(time.Minute))
+		require.NoError(t, err)
+		for _, o := range offsets {
+			require.Equal(t, o.HighWatermark, reset[o.Partition])
+		}
+	})
+
+	t.Run("delete topics", func(t *testing.T) {
+		require.NoError(t, kafkaClient.DeleteTopics(ctx, topic))
+		require.Eventually(t, func() bool {
+			_, err := kafkaClient.DescribeTopics(ctx, topic)
+			return errors.Is(err, ErrUnknownTopic)
+		}, defaultTestTimeout, time.Second)
+		require.ErrorIs(t, kafkaClient.DeleteTopics(ctx, topic), ErrUnknownTopic)
+	})
+}
+
+func sortedPartitions(partitions map[string][]int) map[string][]int {
+	for topic := range partitions {
+		slices.Sort(partitions[topic])
+	}
+	return partitions
+}
+
 func TestSSH(t *testing.T) {
 	pool, err := dockertest.NewPool("")
 	require.NoError(t, err)
@@ -67,18 +67,55 @@ func (c *Config) defaults() {
 type TLS struct {
 	Cert, Key,
 	CACertificate []byte
+	// CertFile, KeyFile and CACertificateFile are paths to PEM files to use instead of Cert, Key and CACertificate.
+	// They are checked for changes whenever a connection is established, so that new connections use certificates
+	// rotated on disk.
+	CertFile, KeyFile,
+	CACertificateFile string
 	WithSystemCertPool,
 	InsecureSkipVerify bool
+	// MinVersion is the minimum TLS version, e.g. tls.VersionTLS12, defaults to TLS 1.2
+	MinVersion uint16
+	// MaxVersion is the maximum TLS version, defaults to TLS 1.3
+	MaxVersion uint16
+	// CipherSuites are the enabled TLS 1.0-1.2 cipher suites, e.g. tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256, defaults
+	// to the Go ones. TLS 1.3 cipher suites are not configurable.
+	CipherSuites []uint16
 }
 
 func (c *TLS) build() (*tls.Config, error) {
-	if len(c.CACertificate) == 0 && !c.InsecureSkipVerify && !c.WithSystemCertPool {
+	if len(c.CACertificate) == 0 && c.CACertificateFile == "" && !c.InsecureSkipVerify && !c.WithSystemCertPool {
 		return nil, fmt.Errorf("invalid TLS configuration, either provide certificates or skip validation")
 	}
+	if len(c.CACertificate) > 0 && c.CACertificateFile != "" {
+		return nil, fmt.Errorf("invalid TLS configuration, either provide a CA certificate or a CA certificate file")
+	}
+	if (len(c.Cert) > 0 || len(c.Key) > 0) && (c.CertFile != "" || c.KeyFile != "") {
+		return nil, fmt.Errorf("invalid TLS configuration, either provide a certificate or a certificate file")
+	}
+	if (c.CertFile == "") != (c.KeyFile == "") {
+		return nil, fmt.Errorf("invalid TLS configuration, both a certificate file and a key file are required")
+	}
 
 	conf := &tls.Config{ // skipcq: GSC-G402
-		MinVersion: tls.VersionTLS11,
-		MaxVersion: tls.VersionTLS12,
+		MinVersion:   tls.VersionTLS12,
+		MaxVersion:   tls.VersionTLS13,
+		CipherSuites: c.CipherSuites,
+	}
+	if c.MinVersion != 0 {
+		conf.MinVersion = c.MinVersion
+	}
+	if c.MaxVersion != 0 {
+		conf.MaxVersion = c.MaxVersion
+	}
+	if conf.MinVersion < tls.VersionTLS10 || conf.MaxVersion > tls.VersionTLS13 || conf.MinVersion > conf.MaxVersion {
+		return nil, fmt.Errorf("invalid TLS versions, min %s and max %s",
+			tls.VersionName(conf.MinVersion), tls.VersionName(conf.MaxVersion))
+	}
+	for _, id := range c.CipherSuites {
+		if !isKnownCipherSuite(id) {
+			return nil, fmt.Errorf("unknown TLS cipher suite %#04x", id)
+		}
 	}
 
 	if c.InsecureSkipVerify {
@@ -112,15 +149,87 @@ func (c *TLS) build() (*tls.Config, error) {
 		conf.Certificates = []tls.Certificate{certificate}
 	}
 
+	if c.CertFile != "" || c.CACertificateFile != "" {
+		files, err := newTLSFiles(c.CertFile, c.KeyFile, c.CACertificateFile, c.WithSystemCertPool)
+		if err != nil {
+			return nil, err
+		}
+		if c.CertFile != "" {
+			conf.GetClientCertificate = files.clientCertificate
+		}
+		if c.verifiesDialedHost() {
+			// the server certificate is verified against the CA certificates loaded at the time of the connection
+			conf.InsecureSkipVerify = true // skipcq: GSC-G402
+			conf.VerifyConnection = files.verifyConnection
+		}
+	}
+
 	return conf, nil
 }
 
+// verifiesDialedHost returns true if the server certificate is verified by the client rather than by crypto/tls, in
+// which case the TLS handshake has to be done by dialTLS so that it is verified against the dialed host
+func (c *TLS) verifiesDialedHost() bool {
+	return c.CACertificateFile != "" && !c.InsecureSkipVerify
+}
+
+// TLSVersionFromString returns the TLS version from its string counterpart, e.g. "1.2"
+func TLSVersionFromString(s string) (uint16, error) {
+	switch s {
+	case "1.0":
+		return tls.VersionTLS10, nil
+	case "1.1":
+		return tls.VersionTLS11, nil
+	case "1.2":
+		return tls.VersionTLS12, nil
+	case "1.3":
+		return tls.VersionTLS13, nil
+	}
+	return 0, fmt.Errorf("TLS version out of the known domain: %s", s)
+}
+
+// CipherSuiteFromString returns the TLS cipher suite from its name, e.g. "TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256"
+func CipherSuiteFromString(s string) (uint16, error) {
+	for _, suites := range [][]*tls.CipherSuite{tls.CipherSuites(), tls.InsecureCipherSuites()} {
+		for _, suite := range suites {
+			if suite.Name == s {
+				return suite.ID, nil
+			}
+		}
+	}
+	return 0, fmt.Errorf("TLS cipher suite out of the known domain: %s", s)
+}
+
+func isKnownCipherSuite(id uint16) bool {
+	for _, suites := range [][]*tls.CipherSuite{tls.CipherSuites(), tls.InsecureCipherSuites()} {
+		for _, suite := range suites {
+			if suite.ID == id {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 type SASL struct {
 	ScramHashGen       ScramHashGenerator
 	Username, Password string
+	// OAuthBearer, if set, makes the client authenticate with the OAUTHBEARER mechanism instead of PLAIN or SCRAM
+	OAuthBearer *OAuthBearer
+	// AWSMSKIAM, if set, makes the client authenticate with the AWS_MSK_IAM mechanism instead of PLAIN or SCRAM
+	AWSMSKIAM *AWSMSKIAM
 }
 
 func (c *SASL) build() (sasl.Mechanism, error) {
+	switch {
+	case c.OAuthBearer != nil && c.AWSMSKIAM != nil:
+		return nil, fmt.Errorf("invalid SASL configuration, either provide OAuthBearer or AWSMSKIAM")
+	case c.OAuthBearer != nil:
+		return c.OAuthBearer.build()
+	case c.AWSMSKIAM != nil:
+		return c.AWSMSKIAM.build()
+	}
+
 	switch c.ScramHashGen {
 	case ScramPlainText:
 		return plain.Mechanism{
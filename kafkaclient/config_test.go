@@ -1,6 +1,7 @@
 package client
 
 import (
+	"crypto/tls"
 	"testing"
 
 	"github.com/segmentio/kafka-go/sasl/plain"
@@ -73,6 +74,44 @@ func TestTLS(t *testing.T) {
 		require.Nil(t, conf)
 		require.ErrorContains(t, err, "could not get TLS certificate")
 	})
+	t.Run("versions and cipher suites", func(t *testing.T) {
+		conf, err := (&TLS{WithSystemCertPool: true}).build()
+		require.NoError(t, err)
+		require.EqualValues(t, tls.VersionTLS12, conf.MinVersion)
+		require.EqualValues(t, tls.VersionTLS13, conf.MaxVersion)
+
+		conf, err = (&TLS{
+			WithSystemCertPool: true,
+			MinVersion:         tls.VersionTLS12,
+			MaxVersion:         tls.VersionTLS12,
+			CipherSuites:       []uint16{tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256},
+		}).build()
+		require.NoError(t, err)
+		require.EqualValues(t, tls.VersionTLS12, conf.MinVersion)
+		require.EqualValues(t, tls.VersionTLS12, conf.MaxVersion)
+		require.Equal(t, []uint16{tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256}, conf.CipherSuites)
+
+		_, err = (&TLS{WithSystemCertPool: true, MinVersion: tls.VersionTLS13, MaxVersion: tls.VersionTLS12}).build()
+		require.ErrorContains(t, err, "invalid TLS versions")
+		_, err = (&TLS{WithSystemCertPool: true, CipherSuites: []uint16{0xffff}}).build()
+		require.ErrorContains(t, err, "unknown TLS cipher suite")
+	})
+}
+
+func TestTLSVersionFromString(t *testing.T) {
+	version, err := TLSVersionFromString("1.3")
+	require.NoError(t, err)
+	require.EqualValues(t, tls.VersionTLS13, version)
+	_, err = TLSVersionFromString("1.4")
+	require.Error(t, err)
+}
+
+func TestCipherSuiteFromString(t *testing.T) {
+	suite, err := CipherSuiteFromString("TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256")
+	require.NoError(t, err)
+	require.Equal(t, tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256, suite)
+	_, err = CipherSuiteFromString("foo")
+	require.Error(t, err)
 }
 
 func TestSASL(t *testing.T) {
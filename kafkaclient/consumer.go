@@ -2,9 +2,13 @@ package client
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"time"
 
 	"github.com/segmentio/kafka-go"
+
+	"github.com/rudderlabs/rudder-go-kit/stats"
 )
 
 type ConsumerStartOffset int64
@@ -22,17 +26,43 @@ type ConsumerConfig struct {
 	StartOffset         ConsumerStartOffset
 	CommitInterval      time.Duration
 	FetchBatchesMaxWait time.Duration
-	Logger              Logger
-	ErrorLogger         Logger
+	// ReadCommitted makes the consumer skip messages of aborted and ongoing transactions
+	ReadCommitted bool
+	Logger        Logger
+	ErrorLogger   Logger
+	// Stats, if set, is used for registering a collector reporting the consumer's metrics, tagged by topic,
+	// client ID and group ID
+	Stats stats.Stats
 }
 
 // Consumer provides a high-level API for reading messages from Kafka
 type Consumer struct {
-	reader *kafka.Reader
+	reader    *kafka.Reader
+	stats     stats.Stats
+	collector *consumerCollector
 }
 
 // NewConsumer instantiates a new consumer.
+// A failure registering the stats collector is ignored, leaving the consumer without metrics: use NewConsumerE for
+// handling it.
 func (c *Client) NewConsumer(topic string, conf ConsumerConfig) *Consumer { // skipcq: CRT-P0003
+	consumer, _ := c.newConsumer(topic, conf)
+	return consumer
+}
+
+// NewConsumerE instantiates a new consumer like NewConsumer, returning an error if the stats collector cannot be
+// registered.
+func (c *Client) NewConsumerE(topic string, conf ConsumerConfig) (*Consumer, error) { // skipcq: CRT-P0003
+	consumer, err := c.newConsumer(topic, conf)
+	if err != nil {
+		_ = consumer.reader.Close()
+		return nil, err
+	}
+	return consumer, nil
+}
+
+// newConsumer instantiates a new consumer, which is returned even if its stats collector cannot be registered
+func (c *Client) newConsumer(topic string, conf ConsumerConfig) (*Consumer, error) { // skipcq: CRT-P0003
 	var readerConf kafka.ReaderConfig
 
 	readerConf.Brokers = c.addresses
@@ -47,19 +77,34 @@ func (c *Client) NewConsumer(topic string, conf ConsumerConfig) *Consumer { // s
 	if conf.StartOffset == LastOffset {
 		readerConf.StartOffset = kafka.LastOffset
 	}
+	if conf.ReadCommitted {
+		readerConf.IsolationLevel = kafka.ReadCommitted
+	}
 
 	readerConf.Logger = conf.Logger
 	readerConf.ErrorLogger = conf.ErrorLogger
 
-	return &Consumer{
+	consumer := &Consumer{
 		reader: kafka.NewReader(readerConf),
+		stats:  conf.Stats,
 	}
+	if conf.Stats != nil {
+		consumer.collector = newConsumerCollector(consumer, topic, c.dialer.ClientID, conf.GroupID)
+		if err := conf.Stats.RegisterCollector(consumer.collector); err != nil {
+			consumer.collector = nil
+			return consumer, fmt.Errorf("could not register stats collector: %w", err)
+		}
+	}
+	return consumer, nil
 }
 
 // Close tries to close the consumer, but it will return sooner if the context is canceled.
 // A routine in background will still try to close the producer since the underlying library does not support
 // contexts on Close().
 func (c *Consumer) Close(ctx context.Context) error {
+	if u, ok := c.stats.(stats.CollectorUnregisterer); ok && c.collector != nil {
+		u.UnregisterCollector(c.collector)
+	}
 	done := make(chan error, 1)
 	go func() {
 		done <- c.reader.Close()
@@ -81,7 +126,47 @@ func (c *Consumer) Receive(ctx context.Context) (Message, error) {
 	if err != nil {
 		return Message{}, err
 	}
+	return message(msg), nil
+}
+
+// ReceiveBatch reads up to maxMessages messages from the consumer, returning the ones read once maxMessages is
+// reached or maxWait elapsed, possibly none.
+// If an error occurs, the messages read so far are returned along with it.
+// Unlike Receive, the messages are not committed: once processed, they have to be committed with Ack.
+func (c *Consumer) ReceiveBatch(ctx context.Context, maxMessages int, maxWait time.Duration) ([]Message, error) {
+	if maxMessages < 1 {
+		return nil, fmt.Errorf("maxMessages must be greater than 0, got %d", maxMessages)
+	}
+	waitCtx, cancel := context.WithTimeout(ctx, maxWait)
+	defer cancel()
+
+	messages := make([]Message, 0, maxMessages)
+	for len(messages) < maxMessages {
+		msg, err := c.reader.FetchMessage(waitCtx)
+		if err != nil {
+			if ctx.Err() == nil && errors.Is(err, context.DeadlineExceeded) {
+				break // maxWait elapsed
+			}
+			return messages, err
+		}
+		messages = append(messages, message(msg))
+	}
+	return messages, nil
+}
 
+func (c *Consumer) Ack(ctx context.Context, msgs ...Message) error {
+	internalMsgs := make([]kafka.Message, 0, len(msgs))
+	for _, msg := range msgs {
+		internalMsgs = append(internalMsgs, kafka.Message{
+			Topic:     msg.Topic,
+			Partition: int(msg.Partition),
+			Offset:    msg.Offset,
+		})
+	}
+	return c.reader.CommitMessages(ctx, internalMsgs...)
+}
+
+func message(msg kafka.Message) Message {
 	var headers []MessageHeader
 	if l := len(msg.Headers); l > 0 {
 		headers = make([]MessageHeader, l)
@@ -101,17 +186,5 @@ func (c *Consumer) Receive(ctx context.Context) (Message, error) {
 		Offset:    msg.Offset,
 		Headers:   headers,
 		Timestamp: msg.Time,
-	}, nil
-}
-
-func (c *Consumer) Ack(ctx context.Context, msgs ...Message) error {
-	internalMsgs := make([]kafka.Message, 0, len(msgs))
-	for _, msg := range msgs {
-		internalMsgs = append(internalMsgs, kafka.Message{
-			Topic:     msg.Topic,
-			Partition: int(msg.Partition),
-			Offset:    msg.Offset,
-		})
 	}
-	return c.reader.CommitMessages(ctx, internalMsgs...)
 }
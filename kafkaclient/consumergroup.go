@@ -0,0 +1,334 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// MessageHandler processes a message consumed by a ConsumerGroup
+type MessageHandler func(ctx context.Context, msg Message) error
+
+type ConsumerGroupConfig struct {
+	GroupID string
+	// StartOffset is where partitions without committed offsets are consumed from
+	StartOffset ConsumerStartOffset
+	// CommitInterval is the interval at which processed offsets are committed, defaults to 1 second.
+	// Offsets are also committed when partitions are revoked.
+	CommitInterval      time.Duration
+	FetchBatchesMaxWait time.Duration
+	// BufferSize is the number of messages fetched ahead of processing for each partition, defaults to 100
+	BufferSize int
+	// ReadCommitted makes the consumer skip messages of aborted and ongoing transactions
+	ReadCommitted bool
+	// OnAssigned, if set, is called with the partitions assigned to the member, by topic, before they are consumed
+	OnAssigned func(ctx context.Context, partitions map[string][]int)
+	// OnRevoked, if set, is called with the partitions revoked from the member, by topic, once their processed
+	// offsets are committed
+	OnRevoked   func(ctx context.Context, partitions map[string][]int)
+	Logger      Logger
+	ErrorLogger Logger
+}
+
+func (c *ConsumerGroupConfig) defaults() {
+	if c.CommitInterval < 1 {
+		c.CommitInterval = time.Second
+	}
+	if c.BufferSize < 1 {
+		c.BufferSize = 100
+	}
+}
+
+// ConsumerGroup consumes topics as a member of a consumer group, processing the partitions assigned to it
+// concurrently, with one worker per partition processing its messages in order.
+// Only the offsets of messages processed without gaps are committed, hence messages are delivered at least once.
+type ConsumerGroup struct {
+	client *Client
+	config ConsumerGroupConfig
+	group  *kafka.ConsumerGroup
+}
+
+// NewConsumerGroup instantiates a new consumer group member for the given topics
+func (c *Client) NewConsumerGroup(topics []string, conf ConsumerGroupConfig) (*ConsumerGroup, error) { // skipcq: CRT-P0003
+	conf.defaults()
+
+	groupConf := kafka.ConsumerGroupConfig{
+		ID:          conf.GroupID,
+		Brokers:     c.addresses,
+		Dialer:      c.dialer,
+		Topics:      topics,
+		StartOffset: kafka.FirstOffset,
+		Logger:      conf.Logger,
+		ErrorLogger: conf.ErrorLogger,
+	}
+	if conf.StartOffset == LastOffset {
+		groupConf.StartOffset = kafka.LastOffset
+	}
+	group, err := kafka.NewConsumerGroup(groupConf)
+	if err != nil {
+		return nil, fmt.Errorf("could not create consumer group: %w", err)
+	}
+
+	return &ConsumerGroup{
+		client: c,
+		config: conf,
+		group:  group,
+	}, nil
+}
+
+// Run consumes the assigned partitions, calling the handler for each message, until the context is canceled or
+// the handler returns an error. Messages are redelivered after a rebalance if their offsets couldn't be committed.
+// If the handler returns an error, the message is not committed and Run returns the error.
+// Run also returns an error if the messages of a partition can't be fetched anymore.
+// Run closes the consumer group when returning, hence it can be called only once.
+func (g *ConsumerGroup) Run(ctx context.Context, handler MessageHandler) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	defer func() { _ = g.group.Close() }()
+
+	var (
+		errOnce sync.Once
+		runErr  error
+	)
+	fail := func(err error) {
+		errOnce.Do(func() {
+			runErr = err
+			cancel()
+		})
+	}
+
+	for {
+		gen, err := g.group.Next(ctx)
+		if err != nil {
+			if runErr != nil {
+				return runErr
+			}
+			if errors.Is(err, kafka.ErrGroupClosed) || ctx.Err() != nil {
+				return nil
+			}
+			// the consumer group keeps trying to join
+			if g.config.ErrorLogger != nil {
+				g.config.ErrorLogger.Printf("could not join consumer group %s: %v", g.config.GroupID, err)
+			}
+			continue
+		}
+		g.runGeneration(ctx, gen, handler, fail)
+	}
+}
+
+// Close leaves the consumer group, making Run return
+func (g *ConsumerGroup) Close() error {
+	return g.group.Close()
+}
+
+// runGeneration starts a worker for each assigned partition, and a committer which commits processed offsets
+// periodically and once the generation ends
+func (g *ConsumerGroup) runGeneration(ctx context.Context, gen *kafka.Generation, handler MessageHandler, fail func(error)) {
+	assigned := make(map[string][]int, len(gen.Assignments))
+	trackers := make(map[topicPartition]*offsetTracker)
+	for topic, assignments := range gen.Assignments {
+		for _, a := range assignments {
+			assigned[topic] = append(assigned[topic], a.ID)
+			trackers[topicPartition{topic: topic, partition: a.ID}] = newOffsetTracker()
+		}
+	}
+	if g.config.OnAssigned != nil {
+		g.config.OnAssigned(ctx, assigned)
+	}
+
+	var workers sync.WaitGroup
+	for topic, assignments := range gen.Assignments {
+		for _, a := range assignments {
+			tracker := trackers[topicPartition{topic: topic, partition: a.ID}]
+			workers.Add(1)
+			gen.Start(func(genCtx context.Context) {
+				defer workers.Done()
+				if err := g.consumePartition(mergeDone(genCtx, ctx), topic, a.ID, a.Offset, tracker, handler); err != nil {
+					fail(err)
+				}
+			})
+		}
+	}
+
+	gen.Start(func(genCtx context.Context) {
+		done := mergeDone(genCtx, ctx).Done()
+		ticker := time.NewTicker(g.config.CommitInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				g.commit(gen, trackers)
+			case <-done:
+				workers.Wait()
+				g.commit(gen, trackers)
+				if g.config.OnRevoked != nil {
+					g.config.OnRevoked(context.WithoutCancel(ctx), assigned)
+				}
+				return
+			}
+		}
+	})
+}
+
+// consumePartition fetches the messages of a partition from the given offset, dispatching them in order to a worker
+func (g *ConsumerGroup) consumePartition(
+	ctx context.Context, topic string, partition int, offset int64, tracker *offsetTracker, handler MessageHandler,
+) error {
+	readerConf := kafka.ReaderConfig{
+		Brokers:     g.client.addresses,
+		Dialer:      g.client.dialer,
+		Topic:       topic,
+		Partition:   partition,
+		MaxWait:     g.config.FetchBatchesMaxWait,
+		Logger:      g.config.Logger,
+		ErrorLogger: g.config.ErrorLogger,
+	}
+	if g.config.ReadCommitted {
+		readerConf.IsolationLevel = kafka.ReadCommitted
+	}
+	reader := kafka.NewReader(readerConf)
+	defer func() { _ = reader.Close() }()
+	if err := reader.SetOffset(offset); err != nil {
+		return fmt.Errorf("could not set offset of %s[%d]: %w", topic, partition, err)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	messages := make(chan Message, g.config.BufferSize)
+	var fetchErr error // only read once messages is closed
+	go func() {
+		defer close(messages)
+		for {
+			msg, err := reader.FetchMessage(ctx)
+			if err != nil {
+				if ctx.Err() == nil {
+					fetchErr = fmt.Errorf("fetching messages of %s[%d]: %w", topic, partition, err)
+				}
+				return
+			}
+			tracker.fetched(msg.Offset)
+			select {
+			case messages <- message(msg):
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	for msg := range messages {
+		if ctx.Err() != nil {
+			return nil
+		}
+		if err := handler(ctx, msg); err != nil {
+			if ctx.Err() != nil {
+				return nil // partition revoked while processing, the message will be redelivered
+			}
+			return fmt.Errorf("handling message %s[%d]@%d: %w", msg.Topic, msg.Partition, msg.Offset, err)
+		}
+		tracker.processed(msg.Offset)
+	}
+	// the partition can't be consumed anymore, thus failing instead of silently leaving it behind
+	return fetchErr
+}
+
+// commit commits the offsets processed since the last commit
+func (g *ConsumerGroup) commit(gen *kafka.Generation, trackers map[topicPartition]*offsetTracker) {
+	offsets := make(map[string]map[int]int64)
+	for tp, tracker := range trackers {
+		if offset, ok := tracker.uncommitted(); ok {
+			if offsets[tp.topic] == nil {
+				offsets[tp.topic] = make(map[int]int64)
+			}
+			offsets[tp.topic][tp.partition] = offset
+		}
+	}
+	if len(offsets) == 0 {
+		return
+	}
+	if err := gen.CommitOffsets(offsets); err != nil {
+		if g.config.ErrorLogger != nil {
+			g.config.ErrorLogger.Printf("could not commit offsets of group %s: %v", g.config.GroupID, err)
+		}
+		return
+	}
+	for tp, tracker := range trackers {
+		if offset, ok := offsets[tp.topic][tp.partition]; ok {
+			tracker.committed(offset)
+		}
+	}
+}
+
+// offsetTracker tracks the offsets of the messages fetched from a partition, so that only offsets below which all
+// fetched messages were processed are committed. Offsets may have gaps, e.g. in compacted topics.
+type offsetTracker struct {
+	mu            sync.Mutex
+	pending       []trackedOffset // fetched offsets, in order, not yet part of the processed prefix
+	next          int64           // offset following the last message processed without gaps, -1 if none
+	lastCommitted int64           // -1 if none
+}
+
+type trackedOffset struct {
+	offset    int64
+	processed bool
+}
+
+func newOffsetTracker() *offsetTracker {
+	return &offsetTracker{next: -1, lastCommitted: -1}
+}
+
+// fetched records that a message was fetched. Offsets must be recorded in increasing order.
+func (t *offsetTracker) fetched(offset int64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.pending = append(t.pending, trackedOffset{offset: offset})
+}
+
+// processed records that a fetched message was processed, in any order
+func (t *offsetTracker) processed(offset int64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for i := range t.pending {
+		if t.pending[i].offset == offset {
+			t.pending[i].processed = true
+			break
+		}
+	}
+	i := 0
+	for ; i < len(t.pending) && t.pending[i].processed; i++ {
+		t.next = t.pending[i].offset + 1
+	}
+	t.pending = t.pending[i:]
+}
+
+// uncommitted returns the offset to commit, i.e. the one following the highest offset processed without gaps, if
+// it wasn't committed yet
+func (t *offsetTracker) uncommitted() (int64, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.next < 0 || t.next == t.lastCommitted {
+		return 0, false
+	}
+	return t.next, true
+}
+
+// committed records that the given offset was committed
+func (t *offsetTracker) committed(offset int64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.lastCommitted = offset
+}
+
+// mergeDone returns a context done when either of the given contexts is, carrying the values of the first one
+func mergeDone(ctx, other context.Context) context.Context {
+	merged, cancel := context.WithCancel(ctx)
+	stop := context.AfterFunc(other, cancel)
+	context.AfterFunc(merged, func() {
+		stop()
+		cancel()
+	})
+	return merged
+}
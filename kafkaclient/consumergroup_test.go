@@ -0,0 +1,40 @@
+package client
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestOffsetTracker(t *testing.T) {
+	tracker := newOffsetTracker()
+	_, ok := tracker.uncommitted()
+	require.False(t, ok, "nothing processed yet")
+
+	// offsets with gaps, as in compacted topics
+	for _, offset := range []int64{3, 5, 6, 9} {
+		tracker.fetched(offset)
+	}
+
+	tracker.processed(5)
+	_, ok = tracker.uncommitted()
+	require.False(t, ok, "offset 3 is not processed yet")
+
+	tracker.processed(3)
+	offset, ok := tracker.uncommitted()
+	require.True(t, ok)
+	require.EqualValues(t, 6, offset)
+
+	tracker.committed(6)
+	_, ok = tracker.uncommitted()
+	require.False(t, ok, "already committed")
+
+	tracker.processed(9)
+	_, ok = tracker.uncommitted()
+	require.False(t, ok, "offset 6 is not processed yet")
+
+	tracker.processed(6)
+	offset, ok = tracker.uncommitted()
+	require.True(t, ok)
+	require.EqualValues(t, 10, offset)
+}
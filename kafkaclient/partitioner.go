@@ -0,0 +1,150 @@
+package client
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"slices"
+	"sync"
+
+	"github.com/segmentio/kafka-go"
+
+	"github.com/rudderlabs/rudder-go-kit/partmap"
+)
+
+// Partitioner is the strategy choosing the partition messages are published to
+type Partitioner uint8
+
+const (
+	// PartitionerReferenceHash hashes keys with FNV-1a, compatible with Sarama. Messages without keys are spread
+	// randomly. This is the default partitioner.
+	PartitionerReferenceHash Partitioner = iota
+	// PartitionerMurmur2 hashes keys with murmur2, compatible with the Java client. Messages without keys are spread
+	// randomly.
+	PartitionerMurmur2
+	// PartitionerCRC32 hashes keys with CRC32, compatible with the consistent_random partitioner of librdkafka.
+	// Messages without keys are spread randomly.
+	PartitionerCRC32
+	// PartitionerRoundRobin spreads messages across partitions one after the other, ignoring keys
+	PartitionerRoundRobin
+	// PartitionerLeastBytes sends messages to the partition which received the fewest bytes, ignoring keys
+	PartitionerLeastBytes
+	// PartitionerSticky hashes keys with murmur2, as PartitionerMurmur2, while messages without keys are sent to the
+	// same partition until the batch bytes limit is reached, then to another one, as the Java client does
+	PartitionerSticky
+)
+
+func (p Partitioner) String() string {
+	switch p {
+	case PartitionerReferenceHash:
+		return "reference-hash"
+	case PartitionerMurmur2:
+		return "murmur2"
+	case PartitionerCRC32:
+		return "crc32"
+	case PartitionerRoundRobin:
+		return "round-robin"
+	case PartitionerLeastBytes:
+		return "least-bytes"
+	case PartitionerSticky:
+		return "sticky"
+	default:
+		panic(fmt.Errorf("partitioner out of the known domain %d", p))
+	}
+}
+
+// PartitionerFromString returns the proper Partitioner from its string counterpart
+func PartitionerFromString(s string) (Partitioner, error) {
+	for p := PartitionerReferenceHash; p <= PartitionerSticky; p++ {
+		if p.String() == s {
+			return p, nil
+		}
+	}
+	var p Partitioner
+	return p, fmt.Errorf("partitioner out of the known domain: %s", s)
+}
+
+// PartitionerFunc returns the partition a message is published to, which must be one of the given partitions of its
+// topic, sorted
+type PartitionerFunc func(msg Message, partitions []int) int
+
+// Murmur3Partitioner is a PartitionerFunc mapping keys to partitions as partmap.Murmur3Partition32 does, so that
+// messages are partitioned as per our own partition mappings.
+// The number of partitions of topics must be a power of 2, otherwise the distribution is not uniform.
+func Murmur3Partitioner(msg Message, partitions []int) int {
+	idx, _ := partmap.Murmur3Partition32(string(msg.Key), uint32(len(partitions)))
+	return partitions[min(int(idx), len(partitions)-1)]
+}
+
+// stickyBatchBytes is the default number of bytes after which the sticky partitioner switches partition, as the
+// default batch.size of the Java client
+const stickyBatchBytes = 16 * 1024
+
+// newBalancer returns the balancer of a partitioner, or of the custom partitioner if set
+func newBalancer(partitioner Partitioner, custom PartitionerFunc, batchBytes int64) (kafka.Balancer, error) {
+	if custom != nil {
+		return balancerFunc(custom), nil
+	}
+	switch partitioner {
+	case PartitionerReferenceHash:
+		return &kafka.ReferenceHash{}, nil
+	case PartitionerMurmur2:
+		return kafka.Murmur2Balancer{}, nil
+	case PartitionerCRC32:
+		return kafka.CRC32Balancer{}, nil
+	case PartitionerRoundRobin:
+		return &kafka.RoundRobin{}, nil
+	case PartitionerLeastBytes:
+		return &kafka.LeastBytes{}, nil
+	case PartitionerSticky:
+		if batchBytes < 1 {
+			batchBytes = stickyBatchBytes
+		}
+		return &stickyBalancer{batchBytes: batchBytes, topics: make(map[string]*stickyPartition)}, nil
+	default:
+		return nil, fmt.Errorf("partitioner out of the known domain: %d", partitioner)
+	}
+}
+
+// balancerFunc adapts a PartitionerFunc to a kafka.Balancer
+type balancerFunc PartitionerFunc
+
+func (f balancerFunc) Balance(msg kafka.Message, partitions ...int) int {
+	return f(message(msg), partitions)
+}
+
+// stickyBalancer sends messages with keys to the partitions chosen by murmur2, and messages without keys to a
+// partition of their topic until batchBytes are sent to it, then to another random one
+type stickyBalancer struct {
+	keyed      kafka.Murmur2Balancer
+	batchBytes int64
+
+	mu     sync.Mutex
+	topics map[string]*stickyPartition
+}
+
+type stickyPartition struct {
+	partition int
+	bytes     int64
+}
+
+func (b *stickyBalancer) Balance(msg kafka.Message, partitions ...int) int {
+	if msg.Key != nil {
+		return b.keyed.Balance(msg, partitions...)
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	sticky, ok := b.topics[msg.Topic]
+	if !ok || sticky.bytes >= b.batchBytes || !slices.Contains(partitions, sticky.partition) {
+		next := partitions[rand.IntN(len(partitions))] // skipcq: GSC-G404
+		if ok && len(partitions) > 1 {
+			for next == sticky.partition {
+				next = partitions[rand.IntN(len(partitions))] // skipcq: GSC-G404
+			}
+		}
+		sticky = &stickyPartition{partition: next}
+		b.topics[msg.Topic] = sticky
+	}
+	sticky.bytes += int64(len(msg.Value))
+	return sticky.partition
+}
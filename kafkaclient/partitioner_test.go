@@ -0,0 +1,110 @@
+package client
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rudderlabs/rudder-go-kit/partmap"
+)
+
+func TestPartitioner(t *testing.T) {
+	partitions := []int{0, 1, 2, 3, 4, 5, 6, 7}
+
+	t.Run("from string", func(t *testing.T) {
+		for p := PartitionerReferenceHash; p <= PartitionerSticky; p++ {
+			fromString, err := PartitionerFromString(p.String())
+			require.NoError(t, err)
+			require.Equal(t, p, fromString)
+		}
+		_, err := PartitionerFromString("unknown")
+		require.Error(t, err)
+		_, err = newBalancer(PartitionerSticky+1, nil, 0)
+		require.Error(t, err)
+	})
+
+	t.Run("valid partitions", func(t *testing.T) {
+		for p := PartitionerReferenceHash; p <= PartitionerSticky; p++ {
+			t.Run(p.String(), func(t *testing.T) {
+				balancer, err := newBalancer(p, nil, 0)
+				require.NoError(t, err)
+				for i := range 100 {
+					var key []byte
+					if i%2 == 0 {
+						key = []byte("key-" + strconv.Itoa(i))
+					}
+					msg := kafka.Message{Topic: "some-topic", Key: key, Value: []byte("some-value")}
+					require.Contains(t, partitions, balancer.Balance(msg, partitions...))
+				}
+			})
+		}
+	})
+
+	t.Run("murmur2 is deterministic", func(t *testing.T) {
+		for _, p := range []Partitioner{PartitionerMurmur2, PartitionerSticky} {
+			balancer, err := newBalancer(p, nil, 0)
+			require.NoError(t, err)
+			require.Equal(t, kafka.Murmur2Balancer{}.Balance(kafka.Message{Key: []byte("some-key")}, partitions...),
+				balancer.Balance(kafka.Message{Key: []byte("some-key")}, partitions...),
+			)
+			for range 10 {
+				require.Equal(t,
+					balancer.Balance(kafka.Message{Key: []byte("some-key")}, partitions...),
+					balancer.Balance(kafka.Message{Key: []byte("some-key")}, partitions...),
+				)
+			}
+		}
+	})
+
+	t.Run("sticky", func(t *testing.T) {
+		balancer, err := newBalancer(PartitionerSticky, nil, 100)
+		require.NoError(t, err)
+		msg := kafka.Message{Topic: "some-topic", Value: make([]byte, 30)}
+
+		first := balancer.Balance(msg, partitions...)
+		for range 3 {
+			require.Equal(t, first, balancer.Balance(msg, partitions...))
+		}
+		// the partition is switched once 100 bytes were sent to it
+		second := balancer.Balance(msg, partitions...)
+		require.NotEqual(t, first, second)
+		// or when it isn't available anymore
+		require.NotEqual(t, second, balancer.Balance(msg, first))
+		require.Equal(t, first, balancer.Balance(msg, partitions...))
+	})
+
+	t.Run("custom", func(t *testing.T) {
+		var received Message
+		balancer, err := newBalancer(PartitionerMurmur2, func(msg Message, partitions []int) int {
+			received = msg
+			return partitions[len(partitions)-1]
+		}, 0)
+		require.NoError(t, err)
+		msg := kafka.Message{
+			Topic:   "some-topic",
+			Key:     []byte("some-key"),
+			Value:   []byte("some-value"),
+			Headers: []kafka.Header{{Key: "some-header", Value: []byte("some-header-value")}},
+		}
+		require.Equal(t, 7, balancer.Balance(msg, partitions...))
+		require.Equal(t, "some-topic", received.Topic)
+		require.Equal(t, []byte("some-key"), received.Key)
+		require.Equal(t, []byte("some-value"), received.Value)
+		require.Equal(t, []MessageHeader{{Key: "some-header", Value: []byte("some-header-value")}}, received.Headers)
+	})
+
+	t.Run("murmur3 is compatible with partmap", func(t *testing.T) {
+		for i := range 100 {
+			key := "key-" + strconv.Itoa(i)
+			idx, _ := partmap.Murmur3Partition32(key, uint32(len(partitions)))
+			require.Equal(t, int(idx), Murmur3Partitioner(Message{Key: []byte(key)}, partitions))
+		}
+		require.Equal(t, 3, Murmur3Partitioner(Message{Key: []byte("some-key")}, []int{3}))
+		for i := range 100 {
+			// the index is within range for partition counts that are not a power of 2
+			require.Contains(t, []int{0, 1, 2}, Murmur3Partitioner(Message{Key: []byte("key-" + strconv.Itoa(i))}, []int{0, 1, 2}))
+		}
+	})
+}
@@ -11,6 +11,8 @@ import (
 	"time"
 
 	"github.com/segmentio/kafka-go"
+
+	"github.com/rudderlabs/rudder-go-kit/stats"
 )
 
 type Compression = kafka.Compression
@@ -31,8 +33,15 @@ type ProducerConfig struct {
 	BatchSize   int
 	BatchBytes  int64
 	Compression Compression
-	Logger      Logger
-	ErrorLogger Logger
+	// Partitioner chooses the partition of messages, defaults to PartitionerReferenceHash
+	Partitioner Partitioner
+	// CustomPartitioner, if set, chooses the partition of messages instead of Partitioner, e.g. Murmur3Partitioner
+	CustomPartitioner PartitionerFunc
+	Logger            Logger
+	ErrorLogger       Logger
+	// Stats, if set, is used for registering a collector reporting the producer's metrics, tagged by client ID
+	// (and by topic for per-topic metrics)
+	Stats stats.Stats
 }
 
 func (c *ProducerConfig) defaults() {
@@ -55,24 +64,66 @@ func (c *ProducerConfig) defaults() {
 
 // Producer provides a high-level API for producing messages to Kafka
 type Producer struct {
-	writer *kafka.Writer
-	config ProducerConfig
+	writer    *kafka.Writer
+	config    ProducerConfig
+	collector *producerCollector
 }
 
 // NewProducer instantiates a new producer. To use it asynchronously just do "go p.Publish(ctx, msgs)".
 func (c *Client) NewProducer(producerConf ProducerConfig) (*Producer, error) { // skipcq: CRT-P0003
 	producerConf.defaults()
 
+	balancer, err := newBalancer(producerConf.Partitioner, producerConf.CustomPartitioner, producerConf.BatchBytes)
+	if err != nil {
+		return nil, err
+	}
+	transport, err := c.transport(producerConf.ClientID)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &Producer{
+		config: producerConf,
+		writer: &kafka.Writer{
+			Addr:                   kafka.TCP(c.addresses...),
+			Balancer:               balancer,
+			WriteTimeout:           producerConf.WriteTimeout,
+			ReadTimeout:            producerConf.ReadTimeout,
+			BatchTimeout:           producerConf.BatchTimeout,
+			BatchSize:              producerConf.BatchSize,
+			BatchBytes:             producerConf.BatchBytes,
+			MaxAttempts:            3,
+			RequiredAcks:           kafka.RequireAll,
+			AllowAutoTopicCreation: true,
+			Async:                  false,
+			Compression:            producerConf.Compression,
+			Transport:              transport,
+		},
+	}
+	if producerConf.Stats != nil {
+		p.collector = newProducerCollector(p, transport.ClientID)
+		if err := producerConf.Stats.RegisterCollector(p.collector); err != nil {
+			_ = p.writer.Close()
+			transport.CloseIdleConnections()
+			return nil, fmt.Errorf("could not register stats collector: %w", err)
+		}
+	}
+
+	return p, nil
+}
+
+// transport returns a transport for the given client ID, falling back to the client's one if empty
+func (c *Client) transport(clientID string) (*kafka.Transport, error) {
 	transport := &kafka.Transport{
 		DialTimeout: c.config.DialTimeout,
 		Dial:        c.dialer.DialFunc,
 	}
-	if producerConf.ClientID != "" {
-		transport.ClientID = producerConf.ClientID
+	if clientID != "" {
+		transport.ClientID = clientID
 	} else if c.config.ClientID != "" {
 		transport.ClientID = c.config.ClientID
 	}
-	if c.config.TLS != nil {
+	if c.config.TLS != nil && !c.config.TLS.verifiesDialedHost() { // otherwise the TLS handshake is done by Dial
 		var err error
 		transport.TLS, err = c.config.TLS.build()
 		if err != nil {
@@ -86,31 +137,16 @@ func (c *Client) NewProducer(producerConf ProducerConfig) (*Producer, error) { /
 			return nil, fmt.Errorf("could not build SASL configuration: %w", err)
 		}
 	}
-
-	return &Producer{
-		config: producerConf,
-		writer: &kafka.Writer{
-			Addr:                   kafka.TCP(c.addresses...),
-			Balancer:               &kafka.ReferenceHash{},
-			WriteTimeout:           producerConf.WriteTimeout,
-			ReadTimeout:            producerConf.ReadTimeout,
-			BatchTimeout:           producerConf.BatchTimeout,
-			BatchSize:              producerConf.BatchSize,
-			BatchBytes:             producerConf.BatchBytes,
-			MaxAttempts:            3,
-			RequiredAcks:           kafka.RequireAll,
-			AllowAutoTopicCreation: true,
-			Async:                  false,
-			Compression:            producerConf.Compression,
-			Transport:              transport,
-		},
-	}, nil
+	return transport, nil
 }
 
 // Close tries to close the producer, but it will return sooner if the context is canceled.
 // A routine in background will still try to close the producer since the underlying library does not support
 // contexts on Close().
 func (p *Producer) Close(ctx context.Context) error {
+	if u, ok := p.config.Stats.(stats.CollectorUnregisterer); ok && p.collector != nil {
+		u.UnregisterCollector(p.collector)
+	}
 	done := make(chan error, 1)
 	go func() {
 		if p.writer != nil {
@@ -128,14 +164,17 @@ func (p *Producer) Close(ctx context.Context) error {
 }
 
 // Publish allows the production of one or more message to Kafka.
+// The W3C trace context of ctx, if any, is injected into the messages' headers (see TraceParentHeader), unless they
+// already carry one.
 // To use it asynchronously just do "go p.Publish(ctx, msgs)".
 func (p *Producer) Publish(ctx context.Context, msgs ...Message) error {
+	traceParent := stats.GetTraceParentFromContext(ctx)
 	messages := make([]kafka.Message, len(msgs))
 	for i := range msgs {
 		if msgs[i].Topic == "" {
 			return fmt.Errorf("no topic provided for message %d", i)
 		}
-		headers := headers(msgs[i])
+		headers := headers(msgs[i], traceParent)
 		messages[i] = kafka.Message{
 			Topic:   msgs[i].Topic,
 			Key:     msgs[i].Key,
@@ -145,12 +184,16 @@ func (p *Producer) Publish(ctx context.Context, msgs ...Message) error {
 		}
 	}
 
-	return p.writer.WriteMessages(ctx, messages...)
+	err := p.writer.WriteMessages(ctx, messages...)
+	if p.collector != nil {
+		p.collector.published(messages, err)
+	}
+	return err
 }
 
-func headers(msg Message) (headers []kafka.Header) {
+func headers(msg Message, traceParent string) (headers []kafka.Header) {
 	if l := len(msg.Headers); l > 0 {
-		headers = make([]kafka.Header, l)
+		headers = make([]kafka.Header, l, l+1)
 		for k := range msg.Headers {
 			headers[k] = kafka.Header{
 				Key:   msg.Headers[k].Key,
@@ -158,6 +201,9 @@ func headers(msg Message) (headers []kafka.Header) {
 			}
 		}
 	}
+	if traceParent != "" && msg.TraceParent() == "" {
+		headers = append(headers, kafka.Header{Key: TraceParentHeader, Value: []byte(traceParent)})
+	}
 	return headers
 }
 
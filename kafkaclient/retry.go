@@ -0,0 +1,171 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// Headers set on messages republished by RetryMiddleware
+const (
+	// HeaderRetryAttempt is the number of times processing the message failed
+	HeaderRetryAttempt = "x-retry-attempt"
+	// HeaderRetryAfter is the time, in Unix milliseconds, before which the message must not be processed again
+	HeaderRetryAfter = "x-retry-after"
+	// HeaderOriginalTopic is the topic the message was first published to
+	HeaderOriginalTopic = "x-original-topic"
+	// HeaderOriginalPartition is the partition the message was first published to
+	HeaderOriginalPartition = "x-original-partition"
+	// HeaderOriginalOffset is the offset of the message in the partition it was first published to
+	HeaderOriginalOffset = "x-original-offset"
+	// HeaderFailureReason is the error returned by the last attempt at processing the message
+	HeaderFailureReason = "x-failure-reason"
+)
+
+// Publisher publishes messages, e.g. a Producer or a TransactionalProducer
+type Publisher interface {
+	Publish(ctx context.Context, msgs ...Message) error
+}
+
+// RetryTopic is a topic messages are republished to when processing them fails
+type RetryTopic struct {
+	Topic string
+	// Delay is the time to wait before processing messages again once republished
+	Delay time.Duration
+}
+
+type RetryConfig struct {
+	// RetryTopics is the chain of topics a message goes through as processing it keeps failing, one per attempt
+	RetryTopics []RetryTopic
+	// DLQTopic is the topic messages are published to once retries are exhausted.
+	// If empty, the error of the last attempt is returned by the handler instead.
+	DLQTopic string
+}
+
+// RetryMiddleware returns a middleware republishing the messages which failed to be processed to a chain of retry
+// topics, and eventually to a dead-letter topic, so that poison messages don't block the partitions they belong to.
+//
+// Republished messages keep their key and headers, and carry the attempt count, the original topic, partition and
+// offset, and the failure reason (see the Header constants). Retry topics must be consumed with a handler wrapped
+// with the same middleware, which waits for the messages' delay before processing them.
+//
+// Errors due to the context being canceled are returned as is, so that messages are redelivered. If republishing
+// fails, the error is returned as well.
+func RetryMiddleware(publisher Publisher, conf RetryConfig) func(MessageHandler) MessageHandler {
+	m := &retryMiddleware{publisher: publisher, config: conf, now: time.Now}
+	return m.wrap
+}
+
+type retryMiddleware struct {
+	publisher Publisher
+	config    RetryConfig
+	now       func() time.Time
+}
+
+func (m *retryMiddleware) wrap(next MessageHandler) MessageHandler {
+	return func(ctx context.Context, msg Message) error {
+		if err := m.waitRetryAfter(ctx, msg); err != nil {
+			return err
+		}
+
+		handlerErr := next(ctx, msg)
+		if handlerErr == nil || ctx.Err() != nil {
+			return handlerErr
+		}
+
+		attempt := 1
+		if v, ok := header(msg, HeaderRetryAttempt); ok {
+			if n, err := strconv.Atoi(v); err == nil {
+				attempt = n + 1
+			}
+		}
+
+		republished := Message{
+			Key:       msg.Key,
+			Value:     msg.Value,
+			Timestamp: msg.Timestamp,
+			Headers:   m.retryHeaders(msg, attempt, handlerErr),
+		}
+		if attempt <= len(m.config.RetryTopics) {
+			retryTopic := m.config.RetryTopics[attempt-1]
+			republished.Topic = retryTopic.Topic
+			republished.Headers = setHeader(republished.Headers, HeaderRetryAfter,
+				strconv.FormatInt(m.now().Add(retryTopic.Delay).UnixMilli(), 10))
+		} else if m.config.DLQTopic != "" {
+			republished.Topic = m.config.DLQTopic
+			republished.Headers = deleteHeader(republished.Headers, HeaderRetryAfter)
+		} else {
+			return handlerErr
+		}
+
+		if err := m.publisher.Publish(ctx, republished); err != nil {
+			return errors.Join(handlerErr, fmt.Errorf("republishing message to %s: %w", republished.Topic, err))
+		}
+		return nil
+	}
+}
+
+// waitRetryAfter waits until the message can be processed again, according to its retry-after header
+func (m *retryMiddleware) waitRetryAfter(ctx context.Context, msg Message) error {
+	v, ok := header(msg, HeaderRetryAfter)
+	if !ok {
+		return nil
+	}
+	retryAfter, err := strconv.ParseInt(v, 10, 64)
+	if err != nil {
+		return nil // not set by the middleware, ignoring it
+	}
+	wait := time.UnixMilli(retryAfter).Sub(m.now())
+	if wait <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// retryHeaders returns the headers of the message to republish, keeping the original ones
+func (*retryMiddleware) retryHeaders(msg Message, attempt int, handlerErr error) []MessageHeader {
+	headers := make([]MessageHeader, len(msg.Headers), len(msg.Headers)+6)
+	copy(headers, msg.Headers)
+	if _, ok := header(msg, HeaderOriginalTopic); !ok {
+		headers = setHeader(headers, HeaderOriginalTopic, msg.Topic)
+		headers = setHeader(headers, HeaderOriginalPartition, strconv.Itoa(int(msg.Partition)))
+		headers = setHeader(headers, HeaderOriginalOffset, strconv.FormatInt(msg.Offset, 10))
+	}
+	headers = setHeader(headers, HeaderRetryAttempt, strconv.Itoa(attempt))
+	headers = setHeader(headers, HeaderFailureReason, handlerErr.Error())
+	return headers
+}
+
+// header returns the value of the last header of the message with the given key
+func header(msg Message, key string) (string, bool) {
+	for i := len(msg.Headers) - 1; i >= 0; i-- {
+		if msg.Headers[i].Key == key {
+			return string(msg.Headers[i].Value), true
+		}
+	}
+	return "", false
+}
+
+// setHeader sets the value of a header, replacing the existing ones with the same key
+func setHeader(headers []MessageHeader, key, value string) []MessageHeader {
+	return append(deleteHeader(headers, key), MessageHeader{Key: key, Value: []byte(value)})
+}
+
+func deleteHeader(headers []MessageHeader, key string) []MessageHeader {
+	filtered := headers[:0]
+	for _, h := range headers {
+		if h.Key != key {
+			filtered = append(filtered, h)
+		}
+	}
+	return filtered
+}
@@ -0,0 +1,137 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type fakePublisher struct {
+	published []Message
+	err       error
+}
+
+func (p *fakePublisher) Publish(_ context.Context, msgs ...Message) error {
+	if p.err != nil {
+		return p.err
+	}
+	p.published = append(p.published, msgs...)
+	return nil
+}
+
+func TestRetryMiddleware(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	conf := RetryConfig{
+		RetryTopics: []RetryTopic{
+			{Topic: "retry-1", Delay: time.Second},
+			{Topic: "retry-2", Delay: time.Minute},
+		},
+		DLQTopic: "dlq",
+	}
+	newHandler := func(publisher Publisher, conf RetryConfig, handler MessageHandler) MessageHandler {
+		m := &retryMiddleware{publisher: publisher, config: conf, now: func() time.Time { return now }}
+		return m.wrap(handler)
+	}
+	handlerErr := errors.New("some error")
+	failing := func(context.Context, Message) error { return handlerErr }
+	msg := Message{
+		Key:       []byte("key"),
+		Value:     []byte("value"),
+		Topic:     "topic",
+		Partition: 2,
+		Offset:    42,
+		Headers:   []MessageHeader{{Key: "custom", Value: []byte("header")}},
+	}
+
+	t.Run("success", func(t *testing.T) {
+		publisher := &fakePublisher{}
+		require.NoError(t, newHandler(publisher, conf, func(context.Context, Message) error { return nil })(context.Background(), msg))
+		require.Empty(t, publisher.published)
+	})
+
+	t.Run("retry topics chain then DLQ", func(t *testing.T) {
+		publisher := &fakePublisher{}
+		handler := newHandler(publisher, conf, failing)
+
+		require.NoError(t, handler(context.Background(), msg))
+		require.Len(t, publisher.published, 1)
+		retry1 := publisher.published[0]
+		require.Equal(t, "retry-1", retry1.Topic)
+		require.Equal(t, msg.Key, retry1.Key)
+		require.Equal(t, msg.Value, retry1.Value)
+		requireHeader(t, retry1, "custom", "header")
+		requireHeader(t, retry1, HeaderRetryAttempt, "1")
+		requireHeader(t, retry1, HeaderRetryAfter, strconv.FormatInt(now.Add(time.Second).UnixMilli(), 10))
+		requireHeader(t, retry1, HeaderOriginalTopic, "topic")
+		requireHeader(t, retry1, HeaderOriginalPartition, "2")
+		requireHeader(t, retry1, HeaderOriginalOffset, "42")
+		requireHeader(t, retry1, HeaderFailureReason, "some error")
+
+		// the delay is elapsed once consumed from the retry topic
+		now = now.Add(time.Second)
+		retry1.Topic, retry1.Partition, retry1.Offset = "retry-1", 0, 7
+		require.NoError(t, handler(context.Background(), retry1))
+		require.Len(t, publisher.published, 2)
+		retry2 := publisher.published[1]
+		require.Equal(t, "retry-2", retry2.Topic)
+		requireHeader(t, retry2, HeaderRetryAttempt, "2")
+		requireHeader(t, retry2, HeaderRetryAfter, strconv.FormatInt(now.Add(time.Minute).UnixMilli(), 10))
+		requireHeader(t, retry2, HeaderOriginalTopic, "topic")
+		requireHeader(t, retry2, HeaderOriginalOffset, "42")
+
+		now = now.Add(time.Minute)
+		require.NoError(t, handler(context.Background(), retry2))
+		require.Len(t, publisher.published, 3)
+		dlq := publisher.published[2]
+		require.Equal(t, "dlq", dlq.Topic)
+		requireHeader(t, dlq, HeaderRetryAttempt, "3")
+		requireHeader(t, dlq, "custom", "header")
+		_, ok := header(dlq, HeaderRetryAfter)
+		require.False(t, ok)
+	})
+
+	t.Run("waiting for the delay", func(t *testing.T) {
+		publisher := &fakePublisher{}
+		handler := newHandler(publisher, conf, func(context.Context, Message) error { return nil })
+		delayed := msg
+		delayed.Headers = []MessageHeader{{Key: HeaderRetryAfter, Value: []byte(strconv.FormatInt(now.Add(time.Hour).UnixMilli(), 10))}}
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		require.ErrorIs(t, handler(ctx, delayed), context.DeadlineExceeded)
+	})
+
+	t.Run("no DLQ", func(t *testing.T) {
+		publisher := &fakePublisher{}
+		handler := newHandler(publisher, RetryConfig{}, failing)
+		require.ErrorIs(t, handler(context.Background(), msg), handlerErr)
+		require.Empty(t, publisher.published)
+	})
+
+	t.Run("publish error", func(t *testing.T) {
+		publishErr := errors.New("publish error")
+		handler := newHandler(&fakePublisher{err: publishErr}, conf, failing)
+		err := handler(context.Background(), msg)
+		require.ErrorIs(t, err, handlerErr)
+		require.ErrorIs(t, err, publishErr)
+	})
+
+	t.Run("canceled context", func(t *testing.T) {
+		publisher := &fakePublisher{}
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		handler := newHandler(publisher, conf, func(ctx context.Context, _ Message) error { return ctx.Err() })
+		require.ErrorIs(t, handler(ctx, msg), context.Canceled)
+		require.Empty(t, publisher.published)
+	})
+}
+
+func requireHeader(t *testing.T, msg Message, key, value string) {
+	t.Helper()
+	v, ok := header(msg, key)
+	require.True(t, ok, "missing header %q", key)
+	require.Equal(t, value, v)
+}
@@ -0,0 +1,222 @@
+package client
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+	"github.com/segmentio/kafka-go/sasl"
+	"golang.org/x/oauth2"
+
+	"github.com/rudderlabs/rudder-go-kit/awsutil"
+)
+
+// OAuthBearer configures the OAUTHBEARER SASL mechanism (KIP-255)
+type OAuthBearer struct {
+	// TokenSource provides the tokens, e.g. the one of a clientcredentials.Config for OIDC-secured clusters
+	TokenSource oauth2.TokenSource
+	// RefreshBefore is how long before their expiry tokens are refreshed, defaults to 1 minute.
+	// Tokens are required only when connecting to brokers.
+	RefreshBefore time.Duration
+	// Extensions are the SASL extensions sent along with the token, e.g. logicalCluster and identityPoolId for
+	// Confluent Cloud
+	Extensions map[string]string
+}
+
+func (c *OAuthBearer) build() (sasl.Mechanism, error) {
+	if c.TokenSource == nil {
+		return nil, fmt.Errorf("invalid OAUTHBEARER configuration, a token source is required")
+	}
+	for key := range c.Extensions {
+		if key == "auth" || !isValidSASLExtensionKey(key) {
+			return nil, fmt.Errorf("invalid OAUTHBEARER extension key %q", key)
+		}
+	}
+	refreshBefore := c.RefreshBefore
+	if refreshBefore < 1 {
+		refreshBefore = time.Minute
+	}
+	return &oauthBearerMechanism{
+		tokenSource: oauth2.ReuseTokenSourceWithExpiry(nil, c.TokenSource, refreshBefore),
+		extensions:  c.Extensions,
+	}, nil
+}
+
+type oauthBearerMechanism struct {
+	tokenSource oauth2.TokenSource
+	extensions  map[string]string
+}
+
+func (*oauthBearerMechanism) Name() string { return "OAUTHBEARER" }
+
+func (m *oauthBearerMechanism) Start(context.Context) (sasl.StateMachine, []byte, error) {
+	token, err := m.tokenSource.Token()
+	if err != nil {
+		return nil, nil, fmt.Errorf("getting OAuth token: %w", err)
+	}
+	if token.AccessToken == "" {
+		return nil, nil, fmt.Errorf("getting OAuth token: empty access token")
+	}
+
+	// client initial response as per RFC 7628, section 3.1, with extensions sorted for determinism
+	var b strings.Builder
+	b.WriteString("n,,\x01auth=Bearer ")
+	b.WriteString(token.AccessToken)
+	b.WriteString("\x01")
+	keys := make([]string, 0, len(m.extensions))
+	for key := range m.extensions {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	for _, key := range keys {
+		b.WriteString(key + "=" + m.extensions[key] + "\x01")
+	}
+	b.WriteString("\x01")
+	return m, []byte(b.String()), nil
+}
+
+func (*oauthBearerMechanism) Next(_ context.Context, challenge []byte) (bool, []byte, error) {
+	// the broker answers with an error message if the token is rejected
+	if len(challenge) > 0 {
+		return false, nil, fmt.Errorf("OAUTHBEARER authentication failed: %s", challenge)
+	}
+	return true, nil, nil
+}
+
+// isValidSASLExtensionKey reports whether the key is made of letters only, as per RFC 7628
+func isValidSASLExtensionKey(key string) bool {
+	if key == "" {
+		return false
+	}
+	for _, r := range key {
+		if (r < 'a' || r > 'z') && (r < 'A' || r > 'Z') {
+			return false
+		}
+	}
+	return true
+}
+
+// AWSMSKIAM configures the AWS_MSK_IAM SASL mechanism, authenticating to Amazon MSK clusters with IAM credentials
+type AWSMSKIAM struct {
+	// SessionConfig is used for loading the credentials, with awsutil.CreateAWSConfig. The region is required.
+	SessionConfig *awsutil.SessionConfig
+	// UserAgent defaults to rudder-go-kit
+	UserAgent string
+}
+
+func (c *AWSMSKIAM) build() (sasl.Mechanism, error) {
+	if c.SessionConfig == nil {
+		return nil, fmt.Errorf("invalid AWS_MSK_IAM configuration, a session config is required")
+	}
+	awsConfig, err := awsutil.CreateAWSConfig(context.Background(), c.SessionConfig)
+	if err != nil {
+		return nil, fmt.Errorf("could not create AWS config: %w", err)
+	}
+	if awsConfig.Region == "" {
+		return nil, fmt.Errorf("invalid AWS_MSK_IAM configuration, a region is required")
+	}
+	if awsConfig.Credentials == nil {
+		return nil, fmt.Errorf("invalid AWS_MSK_IAM configuration, no credentials found")
+	}
+	userAgent := c.UserAgent
+	if userAgent == "" {
+		userAgent = "rudder-go-kit"
+	}
+	return &awsMSKIAMMechanism{
+		credentials: awsConfig.Credentials,
+		region:      awsConfig.Region,
+		userAgent:   userAgent,
+		signer:      v4.NewSigner(),
+		now:         time.Now,
+	}, nil
+}
+
+const (
+	awsMSKIAMVersion = "2020_10_22"
+	awsMSKIAMService = "kafka-cluster"
+	awsMSKIAMAction  = "kafka-cluster:Connect"
+	// awsMSKIAMExpiry is how long the signed payload is valid for
+	awsMSKIAMExpiry = 5 * time.Minute
+)
+
+// emptyPayloadHash is the hex encoded SHA-256 of an empty payload
+var emptyPayloadHash = func() string {
+	sum := sha256.Sum256(nil)
+	return hex.EncodeToString(sum[:])
+}()
+
+type awsMSKIAMMechanism struct {
+	credentials aws.CredentialsProvider
+	region      string
+	userAgent   string
+	signer      *v4.Signer
+	now         func() time.Time
+}
+
+func (*awsMSKIAMMechanism) Name() string { return "AWS_MSK_IAM" }
+
+// Start sends the payload of a request to connect to the broker, presigned with the AWS Signature Version 4
+func (m *awsMSKIAMMechanism) Start(ctx context.Context) (sasl.StateMachine, []byte, error) {
+	metadata := sasl.MetadataFromContext(ctx)
+	if metadata == nil {
+		return nil, nil, fmt.Errorf("AWS_MSK_IAM authentication requires the broker address")
+	}
+	credentials, err := m.credentials.Retrieve(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("retrieving AWS credentials: %w", err)
+	}
+
+	query := url.Values{
+		"Action":        {awsMSKIAMAction},
+		"X-Amz-Expires": {strconv.Itoa(int(awsMSKIAMExpiry.Seconds()))},
+	}
+	u := url.URL{Scheme: "kafka", Host: metadata.Host, Path: "/", RawQuery: query.Encode()}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("creating AWS_MSK_IAM request: %w", err)
+	}
+	signedURL, signedHeaders, err := m.signer.PresignHTTP(
+		ctx, credentials, req, emptyPayloadHash, awsMSKIAMService, m.region, m.now().UTC(),
+	)
+	if err != nil {
+		return nil, nil, fmt.Errorf("signing AWS_MSK_IAM request: %w", err)
+	}
+	signed, err := url.Parse(signedURL)
+	if err != nil {
+		return nil, nil, fmt.Errorf("parsing signed AWS_MSK_IAM request: %w", err)
+	}
+
+	// the payload is made of the signed headers and query parameters, with lowercase keys
+	payload := map[string]string{
+		"version":    awsMSKIAMVersion,
+		"host":       signed.Host,
+		"user-agent": m.userAgent,
+		"action":     awsMSKIAMAction,
+	}
+	for key, values := range signedHeaders {
+		payload[strings.ToLower(key)] = values[0]
+	}
+	for key, values := range signed.Query() {
+		payload[strings.ToLower(key)] = values[0]
+	}
+	b, err := json.Marshal(payload)
+	if err != nil {
+		return nil, nil, fmt.Errorf("encoding AWS_MSK_IAM payload: %w", err)
+	}
+	return m, b, nil
+}
+
+func (*awsMSKIAMMechanism) Next(context.Context, []byte) (bool, []byte, error) {
+	// the broker answers with the version and request ID of the authentication, nothing else is expected
+	return true, nil, nil
+}
@@ -0,0 +1,163 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/segmentio/kafka-go/sasl"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/oauth2"
+
+	"github.com/rudderlabs/rudder-go-kit/awsutil"
+)
+
+type countingTokenSource struct {
+	calls    atomic.Int64
+	expiring time.Duration
+}
+
+func (s *countingTokenSource) Token() (*oauth2.Token, error) {
+	s.calls.Add(1)
+	return &oauth2.Token{AccessToken: "some-token", Expiry: time.Now().Add(s.expiring)}, nil
+}
+
+func TestOAuthBearer(t *testing.T) {
+	t.Run("initial response", func(t *testing.T) {
+		mechanism, err := (&SASL{OAuthBearer: &OAuthBearer{
+			TokenSource: oauth2.StaticTokenSource(&oauth2.Token{AccessToken: "some-token"}),
+			Extensions:  map[string]string{"logicalCluster": "lkc-123", "identityPoolId": "pool-456"},
+		}}).build()
+		require.NoError(t, err)
+		require.Equal(t, "OAUTHBEARER", mechanism.Name())
+
+		stateMachine, initial, err := mechanism.Start(context.Background())
+		require.NoError(t, err)
+		require.Equal(t,
+			"n,,\x01auth=Bearer some-token\x01identityPoolId=pool-456\x01logicalCluster=lkc-123\x01\x01",
+			string(initial),
+		)
+
+		done, response, err := stateMachine.Next(context.Background(), nil)
+		require.NoError(t, err)
+		require.True(t, done)
+		require.Nil(t, response)
+
+		_, _, err = stateMachine.Next(context.Background(), []byte(`{"status":"invalid_token"}`))
+		require.ErrorContains(t, err, "invalid_token")
+	})
+
+	t.Run("tokens refreshed before expiry", func(t *testing.T) {
+		for _, tc := range []struct {
+			name          string
+			expiring      time.Duration
+			expectedCalls int64
+		}{
+			{name: "valid", expiring: time.Hour, expectedCalls: 1},
+			{name: "about to expire", expiring: 30 * time.Second, expectedCalls: 3},
+		} {
+			t.Run(tc.name, func(t *testing.T) {
+				source := &countingTokenSource{expiring: tc.expiring}
+				mechanism, err := (&OAuthBearer{TokenSource: source, RefreshBefore: time.Minute}).build()
+				require.NoError(t, err)
+				for range 3 {
+					_, _, err := mechanism.Start(context.Background())
+					require.NoError(t, err)
+				}
+				require.Equal(t, tc.expectedCalls, source.calls.Load())
+			})
+		}
+	})
+
+	t.Run("token error", func(t *testing.T) {
+		mechanism, err := (&OAuthBearer{TokenSource: errorTokenSource{}}).build()
+		require.NoError(t, err)
+		_, _, err = mechanism.Start(context.Background())
+		require.ErrorContains(t, err, "some token error")
+	})
+
+	t.Run("invalid configuration", func(t *testing.T) {
+		_, err := (&OAuthBearer{}).build()
+		require.Error(t, err)
+
+		_, err = (&OAuthBearer{
+			TokenSource: oauth2.StaticTokenSource(&oauth2.Token{AccessToken: "some-token"}),
+			Extensions:  map[string]string{"auth": "value"},
+		}).build()
+		require.ErrorContains(t, err, `invalid OAUTHBEARER extension key "auth"`)
+
+		_, err = (&SASL{OAuthBearer: &OAuthBearer{}, AWSMSKIAM: &AWSMSKIAM{}}).build()
+		require.ErrorContains(t, err, "either provide OAuthBearer or AWSMSKIAM")
+	})
+}
+
+type errorTokenSource struct{}
+
+func (errorTokenSource) Token() (*oauth2.Token, error) { return nil, errors.New("some token error") }
+
+func TestAWSMSKIAM(t *testing.T) {
+	t.Setenv("AWS_CONFIG_FILE", "/dev/null")
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", "/dev/null")
+	t.Setenv("AWS_CA_BUNDLE", "")
+
+	t.Run("signed payload", func(t *testing.T) {
+		mechanism, err := (&SASL{AWSMSKIAM: &AWSMSKIAM{SessionConfig: &awsutil.SessionConfig{
+			Region:      "us-east-1",
+			AccessKeyID: "some-access-key-id",
+			AccessKey:   "some-secret-access-key",
+		}}}).build()
+		require.NoError(t, err)
+		require.Equal(t, "AWS_MSK_IAM", mechanism.Name())
+		mechanism.(*awsMSKIAMMechanism).now = func() time.Time {
+			return time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+		}
+
+		ctx := sasl.WithMetadata(context.Background(), &sasl.Metadata{Host: "b-1.msk.example.com", Port: 9098})
+		stateMachine, initial, err := mechanism.Start(ctx)
+		require.NoError(t, err)
+
+		var payload map[string]string
+		require.NoError(t, json.Unmarshal(initial, &payload))
+		require.Equal(t, "2020_10_22", payload["version"])
+		require.Equal(t, "b-1.msk.example.com", payload["host"])
+		require.Equal(t, "rudder-go-kit", payload["user-agent"])
+		require.Equal(t, "kafka-cluster:Connect", payload["action"])
+		require.Equal(t, "AWS4-HMAC-SHA256", payload["x-amz-algorithm"])
+		require.Equal(t, "some-access-key-id/20240102/us-east-1/kafka-cluster/aws4_request", payload["x-amz-credential"])
+		require.Equal(t, "20240102T030405Z", payload["x-amz-date"])
+		require.Equal(t, "300", payload["x-amz-expires"])
+		require.Equal(t, "host", payload["x-amz-signedheaders"])
+		require.Len(t, payload["x-amz-signature"], 64)
+
+		done, _, err := stateMachine.Next(ctx, []byte(`{"version":"2020_10_22","request-id":"some-id"}`))
+		require.NoError(t, err)
+		require.True(t, done)
+	})
+
+	t.Run("missing broker metadata", func(t *testing.T) {
+		mechanism, err := (&AWSMSKIAM{SessionConfig: &awsutil.SessionConfig{
+			Region:      "us-east-1",
+			AccessKeyID: "some-access-key-id",
+			AccessKey:   "some-secret-access-key",
+		}}).build()
+		require.NoError(t, err)
+		_, _, err = mechanism.Start(context.Background())
+		require.Error(t, err)
+	})
+
+	t.Run("invalid configuration", func(t *testing.T) {
+		_, err := (&AWSMSKIAM{}).build()
+		require.ErrorContains(t, err, "a session config is required")
+
+		t.Setenv("AWS_REGION", "")
+		t.Setenv("AWS_DEFAULT_REGION", "")
+		_, err = (&AWSMSKIAM{SessionConfig: &awsutil.SessionConfig{
+			AccessKeyID: "some-access-key-id",
+			AccessKey:   "some-secret-access-key",
+		}}).build()
+		require.ErrorContains(t, err, "a region is required")
+	})
+}
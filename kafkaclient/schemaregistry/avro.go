@@ -0,0 +1,51 @@
+package schemaregistry
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/hamba/avro/v2"
+)
+
+// NewAvroSerializer returns a serializer encoding values with the given Avro schema.
+// Values are encoded as by github.com/hamba/avro/v2, e.g. records from structs with avro field tags or from
+// map[string]any.
+func NewAvroSerializer[T any](client *Client, schema string, conf SerializerConfig) (*Serializer[T], error) {
+	parsed, err := avro.ParseWithCache(schema, "", &avro.SchemaCache{})
+	if err != nil {
+		return nil, fmt.Errorf("parsing Avro schema: %w", err)
+	}
+	return newSerializer(client, conf, Schema{Schema: schema, Type: Avro}, func(v T) ([]byte, error) {
+		return avro.Marshal(parsed, v)
+	}), nil
+}
+
+// NewAvroDeserializer returns a deserializer decoding values with the Avro schema they were serialized with.
+// Fields of the schema missing from T are skipped. Schemas with references are not supported.
+func NewAvroDeserializer[T any](client *Client) *Deserializer[T] {
+	var (
+		mu     sync.Mutex
+		parsed = make(map[int]avro.Schema)
+	)
+	return &Deserializer[T]{
+		client: client,
+		decode: func(schema Schema, id int, payload []byte) (v T, err error) {
+			if err := checkType(schema, Avro); err != nil {
+				return v, err
+			}
+			mu.Lock()
+			writerSchema, ok := parsed[id]
+			if !ok {
+				writerSchema, err = avro.ParseWithCache(schema.Schema, "", &avro.SchemaCache{})
+				if err != nil {
+					mu.Unlock()
+					return v, fmt.Errorf("parsing Avro schema: %w", err)
+				}
+				parsed[id] = writerSchema
+			}
+			mu.Unlock()
+			err = avro.Unmarshal(writerSchema, payload, &v)
+			return v, err
+		},
+	}
+}
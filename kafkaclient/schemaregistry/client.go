@@ -0,0 +1,244 @@
+// Package schemaregistry provides typed serializers and deserializers of Kafka messages, backed by a
+// Confluent-compatible schema registry, for Avro, Protobuf and JSON Schema.
+//
+// Serialized messages follow the Confluent wire format: a magic byte, the 4-byte big-endian ID of the schema in the
+// registry, then the encoded payload.
+package schemaregistry
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SchemaType is the type of a schema, as named by the schema registry
+type SchemaType string
+
+const (
+	Avro       SchemaType = "AVRO"
+	Protobuf   SchemaType = "PROTOBUF"
+	JSONSchema SchemaType = "JSON"
+)
+
+// Reference is a reference of a schema to another one registered under a subject, e.g. a Protobuf import
+type Reference struct {
+	Name    string `json:"name"`
+	Subject string `json:"subject"`
+	Version int    `json:"version"`
+}
+
+// Schema is a schema as stored in the schema registry
+type Schema struct {
+	Schema string `json:"schema"`
+	// Type defaults to Avro if empty
+	Type       SchemaType  `json:"schemaType,omitempty"`
+	References []Reference `json:"references,omitempty"`
+}
+
+// Error is returned when the schema registry responds with an error
+type Error struct {
+	StatusCode int
+	// Code is the error code of the schema registry, e.g. 40401 if the subject is not found
+	Code    int    `json:"error_code"`
+	Message string `json:"message"`
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("schema registry error %d (HTTP %d): %s", e.Code, e.StatusCode, e.Message)
+}
+
+type Config struct {
+	// URL is the base URL of the schema registry, e.g. http://localhost:8081
+	URL                string
+	Username, Password string
+	// Timeout of the requests to the schema registry, defaults to 10 seconds. Ignored if HTTPClient is set.
+	Timeout    time.Duration
+	HTTPClient *http.Client
+}
+
+func (c *Config) defaults() {
+	if c.Timeout < 1 {
+		c.Timeout = 10 * time.Second
+	}
+	if c.HTTPClient == nil {
+		c.HTTPClient = &http.Client{Timeout: c.Timeout}
+	}
+}
+
+// Client is a schema registry client, caching schemas and their IDs
+type Client struct {
+	url    string
+	config Config
+
+	mu       sync.RWMutex
+	ids      map[subjectSchema]int
+	versions map[subjectSchema]int
+	schemas  map[int]Schema
+}
+
+type subjectSchema struct {
+	subject string
+	schema  string // JSON encoded Schema
+}
+
+// NewClient returns a new schema registry client
+func NewClient(conf Config) (*Client, error) { // skipcq: CRT-P0003
+	conf.defaults()
+	u, err := url.Parse(conf.URL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid schema registry URL %q: %w", conf.URL, err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("invalid schema registry URL %q: scheme must be http or https", conf.URL)
+	}
+	return &Client{
+		url:      strings.TrimSuffix(conf.URL, "/"),
+		config:   conf,
+		ids:      make(map[subjectSchema]int),
+		versions: make(map[subjectSchema]int),
+		schemas:  make(map[int]Schema),
+	}, nil
+}
+
+// Register registers a schema under a subject, returning its ID.
+// Registering a schema already registered under the subject returns its existing ID.
+func (c *Client) Register(ctx context.Context, subject string, schema Schema) (int, error) {
+	key, err := c.key(subject, schema)
+	if err != nil {
+		return 0, err
+	}
+	c.mu.RLock()
+	id, ok := c.ids[key]
+	c.mu.RUnlock()
+	if ok {
+		return id, nil
+	}
+
+	var res struct {
+		ID int `json:"id"`
+	}
+	if err := c.do(ctx, http.MethodPost, "/subjects/"+url.PathEscape(subject)+"/versions", schema, &res); err != nil {
+		return 0, fmt.Errorf("registering schema under subject %q: %w", subject, err)
+	}
+	c.cache(key, res.ID, 0, schema)
+	return res.ID, nil
+}
+
+// Lookup returns the ID and the version of a schema already registered under a subject
+func (c *Client) Lookup(ctx context.Context, subject string, schema Schema) (id, version int, err error) {
+	key, err := c.key(subject, schema)
+	if err != nil {
+		return 0, 0, err
+	}
+	c.mu.RLock()
+	id, okID := c.ids[key]
+	version, okVersion := c.versions[key]
+	c.mu.RUnlock()
+	if okID && okVersion {
+		return id, version, nil
+	}
+
+	var res struct {
+		ID      int `json:"id"`
+		Version int `json:"version"`
+	}
+	if err := c.do(ctx, http.MethodPost, "/subjects/"+url.PathEscape(subject), schema, &res); err != nil {
+		return 0, 0, fmt.Errorf("looking up schema under subject %q: %w", subject, err)
+	}
+	c.cache(key, res.ID, res.Version, schema)
+	return res.ID, res.Version, nil
+}
+
+// SchemaByID returns the schema with the given ID
+func (c *Client) SchemaByID(ctx context.Context, id int) (Schema, error) {
+	c.mu.RLock()
+	schema, ok := c.schemas[id]
+	c.mu.RUnlock()
+	if ok {
+		return schema, nil
+	}
+
+	if err := c.do(ctx, http.MethodGet, "/schemas/ids/"+strconv.Itoa(id), nil, &schema); err != nil {
+		return Schema{}, fmt.Errorf("getting schema %d: %w", id, err)
+	}
+	if schema.Type == "" {
+		schema.Type = Avro
+	}
+	c.mu.Lock()
+	c.schemas[id] = schema
+	c.mu.Unlock()
+	return schema, nil
+}
+
+func (*Client) key(subject string, schema Schema) (subjectSchema, error) {
+	if schema.Type == Avro {
+		schema.Type = "" // the registry omits the type of Avro schemas
+	}
+	b, err := json.Marshal(schema)
+	if err != nil {
+		return subjectSchema{}, fmt.Errorf("encoding schema: %w", err)
+	}
+	return subjectSchema{subject: subject, schema: string(b)}, nil
+}
+
+func (c *Client) cache(key subjectSchema, id, version int, schema Schema) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.ids[key] = id
+	if version > 0 {
+		c.versions[key] = version
+	}
+	if schema.Type == "" {
+		schema.Type = Avro
+	}
+	c.schemas[id] = schema
+}
+
+func (c *Client) do(ctx context.Context, method, path string, body, result any) error {
+	var reqBody io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("encoding request: %w", err)
+		}
+		reqBody = bytes.NewReader(b)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.url+path, reqBody)
+	if err != nil {
+		return fmt.Errorf("creating request: %w", err)
+	}
+	req.Header.Set("Accept", "application/vnd.schemaregistry.v1+json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/vnd.schemaregistry.v1+json")
+	}
+	if c.config.Username != "" || c.config.Password != "" {
+		req.SetBasicAuth(c.config.Username, c.config.Password)
+	}
+
+	res, err := c.config.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = res.Body.Close() }()
+
+	if res.StatusCode != http.StatusOK {
+		regErr := &Error{StatusCode: res.StatusCode}
+		b, _ := io.ReadAll(io.LimitReader(res.Body, 1<<16))
+		if err := json.Unmarshal(b, regErr); err != nil || regErr.Message == "" {
+			regErr.Message = string(b)
+		}
+		return regErr
+	}
+	if err := json.NewDecoder(res.Body).Decode(result); err != nil {
+		return fmt.Errorf("decoding response: %w", err)
+	}
+	return nil
+}
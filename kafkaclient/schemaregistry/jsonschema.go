@@ -0,0 +1,49 @@
+package schemaregistry
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/xeipuuv/gojsonschema"
+)
+
+// NewJSONSchemaSerializer returns a serializer encoding values as JSON, validating them against the given JSON Schema
+func NewJSONSchemaSerializer[T any](client *Client, schema string, conf SerializerConfig) (*Serializer[T], error) {
+	compiled, err := gojsonschema.NewSchema(gojsonschema.NewStringLoader(schema))
+	if err != nil {
+		return nil, fmt.Errorf("parsing JSON Schema: %w", err)
+	}
+	return newSerializer(client, conf, Schema{Schema: schema, Type: JSONSchema}, func(v T) ([]byte, error) {
+		data, err := json.Marshal(v)
+		if err != nil {
+			return nil, err
+		}
+		result, err := compiled.Validate(gojsonschema.NewBytesLoader(data))
+		if err != nil {
+			return nil, fmt.Errorf("validating against JSON Schema: %w", err)
+		}
+		if !result.Valid() {
+			violations := make([]string, len(result.Errors()))
+			for i, e := range result.Errors() {
+				violations[i] = e.String()
+			}
+			return nil, fmt.Errorf("invalid against JSON Schema: %s", strings.Join(violations, "; "))
+		}
+		return data, nil
+	}), nil
+}
+
+// NewJSONSchemaDeserializer returns a deserializer decoding JSON values serialized with a JSON Schema
+func NewJSONSchemaDeserializer[T any](client *Client) *Deserializer[T] {
+	return &Deserializer[T]{
+		client: client,
+		decode: func(schema Schema, _ int, payload []byte) (v T, err error) {
+			if err := checkType(schema, JSONSchema); err != nil {
+				return v, err
+			}
+			err = json.Unmarshal(payload, &v)
+			return v, err
+		},
+	}
+}
@@ -0,0 +1,156 @@
+package schemaregistry
+
+import (
+	"encoding/binary"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/jhump/protoreflect/desc"
+	"github.com/jhump/protoreflect/desc/protoprint"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// NewProtobufSerializer returns a serializer encoding Protobuf messages of type T.
+// The schema registered is the one of the file T is defined in. The files it imports are registered under subjects
+// named after them, and referenced by it, except for the well-known types known by the registry.
+func NewProtobufSerializer[T proto.Message](client *Client, conf SerializerConfig) (*Serializer[T], error) {
+	var zero T
+	md := zero.ProtoReflect().Descriptor()
+
+	var (
+		dependencies []dependency
+		visited      = make(map[string]bool)
+		visit        func(fd protoreflect.FileDescriptor) error
+	)
+	visit = func(fd protoreflect.FileDescriptor) error {
+		for i := 0; i < fd.Imports().Len(); i++ {
+			imported := fd.Imports().Get(i).FileDescriptor
+			if visited[imported.Path()] || isWellKnownProtoFile(imported.Path()) {
+				continue
+			}
+			visited[imported.Path()] = true
+			if err := visit(imported); err != nil {
+				return err
+			}
+			schema, err := protoSchema(imported)
+			if err != nil {
+				return err
+			}
+			dependencies = append(dependencies, dependency{name: imported.Path(), schema: schema, imports: protoImports(imported)})
+		}
+		return nil
+	}
+	if err := visit(md.ParentFile()); err != nil {
+		return nil, err
+	}
+
+	schema, err := protoSchema(md.ParentFile())
+	if err != nil {
+		return nil, err
+	}
+	prefix := appendMessageIndexes(nil, messageIndexes(md))
+	s := newSerializer(client, conf, schema, func(v T) ([]byte, error) {
+		return proto.MarshalOptions{}.MarshalAppend(slices.Clone(prefix), v)
+	})
+	s.dependencies = dependencies
+	s.imports = protoImports(md.ParentFile())
+	return s, nil
+}
+
+// NewProtobufDeserializer returns a deserializer decoding Protobuf messages of type T
+func NewProtobufDeserializer[T proto.Message](client *Client) *Deserializer[T] {
+	return &Deserializer[T]{
+		client: client,
+		decode: func(schema Schema, _ int, payload []byte) (v T, err error) {
+			if err := checkType(schema, Protobuf); err != nil {
+				return v, err
+			}
+			payload, err = skipMessageIndexes(payload)
+			if err != nil {
+				return v, err
+			}
+			msg := v.ProtoReflect().Type().New().Interface()
+			if err := proto.Unmarshal(payload, msg); err != nil {
+				return v, err
+			}
+			return msg.(T), nil
+		},
+	}
+}
+
+// protoSchema returns the schema of a file, in the .proto format
+func protoSchema(fd protoreflect.FileDescriptor) (Schema, error) {
+	wrapped, err := desc.WrapFile(fd)
+	if err != nil {
+		return Schema{}, fmt.Errorf("wrapping descriptor of %s: %w", fd.Path(), err)
+	}
+	printer := protoprint.Printer{OmitComments: protoprint.CommentsAll}
+	text, err := printer.PrintProtoToString(wrapped)
+	if err != nil {
+		return Schema{}, fmt.Errorf("printing schema of %s: %w", fd.Path(), err)
+	}
+	return Schema{Schema: text, Type: Protobuf}, nil
+}
+
+// protoImports returns the paths of the files imported by a file which must be referenced by its schema
+func protoImports(fd protoreflect.FileDescriptor) []string {
+	var imports []string
+	for i := 0; i < fd.Imports().Len(); i++ {
+		if path := fd.Imports().Get(i).Path(); !isWellKnownProtoFile(path) {
+			imports = append(imports, path)
+		}
+	}
+	return imports
+}
+
+func isWellKnownProtoFile(path string) bool {
+	return strings.HasPrefix(path, "google/protobuf/") ||
+		strings.HasPrefix(path, "google/type/") ||
+		strings.HasPrefix(path, "confluent/")
+}
+
+// messageIndexes returns the path of a message within its file, as indexes of the nested message declarations
+func messageIndexes(md protoreflect.MessageDescriptor) []int {
+	var indexes []int
+	for d := protoreflect.Descriptor(md); ; {
+		indexes = append(indexes, d.Index())
+		parent, ok := d.Parent().(protoreflect.MessageDescriptor)
+		if !ok {
+			break
+		}
+		d = parent
+	}
+	slices.Reverse(indexes)
+	return indexes
+}
+
+// appendMessageIndexes encodes message indexes as in the Confluent wire format: zig-zag varints prefixed with their
+// count, or a single 0 for the first message of the file
+func appendMessageIndexes(b []byte, indexes []int) []byte {
+	if len(indexes) == 1 && indexes[0] == 0 {
+		return append(b, 0)
+	}
+	b = binary.AppendVarint(b, int64(len(indexes)))
+	for _, i := range indexes {
+		b = binary.AppendVarint(b, int64(i))
+	}
+	return b
+}
+
+// skipMessageIndexes returns the payload following the message indexes
+func skipMessageIndexes(payload []byte) ([]byte, error) {
+	count, n := binary.Varint(payload)
+	if n <= 0 || count < 0 {
+		return nil, ErrInvalidWireFormat
+	}
+	payload = payload[n:]
+	for range count {
+		if _, n = binary.Varint(payload); n <= 0 {
+			return nil, ErrInvalidWireFormat
+		}
+		payload = payload[n:]
+	}
+	return payload, nil
+}
@@ -0,0 +1,164 @@
+package schemaregistry
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// magicByte is the first byte of messages serialized in the Confluent wire format
+const magicByte byte = 0
+
+// headerSize is the size of the magic byte followed by the schema ID
+const headerSize = 5
+
+// ErrInvalidWireFormat is returned when deserializing data which wasn't serialized in the Confluent wire format
+var ErrInvalidWireFormat = errors.New("invalid wire format")
+
+type SerializerConfig struct {
+	// IsKey makes the serializer use the key subject of topics, i.e. <topic>-key, instead of <topic>-value
+	IsKey bool
+	// SkipRegistration makes the serializer look up the schema instead of registering it, hence the schema must have
+	// been registered beforehand under the subject of the topic
+	SkipRegistration bool
+}
+
+// Serializer serializes values of type T in the Confluent wire format, e.g. for the key or the value of messages
+// published with Producer.Publish. The IDs of schemas are cached by subject.
+type Serializer[T any] struct {
+	client *Client
+	config SerializerConfig
+	schema Schema
+	// imports are the names of the dependencies referenced by schema
+	imports []string
+	// dependencies are the schemas referenced by schema, directly or not, registered under subjects named after them
+	// before it, in order
+	dependencies []dependency
+	encode       func(v T) ([]byte, error)
+
+	mu  sync.Mutex
+	ids map[string]int
+}
+
+type dependency struct {
+	name    string
+	schema  Schema
+	imports []string
+}
+
+// Serialize encodes the value, registering or looking up the schema under the subject of the topic
+func (s *Serializer[T]) Serialize(ctx context.Context, topic string, v T) ([]byte, error) {
+	id, err := s.schemaID(ctx, subject(topic, s.config.IsKey))
+	if err != nil {
+		return nil, err
+	}
+	payload, err := s.encode(v)
+	if err != nil {
+		return nil, fmt.Errorf("encoding %T: %w", v, err)
+	}
+	data := make([]byte, headerSize, headerSize+len(payload))
+	data[0] = magicByte
+	binary.BigEndian.PutUint32(data[1:], uint32(id))
+	return append(data, payload...), nil
+}
+
+func (s *Serializer[T]) schemaID(ctx context.Context, subject string) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if id, ok := s.ids[subject]; ok {
+		return id, nil
+	}
+
+	versions := make(map[string]int, len(s.dependencies))
+	references := func(imports []string) []Reference {
+		var refs []Reference
+		for _, name := range imports {
+			refs = append(refs, Reference{Name: name, Subject: name, Version: versions[name]})
+		}
+		return refs
+	}
+	for _, dep := range s.dependencies {
+		schema := dep.schema
+		schema.References = references(dep.imports)
+		if !s.config.SkipRegistration {
+			if _, err := s.client.Register(ctx, dep.name, schema); err != nil {
+				return 0, err
+			}
+		}
+		_, version, err := s.client.Lookup(ctx, dep.name, schema)
+		if err != nil {
+			return 0, err
+		}
+		versions[dep.name] = version
+	}
+
+	schema := s.schema
+	schema.References = references(s.imports)
+	var (
+		id  int
+		err error
+	)
+	if s.config.SkipRegistration {
+		id, _, err = s.client.Lookup(ctx, subject, schema)
+	} else {
+		id, err = s.client.Register(ctx, subject, schema)
+	}
+	if err != nil {
+		return 0, err
+	}
+	s.ids[subject] = id
+	return id, nil
+}
+
+// Deserializer deserializes values of type T serialized in the Confluent wire format, e.g. the key or the value of
+// messages returned by Consumer.Receive. Schemas are fetched by ID from the registry, and cached.
+type Deserializer[T any] struct {
+	client *Client
+	decode func(schema Schema, id int, payload []byte) (T, error)
+}
+
+// Deserialize decodes the data with the schema it was serialized with
+func (d *Deserializer[T]) Deserialize(ctx context.Context, data []byte) (T, error) {
+	var zero T
+	if len(data) < headerSize || data[0] != magicByte {
+		return zero, ErrInvalidWireFormat
+	}
+	id := int(binary.BigEndian.Uint32(data[1:headerSize]))
+	schema, err := d.client.SchemaByID(ctx, id)
+	if err != nil {
+		return zero, err
+	}
+	v, err := d.decode(schema, id, data[headerSize:])
+	if err != nil {
+		return zero, fmt.Errorf("decoding %T with schema %d: %w", zero, id, err)
+	}
+	return v, nil
+}
+
+func newSerializer[T any](client *Client, conf SerializerConfig, schema Schema, encode func(T) ([]byte, error)) *Serializer[T] {
+	return &Serializer[T]{
+		client: client,
+		config: conf,
+		schema: schema,
+		encode: encode,
+		ids:    make(map[string]int),
+	}
+}
+
+// subject returns the subject of the key or value schema of a topic, following the topic name strategy
+func subject(topic string, isKey bool) string {
+	if isKey {
+		return topic + "-key"
+	}
+	return topic + "-value"
+}
+
+// checkType returns an error if the schema is not of the expected type
+func checkType(schema Schema, expected SchemaType) error {
+	if schema.Type != expected {
+		return fmt.Errorf("expected %s schema, got %s", expected, schema.Type)
+	}
+	return nil
+}
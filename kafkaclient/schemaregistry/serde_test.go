@@ -0,0 +1,365 @@
+package schemaregistry
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ory/dockertest/v3"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	client "github.com/rudderlabs/rudder-go-kit/kafkaclient"
+	dockerKafka "github.com/rudderlabs/rudder-go-kit/testhelper/docker/resource/kafka"
+)
+
+const userSchema = `{
+	"type": "record",
+	"name": "User",
+	"fields": [
+		{"name": "name", "type": "string"},
+		{"name": "age", "type": "int"},
+		{"name": "email", "type": ["null", "string"], "default": null}
+	]
+}`
+
+type user struct {
+	Name  string  `avro:"name" json:"name"`
+	Age   int     `avro:"age" json:"age"`
+	Email *string `avro:"email" json:"email,omitempty"`
+}
+
+func TestAvro(t *testing.T) {
+	registry := newFakeRegistry(t)
+	c, err := NewClient(Config{URL: registry.URL})
+	require.NoError(t, err)
+
+	serializer, err := NewAvroSerializer[user](c, userSchema, SerializerConfig{})
+	require.NoError(t, err)
+	email := "john@example.com"
+	data, err := serializer.Serialize(t.Context(), "users", user{Name: "John", Age: 42, Email: &email})
+	require.NoError(t, err)
+	require.EqualValues(t, magicByte, data[0])
+	require.EqualValues(t, 1, binary.BigEndian.Uint32(data[1:5]))
+	require.Equal(t, []string{"users-value"}, registry.subjects())
+
+	// the schema ID is cached
+	requests := registry.requests()
+	_, err = serializer.Serialize(t.Context(), "users", user{Name: "Jane", Age: 24})
+	require.NoError(t, err)
+	require.Equal(t, requests, registry.requests())
+
+	t.Run("deserialize", func(t *testing.T) {
+		c, err := NewClient(Config{URL: registry.URL})
+		require.NoError(t, err)
+		u, err := NewAvroDeserializer[user](c).Deserialize(t.Context(), data)
+		require.NoError(t, err)
+		require.Equal(t, user{Name: "John", Age: 42, Email: &email}, u)
+
+		// fields missing from the type are skipped
+		type name struct {
+			Name string `avro:"name"`
+		}
+		n, err := NewAvroDeserializer[name](c).Deserialize(t.Context(), data)
+		require.NoError(t, err)
+		require.Equal(t, name{Name: "John"}, n)
+
+		m, err := NewAvroDeserializer[map[string]any](c).Deserialize(t.Context(), data)
+		require.NoError(t, err)
+		require.Equal(t, "John", m["name"])
+	})
+
+	t.Run("key subject", func(t *testing.T) {
+		serializer, err := NewAvroSerializer[string](c, `"string"`, SerializerConfig{IsKey: true})
+		require.NoError(t, err)
+		data, err := serializer.Serialize(t.Context(), "users", "some-key")
+		require.NoError(t, err)
+		require.Contains(t, registry.subjects(), "users-key")
+
+		key, err := NewAvroDeserializer[string](c).Deserialize(t.Context(), data)
+		require.NoError(t, err)
+		require.Equal(t, "some-key", key)
+	})
+
+	t.Run("skip registration", func(t *testing.T) {
+		serializer, err := NewAvroSerializer[user](c, userSchema, SerializerConfig{SkipRegistration: true})
+		require.NoError(t, err)
+		lookedUp, err := serializer.Serialize(t.Context(), "users", user{Name: "John", Age: 42, Email: &email})
+		require.NoError(t, err)
+		require.Equal(t, data, lookedUp)
+
+		_, err = serializer.Serialize(t.Context(), "unregistered", user{})
+		var regErr *Error
+		require.ErrorAs(t, err, &regErr)
+		require.Equal(t, http.StatusNotFound, regErr.StatusCode)
+		require.Equal(t, 40401, regErr.Code)
+	})
+
+	t.Run("invalid data", func(t *testing.T) {
+		d := NewAvroDeserializer[user](c)
+		_, err := d.Deserialize(t.Context(), []byte("not avro"))
+		require.ErrorIs(t, err, ErrInvalidWireFormat)
+		_, err = d.Deserialize(t.Context(), []byte{magicByte, 0, 0, 0, 99, 1})
+		var regErr *Error
+		require.ErrorAs(t, err, &regErr)
+		require.Equal(t, 40403, regErr.Code)
+	})
+
+	t.Run("invalid schema", func(t *testing.T) {
+		_, err := NewAvroSerializer[user](c, `{"type": "record"}`, SerializerConfig{})
+		require.Error(t, err)
+	})
+}
+
+func TestJSONSchema(t *testing.T) {
+	const schema = `{
+		"type": "object",
+		"properties": {
+			"name": {"type": "string"},
+			"age": {"type": "integer", "minimum": 0}
+		},
+		"required": ["name", "age"]
+	}`
+	registry := newFakeRegistry(t)
+	c, err := NewClient(Config{URL: registry.URL})
+	require.NoError(t, err)
+
+	serializer, err := NewJSONSchemaSerializer[user](c, schema, SerializerConfig{})
+	require.NoError(t, err)
+	data, err := serializer.Serialize(t.Context(), "users", user{Name: "John", Age: 42})
+	require.NoError(t, err)
+	require.JSONEq(t, `{"name": "John", "age": 42}`, string(data[5:]))
+
+	u, err := NewJSONSchemaDeserializer[user](c).Deserialize(t.Context(), data)
+	require.NoError(t, err)
+	require.Equal(t, user{Name: "John", Age: 42}, u)
+
+	_, err = serializer.Serialize(t.Context(), "users", user{Name: "John", Age: -1})
+	require.ErrorContains(t, err, "invalid against JSON Schema")
+
+	_, err = NewAvroDeserializer[user](c).Deserialize(t.Context(), data)
+	require.ErrorContains(t, err, "expected AVRO schema, got JSON")
+}
+
+func TestProtobuf(t *testing.T) {
+	registry := newFakeRegistry(t)
+	c, err := NewClient(Config{URL: registry.URL})
+	require.NoError(t, err)
+
+	t.Run("first message of the file", func(t *testing.T) {
+		serializer, err := NewProtobufSerializer[*structpb.Struct](c, SerializerConfig{})
+		require.NoError(t, err)
+		msg, err := structpb.NewStruct(map[string]any{"name": "John", "age": 42})
+		require.NoError(t, err)
+		data, err := serializer.Serialize(t.Context(), "structs", msg)
+		require.NoError(t, err)
+		require.EqualValues(t, 0, data[5], "message indexes")
+		require.Contains(t, registry.schema("structs-value").Schema, "message Struct")
+		require.Equal(t, Protobuf, registry.schema("structs-value").Type)
+
+		decoded, err := NewProtobufDeserializer[*structpb.Struct](c).Deserialize(t.Context(), data)
+		require.NoError(t, err)
+		require.True(t, proto.Equal(msg, decoded))
+	})
+
+	t.Run("other message of the file", func(t *testing.T) {
+		serializer, err := NewProtobufSerializer[*structpb.Value](c, SerializerConfig{})
+		require.NoError(t, err)
+		msg := structpb.NewStringValue("some-value")
+		data, err := serializer.Serialize(t.Context(), "values", msg)
+		require.NoError(t, err)
+		require.Equal(t, []byte{2, 2}, data[5:7], "message indexes [1], zig-zag encoded")
+
+		decoded, err := NewProtobufDeserializer[*structpb.Value](c).Deserialize(t.Context(), data)
+		require.NoError(t, err)
+		require.True(t, proto.Equal(msg, decoded))
+	})
+
+	t.Run("message indexes", func(t *testing.T) {
+		for _, indexes := range [][]int{{0}, {1}, {0, 2}, {3, 1, 64}} {
+			b := appendMessageIndexes(nil, indexes)
+			rest, err := skipMessageIndexes(append(b, 42))
+			require.NoError(t, err)
+			require.Equal(t, []byte{42}, rest)
+		}
+		_, err := skipMessageIndexes([]byte{4, 2})
+		require.ErrorIs(t, err, ErrInvalidWireFormat)
+	})
+}
+
+func TestSchemaRegistryWithKafka(t *testing.T) {
+	pool, err := dockertest.NewPool("")
+	require.NoError(t, err)
+	container, err := dockerKafka.Setup(pool, t, dockerKafka.WithBrokers(1), dockerKafka.WithSchemaRegistry())
+	require.NoError(t, err)
+
+	kafkaClient, err := client.New("tcp", container.Brokers, client.Config{})
+	require.NoError(t, err)
+	registry, err := NewClient(Config{URL: container.SchemaRegistryURL})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+	topic := "users"
+	require.Eventually(t, func() bool {
+		err := kafkaClient.CreateTopics(ctx, client.TopicConfig{Topic: topic, NumPartitions: 1, ReplicationFactor: 1})
+		if err != nil {
+			t.Logf("Could not create topic: %v", err)
+		}
+		return err == nil
+	}, time.Minute, time.Second)
+
+	keySerializer, err := NewAvroSerializer[string](registry, `"string"`, SerializerConfig{IsKey: true})
+	require.NoError(t, err)
+	valueSerializer, err := NewAvroSerializer[user](registry, userSchema, SerializerConfig{})
+	require.NoError(t, err)
+	key, err := keySerializer.Serialize(ctx, topic, "123")
+	require.NoError(t, err)
+	value, err := valueSerializer.Serialize(ctx, topic, user{Name: "John", Age: 42})
+	require.NoError(t, err)
+
+	producer, err := kafkaClient.NewProducer(client.ProducerConfig{})
+	require.NoError(t, err)
+	defer func() { _ = producer.Close(context.Background()) }()
+	require.Eventually(t, func() bool {
+		err := producer.Publish(ctx, client.Message{Topic: topic, Key: key, Value: value})
+		if err != nil {
+			t.Logf("Could not publish message: %v", err)
+		}
+		return err == nil
+	}, time.Minute, time.Second)
+
+	consumer := kafkaClient.NewConsumer(topic, client.ConsumerConfig{StartOffset: client.FirstOffset})
+	defer func() { _ = consumer.Close(context.Background()) }()
+	msg, err := consumer.Receive(ctx)
+	require.NoError(t, err)
+
+	u, err := NewAvroDeserializer[user](registry).Deserialize(ctx, msg.Value)
+	require.NoError(t, err)
+	require.Equal(t, user{Name: "John", Age: 42}, u)
+	k, err := NewAvroDeserializer[string](registry).Deserialize(ctx, msg.Key)
+	require.NoError(t, err)
+	require.Equal(t, "123", k)
+}
+
+// fakeRegistry is an in-memory implementation of the schema registry API used by the client
+type fakeRegistry struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	schemas  []Schema         // by ID - 1
+	versions map[string][]int // schema IDs by subject, by version - 1
+	log      []string         // requests received
+	bySchema map[string]int   // IDs by JSON encoded schema
+}
+
+func newFakeRegistry(t *testing.T) *fakeRegistry {
+	t.Helper()
+	r := &fakeRegistry{
+		versions: make(map[string][]int),
+		bySchema: make(map[string]int),
+	}
+	r.Server = httptest.NewServer(http.HandlerFunc(r.handle))
+	t.Cleanup(r.Close)
+	return r
+}
+
+func (r *fakeRegistry) handle(w http.ResponseWriter, req *http.Request) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.log = append(r.log, req.Method+" "+req.URL.Path)
+
+	writeError := func(status, code int) {
+		w.WriteHeader(status)
+		_ = json.NewEncoder(w).Encode(map[string]any{"error_code": code, "message": "some error"})
+	}
+	path := strings.Split(strings.Trim(req.URL.Path, "/"), "/")
+	switch {
+	case req.Method == http.MethodGet && len(path) == 3 && path[0] == "schemas" && path[1] == "ids":
+		id, _ := strconv.Atoi(path[2])
+		if id < 1 || id > len(r.schemas) {
+			writeError(http.StatusNotFound, 40403)
+			return
+		}
+		schema := r.schemas[id-1]
+		if schema.Type == Avro {
+			schema.Type = ""
+		}
+		_ = json.NewEncoder(w).Encode(schema)
+
+	case req.Method == http.MethodPost && path[0] == "subjects":
+		var schema Schema
+		if err := json.NewDecoder(req.Body).Decode(&schema); err != nil {
+			writeError(http.StatusUnprocessableEntity, 42201)
+			return
+		}
+		if schema.Type == "" {
+			schema.Type = Avro
+		}
+		b, _ := json.Marshal(schema)
+		subject := path[1]
+		id, ok := r.bySchema[string(b)]
+		if len(path) == 3 && path[2] == "versions" { // register
+			if !ok {
+				r.schemas = append(r.schemas, schema)
+				id = len(r.schemas)
+				r.bySchema[string(b)] = id
+			}
+			for _, existing := range r.versions[subject] {
+				if existing == id {
+					_ = json.NewEncoder(w).Encode(map[string]int{"id": id})
+					return
+				}
+			}
+			r.versions[subject] = append(r.versions[subject], id)
+			_ = json.NewEncoder(w).Encode(map[string]int{"id": id})
+			return
+		}
+		// lookup
+		if _, exists := r.versions[subject]; !exists {
+			writeError(http.StatusNotFound, 40401)
+			return
+		}
+		for i, existing := range r.versions[subject] {
+			if ok && existing == id {
+				_ = json.NewEncoder(w).Encode(map[string]any{"subject": subject, "id": id, "version": i + 1})
+				return
+			}
+		}
+		writeError(http.StatusNotFound, 40403)
+
+	default:
+		writeError(http.StatusNotFound, 404)
+	}
+}
+
+func (r *fakeRegistry) subjects() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var subjects []string
+	for subject := range r.versions {
+		subjects = append(subjects, subject)
+	}
+	return subjects
+}
+
+func (r *fakeRegistry) schema(subject string) Schema {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	versions := r.versions[subject]
+	return r.schemas[versions[len(versions)-1]-1]
+}
+
+func (r *fakeRegistry) requests() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.log...)
+}
@@ -0,0 +1,205 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+
+	"github.com/rudderlabs/rudder-go-kit/stats"
+)
+
+// producerCollector is a stats.Collector reporting the stats of a producer.
+// The writer's counters are reset whenever its stats are read, hence totals are accumulated by the collector.
+type producerCollector struct {
+	producer *Producer
+	tags     stats.Tags
+
+	mu                                     sync.Mutex
+	writes, messages, bytes, errs, retries uint64
+	topics                                 map[string]*topicTotals
+}
+
+type topicTotals struct {
+	messages, bytes, errs uint64
+}
+
+func newProducerCollector(p *Producer, clientID string) *producerCollector {
+	return &producerCollector{
+		producer: p,
+		tags:     stats.Tags{"client_id": clientID},
+		topics:   make(map[string]*topicTotals),
+	}
+}
+
+// published accounts for published messages, per topic. Messages failed to be published are accounted as errors.
+func (c *producerCollector) published(msgs []kafka.Message, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var writeErrors kafka.WriteErrors
+	isWriteErrors := errors.As(err, &writeErrors) && len(writeErrors) == len(msgs)
+	for i := range msgs {
+		t, ok := c.topics[msgs[i].Topic]
+		if !ok {
+			t = &topicTotals{}
+			c.topics[msgs[i].Topic] = t
+		}
+		if err != nil && (!isWriteErrors || writeErrors[i] != nil) {
+			t.errs++
+			continue
+		}
+		t.messages++
+		t.bytes += uint64(len(msgs[i].Key) + len(msgs[i].Value))
+	}
+}
+
+func (c *producerCollector) Collect(gaugeFunc func(key string, tags stats.Tags, val uint64)) {
+	s := c.producer.writer.Stats()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.writes += uint64(s.Writes)
+	c.messages += uint64(s.Messages)
+	c.bytes += uint64(s.Bytes)
+	c.errs += uint64(s.Errors)
+	c.retries += uint64(s.Retries)
+
+	gaugeFunc("kafka_producer_writes_total", c.tags, c.writes)
+	gaugeFunc("kafka_producer_messages_total", c.tags, c.messages)
+	gaugeFunc("kafka_producer_bytes_total", c.tags, c.bytes)
+	gaugeFunc("kafka_producer_errors_total", c.tags, c.errs)
+	gaugeFunc("kafka_producer_retries_total", c.tags, c.retries)
+	gaugeFunc("kafka_producer_batch_size_avg", c.tags, uint64(s.BatchSize.Avg))
+	gaugeFunc("kafka_producer_batch_size_max", c.tags, uint64(s.BatchSize.Max))
+	gaugeFunc("kafka_producer_batch_bytes_avg", c.tags, uint64(s.BatchBytes.Avg))
+	gaugeFunc("kafka_producer_batch_bytes_max", c.tags, uint64(s.BatchBytes.Max))
+	gaugeFunc("kafka_producer_batch_queue_time_avg_ms", c.tags, milliseconds(s.BatchQueueTime.Avg))
+	gaugeFunc("kafka_producer_batch_queue_time_max_ms", c.tags, milliseconds(s.BatchQueueTime.Max))
+	gaugeFunc("kafka_producer_write_time_avg_ms", c.tags, milliseconds(s.WriteTime.Avg))
+	gaugeFunc("kafka_producer_write_time_max_ms", c.tags, milliseconds(s.WriteTime.Max))
+
+	for topic, t := range c.topics {
+		tags := c.topicTags(topic)
+		gaugeFunc("kafka_producer_topic_messages_total", tags, t.messages)
+		gaugeFunc("kafka_producer_topic_bytes_total", tags, t.bytes)
+		gaugeFunc("kafka_producer_topic_errors_total", tags, t.errs)
+	}
+}
+
+func (c *producerCollector) Zero(gaugeFunc func(key string, tags stats.Tags, val uint64)) {
+	for _, key := range []string{
+		"kafka_producer_writes_total",
+		"kafka_producer_messages_total",
+		"kafka_producer_bytes_total",
+		"kafka_producer_errors_total",
+		"kafka_producer_retries_total",
+		"kafka_producer_batch_size_avg",
+		"kafka_producer_batch_size_max",
+		"kafka_producer_batch_bytes_avg",
+		"kafka_producer_batch_bytes_max",
+		"kafka_producer_batch_queue_time_avg_ms",
+		"kafka_producer_batch_queue_time_max_ms",
+		"kafka_producer_write_time_avg_ms",
+		"kafka_producer_write_time_max_ms",
+	} {
+		gaugeFunc(key, c.tags, 0)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for topic := range c.topics {
+		tags := c.topicTags(topic)
+		gaugeFunc("kafka_producer_topic_messages_total", tags, 0)
+		gaugeFunc("kafka_producer_topic_bytes_total", tags, 0)
+		gaugeFunc("kafka_producer_topic_errors_total", tags, 0)
+	}
+}
+
+func (c *producerCollector) ID() string {
+	return fmt.Sprintf("kafka_producer_%s_%p", c.tags["client_id"], c.producer)
+}
+
+func (c *producerCollector) topicTags(topic string) stats.Tags {
+	return stats.Tags{"client_id": c.tags["client_id"], "topic": topic}
+}
+
+// consumerCollector is a stats.Collector reporting the stats of a consumer.
+// The reader's counters are reset whenever its stats are read, hence totals are accumulated by the collector.
+type consumerCollector struct {
+	consumer *Consumer
+	tags     stats.Tags
+
+	mu                                                          sync.Mutex
+	dials, fetches, messages, bytes, rebalances, timeouts, errs uint64
+}
+
+func newConsumerCollector(c *Consumer, topic, clientID, groupID string) *consumerCollector {
+	return &consumerCollector{
+		consumer: c,
+		tags:     stats.Tags{"topic": topic, "client_id": clientID, "group_id": groupID},
+	}
+}
+
+func (c *consumerCollector) Collect(gaugeFunc func(key string, tags stats.Tags, val uint64)) {
+	s := c.consumer.reader.Stats()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.dials += uint64(s.Dials)
+	c.fetches += uint64(s.Fetches)
+	c.messages += uint64(s.Messages)
+	c.bytes += uint64(s.Bytes)
+	c.rebalances += uint64(s.Rebalances)
+	c.timeouts += uint64(s.Timeouts)
+	c.errs += uint64(s.Errors)
+
+	gaugeFunc("kafka_consumer_dials_total", c.tags, c.dials)
+	gaugeFunc("kafka_consumer_fetches_total", c.tags, c.fetches)
+	gaugeFunc("kafka_consumer_messages_total", c.tags, c.messages)
+	gaugeFunc("kafka_consumer_bytes_total", c.tags, c.bytes)
+	gaugeFunc("kafka_consumer_rebalances_total", c.tags, c.rebalances)
+	gaugeFunc("kafka_consumer_timeouts_total", c.tags, c.timeouts)
+	gaugeFunc("kafka_consumer_errors_total", c.tags, c.errs)
+	gaugeFunc("kafka_consumer_lag", c.tags, uint64(max(s.Lag, 0)))
+	gaugeFunc("kafka_consumer_queue_length", c.tags, uint64(s.QueueLength))
+	gaugeFunc("kafka_consumer_queue_capacity", c.tags, uint64(s.QueueCapacity))
+	gaugeFunc("kafka_consumer_fetch_size_avg", c.tags, uint64(s.FetchSize.Avg))
+	gaugeFunc("kafka_consumer_fetch_bytes_avg", c.tags, uint64(s.FetchBytes.Avg))
+	gaugeFunc("kafka_consumer_fetch_wait_avg_ms", c.tags, milliseconds(s.WaitTime.Avg))
+	gaugeFunc("kafka_consumer_fetch_wait_max_ms", c.tags, milliseconds(s.WaitTime.Max))
+	gaugeFunc("kafka_consumer_read_time_avg_ms", c.tags, milliseconds(s.ReadTime.Avg))
+	gaugeFunc("kafka_consumer_read_time_max_ms", c.tags, milliseconds(s.ReadTime.Max))
+}
+
+func (c *consumerCollector) Zero(gaugeFunc func(key string, tags stats.Tags, val uint64)) {
+	for _, key := range []string{
+		"kafka_consumer_dials_total",
+		"kafka_consumer_fetches_total",
+		"kafka_consumer_messages_total",
+		"kafka_consumer_bytes_total",
+		"kafka_consumer_rebalances_total",
+		"kafka_consumer_timeouts_total",
+		"kafka_consumer_errors_total",
+		"kafka_consumer_lag",
+		"kafka_consumer_queue_length",
+		"kafka_consumer_queue_capacity",
+		"kafka_consumer_fetch_size_avg",
+		"kafka_consumer_fetch_bytes_avg",
+		"kafka_consumer_fetch_wait_avg_ms",
+		"kafka_consumer_fetch_wait_max_ms",
+		"kafka_consumer_read_time_avg_ms",
+		"kafka_consumer_read_time_max_ms",
+	} {
+		gaugeFunc(key, c.tags, 0)
+	}
+}
+
+func (c *consumerCollector) ID() string {
+	return fmt.Sprintf("kafka_consumer_%s_%s_%p", c.tags["topic"], c.tags["client_id"], c.consumer)
+}
+
+func milliseconds(d time.Duration) uint64 {
+	return uint64(max(d.Milliseconds(), 0))
+}
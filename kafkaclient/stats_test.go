@@ -0,0 +1,109 @@
+package client
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/rudderlabs/rudder-go-kit/stats"
+	"github.com/rudderlabs/rudder-go-kit/stats/memstats"
+	"github.com/rudderlabs/rudder-go-kit/stats/mock_stats"
+)
+
+func TestStatsCollectors(t *testing.T) {
+	c, err := New("tcp", []string{"localhost:1"}, Config{ClientID: "some-client"})
+	require.NoError(t, err)
+
+	t.Run("producer", func(t *testing.T) {
+		store, err := memstats.New()
+		require.NoError(t, err)
+
+		p, err := c.NewProducer(ProducerConfig{Stats: store})
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = p.Close(t.Context()) })
+
+		tags := stats.Tags{"client_id": "some-client"}
+		require.EqualValues(t, 0, store.Get("kafka_producer_messages_total", tags).LastValue())
+		require.EqualValues(t, 0, store.Get("kafka_producer_errors_total", tags).LastValue())
+
+		p.collector.published([]kafka.Message{
+			{Topic: "topic-a", Key: []byte("k"), Value: []byte("value")},
+			{Topic: "topic-a", Value: []byte("value")},
+			{Topic: "topic-b", Value: []byte("value")},
+		}, kafka.WriteErrors{nil, nil, errors.New("some error")})
+		p.collector.published([]kafka.Message{{Topic: "topic-b", Value: []byte("value")}}, errors.New("some error"))
+
+		// memstats collects once when registering, hence using a new store for getting the latest values
+		store, err = memstats.New()
+		require.NoError(t, err)
+		require.NoError(t, store.RegisterCollector(p.collector))
+
+		topicA := stats.Tags{"client_id": "some-client", "topic": "topic-a"}
+		require.EqualValues(t, 2, store.Get("kafka_producer_topic_messages_total", topicA).LastValue())
+		require.EqualValues(t, 11, store.Get("kafka_producer_topic_bytes_total", topicA).LastValue())
+		require.EqualValues(t, 0, store.Get("kafka_producer_topic_errors_total", topicA).LastValue())
+		topicB := stats.Tags{"client_id": "some-client", "topic": "topic-b"}
+		require.EqualValues(t, 0, store.Get("kafka_producer_topic_messages_total", topicB).LastValue())
+		require.EqualValues(t, 2, store.Get("kafka_producer_topic_errors_total", topicB).LastValue())
+	})
+
+	t.Run("consumer", func(t *testing.T) {
+		store, err := memstats.New()
+		require.NoError(t, err)
+
+		consumer := c.NewConsumer("some-topic", ConsumerConfig{Stats: store})
+		t.Cleanup(func() { _ = consumer.Close(t.Context()) })
+
+		tags := stats.Tags{"topic": "some-topic", "client_id": "some-client", "group_id": ""}
+		require.NotNil(t, store.Get("kafka_consumer_lag", tags))
+		require.EqualValues(t, 0, store.Get("kafka_consumer_messages_total", tags).LastValue())
+		require.EqualValues(t, 0, store.Get("kafka_consumer_rebalances_total", tags).LastValue())
+	})
+
+	t.Run("collectors unregistered on close", func(t *testing.T) {
+		s := &unregisteringStats{Stats: stats.NOP}
+		p, err := c.NewProducer(ProducerConfig{Stats: s})
+		require.NoError(t, err)
+		consumer := c.NewConsumer("some-topic", ConsumerConfig{Stats: s})
+
+		require.NoError(t, p.Close(t.Context()))
+		require.NoError(t, consumer.Close(t.Context()))
+		require.Equal(t, []stats.Collector{p.collector, consumer.collector}, s.unregistered)
+	})
+
+	t.Run("collector registration error", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockStats := mock_stats.NewMockStats(ctrl)
+		mockStats.EXPECT().RegisterCollector(gomock.Any()).Return(errors.New("some error")).Times(3)
+
+		_, err := c.NewProducer(ProducerConfig{Stats: mockStats})
+		require.ErrorContains(t, err, "could not register stats collector")
+		_, err = c.NewConsumerE("some-topic", ConsumerConfig{Stats: mockStats})
+		require.ErrorContains(t, err, "could not register stats collector")
+
+		consumer := c.NewConsumer("some-topic", ConsumerConfig{Stats: mockStats})
+		require.Nil(t, consumer.collector, "the consumer should be usable without its collector")
+		require.NoError(t, consumer.Close(t.Context()))
+	})
+
+	t.Run("unique collector IDs", func(t *testing.T) {
+		p1, err := c.NewProducer(ProducerConfig{Stats: stats.NOP})
+		require.NoError(t, err)
+		p2, err := c.NewProducer(ProducerConfig{Stats: stats.NOP})
+		require.NoError(t, err)
+		require.NotEqual(t, p1.collector.ID(), p2.collector.ID())
+	})
+}
+
+// unregisteringStats records the collectors unregistered through it
+type unregisteringStats struct {
+	stats.Stats
+	unregistered []stats.Collector
+}
+
+func (s *unregisteringStats) UnregisterCollector(c stats.Collector) {
+	s.unregistered = append(s.unregistered, c)
+}
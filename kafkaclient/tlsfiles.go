@@ -0,0 +1,189 @@
+package client
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"sync"
+	"time"
+)
+
+// tlsFiles loads the certificate, key and CA certificate files of a TLS configuration, checking whether they changed
+// whenever a connection is established, so that new connections use the certificates rotated on disk, e.g. by
+// cert-manager.
+// If loading changed files fails, e.g. while they are being rotated, the previous certificates keep being used and
+// loading is retried for the next connection.
+type tlsFiles struct {
+	certFile, keyFile, caFile string
+	systemCertPool            bool
+
+	mu          sync.Mutex
+	stamps      map[string]fileStamp
+	certificate *tls.Certificate
+	roots       *x509.CertPool
+}
+
+// fileStamp identifies a version of a file. Files mounted from Kubernetes secrets are symlinks to a new file on
+// each update, hence their modification time changes.
+type fileStamp struct {
+	modTime time.Time
+	size    int64
+}
+
+func newTLSFiles(certFile, keyFile, caFile string, systemCertPool bool) (*tlsFiles, error) {
+	f := &tlsFiles{
+		certFile:       certFile,
+		keyFile:        keyFile,
+		caFile:         caFile,
+		systemCertPool: systemCertPool,
+	}
+	if err := f.reload(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// reload loads the files again if any of them changed since they were last loaded
+func (f *tlsFiles) reload() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	stamps := make(map[string]fileStamp, 3)
+	changed := f.stamps == nil
+	for _, path := range []string{f.certFile, f.keyFile, f.caFile} {
+		if path == "" {
+			continue
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			return f.keepPrevious(fmt.Errorf("could not stat %s: %w", path, err))
+		}
+		stamps[path] = fileStamp{modTime: info.ModTime(), size: info.Size()}
+		if stamps[path] != f.stamps[path] {
+			changed = true
+		}
+	}
+	if !changed {
+		return nil
+	}
+
+	var (
+		certificate *tls.Certificate
+		roots       *x509.CertPool
+	)
+	if f.certFile != "" {
+		c, err := tls.LoadX509KeyPair(f.certFile, f.keyFile)
+		if err != nil {
+			return f.keepPrevious(fmt.Errorf("could not get TLS certificate: %w", err))
+		}
+		certificate = &c
+	}
+	if f.caFile != "" {
+		caCertificate, err := os.ReadFile(f.caFile)
+		if err != nil {
+			return f.keepPrevious(fmt.Errorf("could not read CA certificate: %w", err))
+		}
+		roots = x509.NewCertPool()
+		if f.systemCertPool {
+			if roots, err = x509.SystemCertPool(); err != nil {
+				return f.keepPrevious(fmt.Errorf("could not copy of the system cert pool: %w", err))
+			}
+		}
+		if ok := roots.AppendCertsFromPEM(caCertificate); !ok {
+			return f.keepPrevious(fmt.Errorf("could not append certs from PEM"))
+		}
+	}
+
+	f.stamps = stamps
+	f.certificate = certificate
+	f.roots = roots
+	return nil
+}
+
+// keepPrevious returns the error if nothing was loaded yet, otherwise the previously loaded files keep being used
+func (f *tlsFiles) keepPrevious(err error) error {
+	if f.stamps == nil {
+		return err
+	}
+	return nil
+}
+
+// clientCertificate is a tls.Config GetClientCertificate callback returning the current certificate
+func (f *tlsFiles) clientCertificate(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+	if err := f.reload(); err != nil {
+		return nil, err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.certificate, nil
+}
+
+// verifyConnection is a tls.Config VerifyConnection callback verifying the certificate chain of the server against
+// the current CA certificates, as done by crypto/tls if verification wasn't skipped.
+// The server name of the connection state is the one sent for SNI, which is empty for IP addresses, hence connections
+// have to be established by dialTLS, setting it to the dialed host.
+func (f *tlsFiles) verifyConnection(cs tls.ConnectionState) error {
+	if cs.ServerName == "" {
+		return errors.New("no server name to verify the server certificate against")
+	}
+	if err := f.reload(); err != nil {
+		return err
+	}
+	f.mu.Lock()
+	roots := f.roots
+	f.mu.Unlock()
+
+	if len(cs.PeerCertificates) == 0 {
+		return errors.New("no certificate presented by the server")
+	}
+	opts := x509.VerifyOptions{
+		DNSName:       cs.ServerName,
+		Roots:         roots,
+		Intermediates: x509.NewCertPool(),
+	}
+	for _, cert := range cs.PeerCertificates[1:] {
+		opts.Intermediates.AddCert(cert)
+	}
+	_, err := cs.PeerCertificates[0].Verify(opts)
+	return err
+}
+
+// dialFunc establishes connections, e.g. net.Dialer.DialContext
+type dialFunc = func(ctx context.Context, network, address string) (net.Conn, error)
+
+// dialTLS returns a dial function establishing TLS connections over the ones returned by dial, verifying the server
+// certificate against the dialed host, be it a name or an IP address.
+// The handshake isn't left to kafka-go since the connection state passed to conf.VerifyConnection only has the server
+// name sent for SNI, which is empty for IP addresses.
+func dialTLS(dial dialFunc, conf *tls.Config) dialFunc {
+	return func(ctx context.Context, network, address string) (net.Conn, error) {
+		host, _, err := net.SplitHostPort(address)
+		if err != nil {
+			return nil, fmt.Errorf("could not get the host of %q: %w", address, err)
+		}
+		c := conf.Clone()
+		if c.ServerName == "" {
+			c.ServerName = host
+		}
+		verifyConnection := c.VerifyConnection
+		c.VerifyConnection = func(cs tls.ConnectionState) error {
+			cs.ServerName = c.ServerName
+			return verifyConnection(cs)
+		}
+
+		conn, err := dial(ctx, network, address)
+		if err != nil {
+			return nil, err
+		}
+		tlsConn := tls.Client(conn, c)
+		if err := tlsConn.HandshakeContext(ctx); err != nil {
+			_ = conn.Close()
+			return nil, err
+		}
+		return tlsConn, nil
+	}
+}
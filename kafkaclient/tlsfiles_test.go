@@ -0,0 +1,237 @@
+package client
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"io"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTLSFilesReload(t *testing.T) {
+	dir := t.TempDir()
+	var (
+		caFile   = filepath.Join(dir, "ca.pem")
+		certFile = filepath.Join(dir, "client.pem")
+		keyFile  = filepath.Join(dir, "client-key.pem")
+	)
+	ca1, ca2 := newTestCA(t, 1), newTestCA(t, 2)
+	writeTestFile(t, caFile, ca1.certPEM, 0)
+	client1Cert, client1Key := ca1.issue(t, 11, "client")
+	writeTestFile(t, certFile, client1Cert, 0)
+	writeTestFile(t, keyFile, client1Key, 0)
+
+	serverCert, serverKey := ca1.issue(t, 21, "localhost")
+	server := newTestTLSServer(t, serverCert, serverKey, ca1, ca2)
+
+	conf, err := (&TLS{CACertificateFile: caFile, CertFile: certFile, KeyFile: keyFile}).build()
+	require.NoError(t, err)
+	require.EqualValues(t, tls.VersionTLS13, conf.MaxVersion)
+
+	require.NoError(t, server.handshake(conf))
+	require.EqualValues(t, 11, <-server.clientSerials)
+
+	// the server certificate is signed by another CA, unknown until the CA file is rotated
+	serverCert, serverKey = ca2.issue(t, 22, "localhost")
+	server.setCertificate(t, serverCert, serverKey)
+	require.Error(t, server.handshake(conf))
+	<-server.clientSerials
+
+	writeTestFile(t, caFile, ca2.certPEM, time.Minute)
+	client2Cert, client2Key := ca2.issue(t, 12, "client")
+	writeTestFile(t, certFile, client2Cert, time.Minute)
+	writeTestFile(t, keyFile, client2Key, time.Minute)
+	require.NoError(t, server.handshake(conf))
+	require.EqualValues(t, 12, <-server.clientSerials)
+
+	// invalid files, e.g. while being rotated, are ignored until they are valid again
+	writeTestFile(t, certFile, []byte("invalid"), 2*time.Minute)
+	require.NoError(t, server.handshake(conf))
+	require.EqualValues(t, 12, <-server.clientSerials)
+
+	t.Run("dialed host", func(t *testing.T) {
+		dial := dialTLS((&net.Dialer{Timeout: time.Second}).DialContext, conf)
+		handshake := func() error {
+			conn, err := dial(context.Background(), "tcp", server.listener.Addr().String())
+			if err != nil {
+				return err
+			}
+			defer func() { _ = conn.Close() }()
+			_, err = conn.Read(make([]byte, 1))
+			if err != nil && !errors.Is(err, io.EOF) {
+				return err
+			}
+			return nil
+		}
+
+		// the server certificate is verified against the dialed IP address, which it isn't issued for
+		require.ErrorContains(t, handshake(), "127.0.0.1")
+		<-server.clientSerials
+
+		serverCert, serverKey := ca2.issue(t, 23, "127.0.0.1")
+		server.setCertificate(t, serverCert, serverKey)
+		require.NoError(t, handshake())
+		require.EqualValues(t, 12, <-server.clientSerials)
+
+		// connections not established by dialTLS have no server name to verify when dialing IP addresses
+		_, err := tls.Dial("tcp", server.listener.Addr().String(), conf)
+		require.ErrorContains(t, err, "no server name")
+		<-server.clientSerials
+	})
+
+	t.Run("invalid files", func(t *testing.T) {
+		_, err := (&TLS{CACertificateFile: filepath.Join(dir, "missing.pem")}).build()
+		require.ErrorContains(t, err, "could not stat")
+		_, err = (&TLS{CACertificateFile: caFile, CertFile: certFile, KeyFile: keyFile}).build()
+		require.ErrorContains(t, err, "could not get TLS certificate")
+		_, err = (&TLS{CACertificateFile: caFile, CertFile: certFile}).build()
+		require.ErrorContains(t, err, "both a certificate file and a key file are required")
+	})
+}
+
+type testCA struct {
+	cert    *x509.Certificate
+	key     *ecdsa.PrivateKey
+	certPEM []byte
+}
+
+func newTestCA(t *testing.T, serial int64) *testCA {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(serial),
+		Subject:               pkix.Name{CommonName: "test-ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	return &testCA{cert: cert, key: key, certPEM: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})}
+}
+
+// issue returns a certificate for a name or an IP address signed by the CA, and its key, PEM encoded
+func (ca *testCA) issue(t *testing.T, serial int64, name string) (certPEM, keyPEM []byte) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: name},
+		DNSNames:     []string{name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+	if ip := net.ParseIP(name); ip != nil {
+		template.DNSNames, template.IPAddresses = nil, []net.IP{ip}
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.cert, &key.PublicKey, ca.key)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+}
+
+// writeTestFile writes a file, setting its modification time in the future so that it is seen as changed
+func writeTestFile(t *testing.T, path string, data []byte, modTimeOffset time.Duration) {
+	t.Helper()
+	require.NoError(t, os.WriteFile(path, data, 0o600))
+	modTime := time.Now().Add(modTimeOffset)
+	require.NoError(t, os.Chtimes(path, modTime, modTime))
+}
+
+type testTLSServer struct {
+	listener      net.Listener
+	certificate   chan tls.Certificate
+	clientSerials chan int64
+}
+
+// newTestTLSServer starts a TLS server requiring client certificates signed by any of the given CAs
+func newTestTLSServer(t *testing.T, certPEM, keyPEM []byte, clientCAs ...*testCA) *testTLSServer {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = listener.Close() })
+
+	s := &testTLSServer{
+		listener:      listener,
+		certificate:   make(chan tls.Certificate, 1),
+		clientSerials: make(chan int64, 1),
+	}
+	s.setCertificate(t, certPEM, keyPEM)
+	pool := x509.NewCertPool()
+	for _, ca := range clientCAs {
+		pool.AddCert(ca.cert)
+	}
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			certificate := <-s.certificate
+			s.certificate <- certificate
+			tlsConn := tls.Server(conn, &tls.Config{
+				Certificates: []tls.Certificate{certificate},
+				ClientAuth:   tls.RequireAndVerifyClientCert,
+				ClientCAs:    pool,
+			})
+			serial := int64(-1)
+			if tlsConn.Handshake() == nil {
+				serial = tlsConn.ConnectionState().PeerCertificates[0].SerialNumber.Int64()
+			}
+			s.clientSerials <- serial
+			_ = tlsConn.Close()
+		}
+	}()
+	return s
+}
+
+func (s *testTLSServer) setCertificate(t *testing.T, certPEM, keyPEM []byte) {
+	t.Helper()
+	certificate, err := tls.X509KeyPair(certPEM, keyPEM)
+	require.NoError(t, err)
+	select {
+	case <-s.certificate:
+	default:
+	}
+	s.certificate <- certificate
+}
+
+func (s *testTLSServer) handshake(conf *tls.Config) error {
+	conf = conf.Clone()
+	conf.ServerName = "localhost"
+	conn, err := tls.Dial("tcp", s.listener.Addr().String(), conf)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = conn.Close() }()
+	// with TLS 1.3, the server verifies the client certificate after the client completes the handshake
+	_, err = conn.Read(make([]byte, 1))
+	if err != nil && !errors.Is(err, io.EOF) {
+		return err
+	}
+	return nil
+}
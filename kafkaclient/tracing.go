@@ -0,0 +1,43 @@
+package client
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/rudderlabs/rudder-go-kit/stats"
+)
+
+// TraceParentHeader is the header holding the W3C trace context of a message
+const TraceParentHeader = "traceparent"
+
+// TraceParent returns the W3C traceparent header of the message, or an empty string if it has none
+func (m Message) TraceParent() string {
+	traceParent, _ := header(m, TraceParentHeader)
+	return traceParent
+}
+
+// ReceiveWithTrace reads the next message like Receive, returning a context continuing the trace the message was
+// published with (if any) and a SpanKindConsumer span started with the given tracer.
+// The span must be ended by the caller once done with the message. No span is returned along with an error.
+func (c *Consumer) ReceiveWithTrace(ctx context.Context, tracer stats.Tracer) (context.Context, Message, stats.TraceSpan, error) {
+	msg, err := c.Receive(ctx)
+	if err != nil {
+		return ctx, Message{}, nil, err
+	}
+	ctx, span := startConsumerSpan(ctx, tracer, msg)
+	return ctx, msg, span, nil
+}
+
+// startConsumerSpan starts a SpanKindConsumer span, child of the trace context carried by the message
+func startConsumerSpan(ctx context.Context, tracer stats.Tracer, msg Message) (context.Context, stats.TraceSpan) {
+	if traceParent := msg.TraceParent(); traceParent != "" {
+		ctx = stats.InjectTraceParentIntoContext(ctx, traceParent)
+	}
+	return tracer.Start(ctx, "kafka.receive", stats.SpanKindConsumer, stats.SpanWithTags(stats.Tags{
+		"messaging.system":                   "kafka",
+		"messaging.operation":                "receive",
+		"messaging.destination.name":         msg.Topic,
+		"messaging.destination.partition.id": strconv.Itoa(int(msg.Partition)),
+		"messaging.kafka.offset":             strconv.FormatInt(msg.Offset, 10),
+	}))
+}
@@ -0,0 +1,64 @@
+package client
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/rudderlabs/rudder-go-kit/stats"
+	"github.com/rudderlabs/rudder-go-kit/stats/memstats"
+)
+
+func TestTracePropagation(t *testing.T) {
+	store, err := memstats.New(memstats.WithTracing())
+	require.NoError(t, err)
+	tracer := store.NewTracer("kafkaclient")
+
+	ctx, producerSpan := tracer.Start(context.Background(), "publish", stats.SpanKindProducer)
+	traceParent := stats.GetTraceParentFromContext(ctx)
+	require.NotEmpty(t, traceParent)
+
+	t.Run("headers", func(t *testing.T) {
+		require.Empty(t, headers(Message{}, ""))
+
+		h := headers(Message{Headers: []MessageHeader{{Key: "a", Value: []byte("b")}}}, traceParent)
+		require.Len(t, h, 2)
+		require.Equal(t, "a", h[0].Key)
+		require.Equal(t, TraceParentHeader, h[1].Key)
+		require.Equal(t, traceParent, string(h[1].Value))
+
+		// an existing traceparent is preserved
+		h = headers(Message{Headers: []MessageHeader{{Key: TraceParentHeader, Value: []byte("other")}}}, traceParent)
+		require.Len(t, h, 1)
+		require.Equal(t, "other", string(h[0].Value))
+	})
+
+	t.Run("consumer span", func(t *testing.T) {
+		msg := Message{
+			Topic:     "some-topic",
+			Partition: 1,
+			Offset:    42,
+			Headers:   []MessageHeader{{Key: TraceParentHeader, Value: []byte(traceParent)}},
+		}
+		require.Equal(t, traceParent, msg.TraceParent())
+
+		consumerCtx, consumerSpan := startConsumerSpan(context.Background(), tracer, msg)
+		require.Equal(t, producerSpan.SpanContext().TraceID(), consumerSpan.SpanContext().TraceID())
+		require.Equal(t, consumerSpan.SpanContext().SpanID(), tracer.SpanFromContext(consumerCtx).SpanContext().SpanID())
+		consumerSpan.End()
+
+		spans, err := store.Spans()
+		require.NoError(t, err)
+		require.Len(t, spans, 1)
+		require.Equal(t, "kafka.receive", spans[0].Name)
+		require.Equal(t, int(stats.SpanKindConsumer), spans[0].SpanKind)
+		require.Equal(t, producerSpan.SpanContext().SpanID().String(), spans[0].Parent.SpanID)
+	})
+
+	t.Run("no trace context", func(t *testing.T) {
+		_, span := startConsumerSpan(context.Background(), tracer, Message{Topic: "some-topic"})
+		require.NotEqual(t, producerSpan.SpanContext().TraceID(), span.SpanContext().TraceID())
+		span.End()
+	})
+}
@@ -0,0 +1,522 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"math"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/segmentio/kafka-go/protocol"
+
+	"github.com/rudderlabs/rudder-go-kit/stats"
+)
+
+var (
+	// ErrNotTransactional is returned by the transactional API of producers configured without a transactional ID
+	ErrNotTransactional = errors.New("producer is not transactional")
+	// ErrNoTransaction is returned when a transactional operation is performed without an ongoing transaction
+	ErrNoTransaction = errors.New("no ongoing transaction")
+	// ErrTransactionInProgress is returned when beginning a transaction while another one is ongoing
+	ErrTransactionInProgress = errors.New("transaction already in progress")
+)
+
+// Offsets of the fields of a v2 record batch which are patched once the batch is encoded, including the 4 bytes
+// prefix holding the size of the record set
+const (
+	batchCRCOffset           = 4 + 17
+	batchAttributesOffset    = 4 + 21
+	batchProducerIDOffset    = 4 + 43
+	batchProducerEpochOffset = 4 + 51
+	batchBaseSequenceOffset  = 4 + 53
+)
+
+var crc32c = crc32.MakeTable(crc32.Castagnoli)
+
+type TransactionalProducerConfig struct {
+	ClientID string
+	// TransactionalID identifies the producer across restarts, fencing previous instances using the same ID.
+	// If empty, the producer is idempotent only and messages are published outside of transactions.
+	TransactionalID string
+	// TransactionTimeout is the time after which the coordinator aborts an ongoing transaction, defaults to 1 minute.
+	// It must not exceed the transaction.max.timeout.ms setting of the brokers.
+	TransactionTimeout time.Duration
+	// WriteTimeout is the timeout of each request, defaults to 10 seconds
+	WriteTimeout time.Duration
+	// MaxAttempts is the number of attempts for publishing a batch of messages to a partition in case of
+	// temporary errors, defaults to 3. Retries don't cause duplicates since writes are idempotent.
+	MaxAttempts int
+	Compression Compression
+	// Partitioner chooses the partition of messages, defaults to PartitionerReferenceHash
+	Partitioner Partitioner
+	// CustomPartitioner, if set, chooses the partition of messages instead of Partitioner, e.g. Murmur3Partitioner
+	CustomPartitioner PartitionerFunc
+}
+
+func (c *TransactionalProducerConfig) defaults() {
+	if c.TransactionTimeout < 1 {
+		c.TransactionTimeout = time.Minute
+	}
+	if c.WriteTimeout < 1 {
+		c.WriteTimeout = 10 * time.Second
+	}
+	if c.MaxAttempts < 1 {
+		c.MaxAttempts = 3
+	}
+}
+
+type topicPartition struct {
+	topic     string
+	partition int
+}
+
+// TransactionalProducer is an idempotent producer, writing messages with a producer ID and per-partition sequence
+// numbers so that brokers discard duplicates caused by retries.
+// If configured with a transactional ID, messages are published within transactions which can also include the
+// offsets of consumed messages, so that they are committed atomically along with the published messages.
+//
+// Topics must exist before messages are published to them. Operations are serialized, hence the producer is safe
+// for concurrent use but Publish calls do not run in parallel.
+type TransactionalProducer struct {
+	client    *kafka.Client
+	transport *kafka.Transport
+	config    TransactionalProducerConfig
+	balancer  kafka.Balancer
+
+	mu            sync.Mutex
+	producerID    int
+	producerEpoch int
+	sequences     map[topicPartition]int32
+	partitions    map[string][]int
+	initRequired  bool // set when sequences may be out of sync with the brokers after a failed write
+
+	inTxn         bool
+	txnStarted    bool // set once the coordinator knows about the ongoing transaction
+	txnPartitions map[topicPartition]struct{}
+	txnErr        error // set when the ongoing transaction failed and can only be aborted
+}
+
+// NewTransactionalProducer instantiates a new idempotent producer, transactional if a transactional ID is configured.
+// It obtains a producer ID from the brokers, fencing other producers with the same transactional ID and aborting
+// their ongoing transactions.
+func (c *Client) NewTransactionalProducer(ctx context.Context, conf TransactionalProducerConfig) (*TransactionalProducer, error) { // skipcq: CRT-P0003
+	conf.defaults()
+
+	balancer, err := newBalancer(conf.Partitioner, conf.CustomPartitioner, 0)
+	if err != nil {
+		return nil, err
+	}
+	transport, err := c.transport(conf.ClientID)
+	if err != nil {
+		return nil, err
+	}
+	p := &TransactionalProducer{
+		client: &kafka.Client{
+			Addr:      kafka.TCP(c.addresses...),
+			Timeout:   conf.WriteTimeout,
+			Transport: transport,
+		},
+		transport:  transport,
+		config:     conf,
+		balancer:   balancer,
+		partitions: make(map[string][]int),
+	}
+	if err := p.initProducerID(ctx); err != nil {
+		transport.CloseIdleConnections()
+		return nil, err
+	}
+	return p, nil
+}
+
+// Close aborts the ongoing transaction, if any, and closes the connections of the producer
+func (p *TransactionalProducer) Close(ctx context.Context) error {
+	p.mu.Lock()
+	inTxn := p.inTxn
+	p.mu.Unlock()
+
+	var err error
+	if inTxn {
+		err = p.AbortTxn(ctx)
+	}
+	p.transport.CloseIdleConnections()
+	return err
+}
+
+// BeginTxn starts a transaction. Messages published and offsets sent until the transaction is committed or aborted
+// are part of it.
+func (p *TransactionalProducer) BeginTxn() error {
+	if p.config.TransactionalID == "" {
+		return ErrNotTransactional
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.inTxn {
+		return ErrTransactionInProgress
+	}
+	p.inTxn = true
+	p.txnStarted = false
+	p.txnPartitions = make(map[topicPartition]struct{})
+	p.txnErr = nil
+	return nil
+}
+
+// Publish writes one or more messages, partitioned by key like with Producer.Publish.
+// For transactional producers, it must be called within a transaction, and if it fails the transaction can only be
+// aborted.
+// The W3C trace context of ctx, if any, is injected into the messages' headers, unless they already carry one.
+func (p *TransactionalProducer) Publish(ctx context.Context, msgs ...Message) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	transactional := p.config.TransactionalID != ""
+	if transactional {
+		if !p.inTxn {
+			return ErrNoTransaction
+		}
+		if p.txnErr != nil {
+			return fmt.Errorf("transaction must be aborted: %w", p.txnErr)
+		}
+	} else if p.initRequired {
+		if err := p.initProducerID(ctx); err != nil {
+			return err
+		}
+	}
+
+	err := p.publish(ctx, msgs)
+	if err != nil {
+		if transactional {
+			p.txnErr = err
+		} else {
+			p.initRequired = true
+		}
+	}
+	return err
+}
+
+func (p *TransactionalProducer) publish(ctx context.Context, msgs []Message) error {
+	traceParent := stats.GetTraceParentFromContext(ctx)
+	batches := make(map[topicPartition][]protocol.Record)
+	var order []topicPartition
+	for i := range msgs {
+		if msgs[i].Topic == "" {
+			return fmt.Errorf("no topic provided for message %d", i)
+		}
+		partitions, err := p.topicPartitions(ctx, msgs[i].Topic)
+		if err != nil {
+			return err
+		}
+		tp := topicPartition{
+			topic:     msgs[i].Topic,
+			partition: p.balancer.Balance(kafka.Message{Topic: msgs[i].Topic, Key: msgs[i].Key}, partitions...),
+		}
+		if _, ok := batches[tp]; !ok {
+			order = append(order, tp)
+		}
+		batches[tp] = append(batches[tp], protocol.Record{
+			Time:    msgs[i].Timestamp,
+			Key:     nullableBytes(msgs[i].Key),
+			Value:   nullableBytes(msgs[i].Value),
+			Headers: headers(msgs[i], traceParent),
+		})
+	}
+
+	if p.config.TransactionalID != "" {
+		if err := p.addPartitionsToTxn(ctx, order); err != nil {
+			return err
+		}
+	}
+	for _, tp := range order {
+		if err := p.produce(ctx, tp, batches[tp]); err != nil {
+			return fmt.Errorf("publishing to %s[%d]: %w", tp.topic, tp.partition, err)
+		}
+	}
+	return nil
+}
+
+// SendOffsetsToTxn adds the offsets of the given consumed messages to the transaction, so that they are committed
+// for the consumer group only if the transaction is committed.
+// The consumer should not commit these offsets itself, i.e. Consumer.Ack must not be used for these messages.
+func (p *TransactionalProducer) SendOffsetsToTxn(ctx context.Context, groupID string, msgs ...Message) error {
+	if p.config.TransactionalID == "" {
+		return ErrNotTransactional
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if !p.inTxn {
+		return ErrNoTransaction
+	}
+	if p.txnErr != nil {
+		return fmt.Errorf("transaction must be aborted: %w", p.txnErr)
+	}
+
+	err := p.sendOffsetsToTxn(ctx, groupID, msgs)
+	if err != nil {
+		p.txnErr = err
+	}
+	return err
+}
+
+func (p *TransactionalProducer) sendOffsetsToTxn(ctx context.Context, groupID string, msgs []Message) error {
+	res, err := p.client.AddOffsetsToTxn(ctx, &kafka.AddOffsetsToTxnRequest{
+		TransactionalID: p.config.TransactionalID,
+		ProducerID:      p.producerID,
+		ProducerEpoch:   p.producerEpoch,
+		GroupID:         groupID,
+	})
+	if err != nil {
+		return fmt.Errorf("adding offsets to transaction: %w", err)
+	}
+	if res.Error != nil {
+		return fmt.Errorf("adding offsets to transaction: %w", res.Error)
+	}
+	p.txnStarted = true
+
+	// committing the offset following the last consumed message of each partition
+	next := make(map[topicPartition]int64)
+	for _, msg := range msgs {
+		tp := topicPartition{topic: msg.Topic, partition: int(msg.Partition)}
+		next[tp] = max(next[tp], msg.Offset+1)
+	}
+	topics := make(map[string][]kafka.TxnOffsetCommit)
+	for tp, offset := range next {
+		topics[tp.topic] = append(topics[tp.topic], kafka.TxnOffsetCommit{Partition: tp.partition, Offset: offset})
+	}
+	commitRes, err := p.client.TxnOffsetCommit(ctx, &kafka.TxnOffsetCommitRequest{
+		TransactionalID: p.config.TransactionalID,
+		GroupID:         groupID,
+		ProducerID:      p.producerID,
+		ProducerEpoch:   p.producerEpoch,
+		GenerationID:    -1,
+		Topics:          topics,
+	})
+	if err != nil {
+		return fmt.Errorf("committing offsets in transaction: %w", err)
+	}
+	for topic, partitions := range commitRes.Topics {
+		for _, partition := range partitions {
+			if partition.Error != nil {
+				return fmt.Errorf("committing offset of %s[%d] in transaction: %w", topic, partition.Partition, partition.Error)
+			}
+		}
+	}
+	return nil
+}
+
+// CommitTxn commits the ongoing transaction, making its messages visible to consumers reading committed messages
+// only, and committing its offsets. If the transaction failed, it must be aborted instead.
+func (p *TransactionalProducer) CommitTxn(ctx context.Context) error {
+	return p.endTxn(ctx, true)
+}
+
+// AbortTxn aborts the ongoing transaction, discarding its messages and offsets
+func (p *TransactionalProducer) AbortTxn(ctx context.Context) error {
+	return p.endTxn(ctx, false)
+}
+
+func (p *TransactionalProducer) endTxn(ctx context.Context, commit bool) error {
+	if p.config.TransactionalID == "" {
+		return ErrNotTransactional
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if !p.inTxn {
+		return ErrNoTransaction
+	}
+	if commit && p.txnErr != nil {
+		return fmt.Errorf("transaction must be aborted: %w", p.txnErr)
+	}
+
+	// nothing to end if the coordinator doesn't know about the transaction yet
+	if p.txnStarted || p.txnErr != nil {
+		res, err := p.client.EndTxn(ctx, &kafka.EndTxnRequest{
+			TransactionalID: p.config.TransactionalID,
+			ProducerID:      p.producerID,
+			ProducerEpoch:   p.producerEpoch,
+			Committed:       commit,
+		})
+		if err == nil {
+			err = res.Error
+		}
+		if err != nil && !(errors.Is(err, kafka.InvalidTransactionState) && p.txnErr != nil) {
+			return fmt.Errorf("ending transaction: %w", err)
+		}
+	}
+
+	if p.txnErr != nil {
+		// bumping the epoch for resetting the sequence numbers, which may be out of sync after a failed write
+		if err := p.initProducerID(ctx); err != nil {
+			return err
+		}
+	}
+	p.inTxn = false
+	p.txnStarted = false
+	p.txnPartitions = nil
+	p.txnErr = nil
+	return nil
+}
+
+// initProducerID obtains a producer ID and epoch, resetting the sequence numbers
+func (p *TransactionalProducer) initProducerID(ctx context.Context) error {
+	res, err := p.client.InitProducerID(ctx, &kafka.InitProducerIDRequest{
+		TransactionalID:      p.config.TransactionalID,
+		TransactionTimeoutMs: int(p.config.TransactionTimeout.Milliseconds()),
+	})
+	if err != nil {
+		return fmt.Errorf("initializing producer ID: %w", err)
+	}
+	if res.Error != nil {
+		return fmt.Errorf("initializing producer ID: %w", res.Error)
+	}
+	p.producerID = res.Producer.ProducerID
+	p.producerEpoch = res.Producer.ProducerEpoch
+	p.sequences = make(map[topicPartition]int32)
+	p.initRequired = false
+	return nil
+}
+
+// topicPartitions returns the partitions of a topic, caching them
+func (p *TransactionalProducer) topicPartitions(ctx context.Context, topic string) ([]int, error) {
+	if partitions, ok := p.partitions[topic]; ok {
+		return partitions, nil
+	}
+	res, err := p.client.Metadata(ctx, &kafka.MetadataRequest{Topics: []string{topic}})
+	if err != nil {
+		return nil, fmt.Errorf("getting metadata of topic %q: %w", topic, err)
+	}
+	if len(res.Topics) != 1 {
+		return nil, fmt.Errorf("getting metadata of topic %q: %w", topic, kafka.UnknownTopicOrPartition)
+	}
+	if res.Topics[0].Error != nil {
+		return nil, fmt.Errorf("getting metadata of topic %q: %w", topic, res.Topics[0].Error)
+	}
+	partitions := make([]int, len(res.Topics[0].Partitions))
+	for i, partition := range res.Topics[0].Partitions {
+		partitions[i] = partition.ID
+	}
+	if len(partitions) == 0 {
+		return nil, fmt.Errorf("getting metadata of topic %q: %w", topic, kafka.UnknownTopicOrPartition)
+	}
+	slices.Sort(partitions)
+	p.partitions[topic] = partitions
+	return partitions, nil
+}
+
+// addPartitionsToTxn registers the partitions not yet part of the ongoing transaction
+func (p *TransactionalProducer) addPartitionsToTxn(ctx context.Context, tps []topicPartition) error {
+	topics := make(map[string][]kafka.AddPartitionToTxn)
+	for _, tp := range tps {
+		if _, ok := p.txnPartitions[tp]; !ok {
+			topics[tp.topic] = append(topics[tp.topic], kafka.AddPartitionToTxn{Partition: tp.partition})
+		}
+	}
+	if len(topics) == 0 {
+		return nil
+	}
+
+	res, err := p.client.AddPartitionsToTxn(ctx, &kafka.AddPartitionsToTxnRequest{
+		TransactionalID: p.config.TransactionalID,
+		ProducerID:      p.producerID,
+		ProducerEpoch:   p.producerEpoch,
+		Topics:          topics,
+	})
+	if err != nil {
+		return fmt.Errorf("adding partitions to transaction: %w", err)
+	}
+	p.txnStarted = true
+	for topic, partitions := range res.Topics {
+		for _, partition := range partitions {
+			if partition.Error != nil {
+				return fmt.Errorf("adding %s[%d] to transaction: %w", topic, partition.Partition, partition.Error)
+			}
+		}
+	}
+	for _, tp := range tps {
+		p.txnPartitions[tp] = struct{}{}
+	}
+	return nil
+}
+
+// produce writes a batch of records to a partition, retrying temporary errors with the same sequence number
+func (p *TransactionalProducer) produce(ctx context.Context, tp topicPartition, records []protocol.Record) error {
+	sequence := p.sequences[tp]
+	batch, err := p.encodeBatch(records, sequence)
+	if err != nil {
+		return err
+	}
+
+	for attempt := 1; ; attempt++ {
+		res, err := p.client.RawProduce(ctx, &kafka.RawProduceRequest{
+			Topic:           tp.topic,
+			Partition:       tp.partition,
+			RequiredAcks:    kafka.RequireAll,
+			TransactionalID: p.config.TransactionalID,
+			RawRecords:      protocol.RawRecordSet{Reader: bytes.NewReader(batch)},
+		})
+		if err == nil {
+			err = res.Error
+		}
+		if err == nil || errors.Is(err, kafka.DuplicateSequenceNumber) {
+			// sequence numbers wrap around once they reach the maximum value
+			p.sequences[tp] = int32((int64(sequence) + int64(len(records))) % (math.MaxInt32 + 1))
+			return nil
+		}
+		if attempt >= p.config.MaxAttempts || !isRetriable(err) {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(time.Duration(attempt) * 100 * time.Millisecond):
+		}
+	}
+}
+
+// encodeBatch encodes the records in a v2 record batch carrying the producer ID, epoch and base sequence
+func (p *TransactionalProducer) encodeBatch(records []protocol.Record, baseSequence int32) ([]byte, error) {
+	attributes := protocol.Attributes(p.config.Compression) & 0x7
+	if p.config.TransactionalID != "" {
+		attributes |= protocol.Transactional
+	}
+	rs := protocol.RecordSet{
+		Version:    2,
+		Attributes: attributes,
+		Records:    protocol.NewRecordReader(records...),
+	}
+	var buf bytes.Buffer
+	if _, err := rs.WriteTo(&buf); err != nil {
+		return nil, fmt.Errorf("encoding records: %w", err)
+	}
+
+	// the encoder doesn't support idempotent producers, hence setting the producer fields afterward
+	b := buf.Bytes()
+	binary.BigEndian.PutUint64(b[batchProducerIDOffset:], uint64(p.producerID))
+	binary.BigEndian.PutUint16(b[batchProducerEpochOffset:], uint16(p.producerEpoch))
+	binary.BigEndian.PutUint32(b[batchBaseSequenceOffset:], uint32(baseSequence))
+	binary.BigEndian.PutUint32(b[batchCRCOffset:], crc32.Checksum(b[batchAttributesOffset:], crc32c))
+	return b, nil
+}
+
+func isRetriable(err error) bool {
+	var kafkaErr kafka.Error
+	if errors.As(err, &kafkaErr) {
+		return kafkaErr.Temporary()
+	}
+	return isErrTemporary(err)
+}
+
+func nullableBytes(b []byte) protocol.Bytes {
+	if b == nil {
+		return nil
+	}
+	return protocol.NewBytes(b)
+}
@@ -0,0 +1,81 @@
+package client
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"testing"
+
+	"github.com/segmentio/kafka-go/protocol"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTransactionalProducerEncodeBatch(t *testing.T) {
+	for _, tc := range []struct {
+		name            string
+		transactionalID string
+		compression     Compression
+	}{
+		{name: "idempotent"},
+		{name: "transactional", transactionalID: "some-txn"},
+		{name: "compressed", transactionalID: "some-txn", compression: CompressionZstd},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			p := &TransactionalProducer{
+				config:        TransactionalProducerConfig{TransactionalID: tc.transactionalID, Compression: tc.compression},
+				producerID:    1234,
+				producerEpoch: 5,
+			}
+			b, err := p.encodeBatch([]protocol.Record{
+				{Key: protocol.NewBytes([]byte("key-1")), Value: protocol.NewBytes([]byte("value-1"))},
+				{Value: protocol.NewBytes([]byte("value-2")), Headers: []protocol.Header{{Key: "h", Value: []byte("v")}}},
+			}, 42)
+			require.NoError(t, err)
+
+			require.EqualValues(t, 1234, binary.BigEndian.Uint64(b[batchProducerIDOffset:]))
+			require.EqualValues(t, 5, binary.BigEndian.Uint16(b[batchProducerEpochOffset:]))
+			require.EqualValues(t, 42, binary.BigEndian.Uint32(b[batchBaseSequenceOffset:]))
+
+			// decoding the batch validates its checksum
+			var rs protocol.RecordSet
+			_, err = rs.ReadFrom(bytes.NewReader(b))
+			require.NoError(t, err)
+			require.Equal(t, tc.transactionalID != "", rs.Attributes.Transactional())
+			require.Equal(t, tc.compression, rs.Attributes.Compression())
+
+			r, err := rs.Records.ReadRecord()
+			require.NoError(t, err)
+			key, err := io.ReadAll(r.Key)
+			require.NoError(t, err)
+			require.Equal(t, "key-1", string(key))
+
+			r, err = rs.Records.ReadRecord()
+			require.NoError(t, err)
+			require.Nil(t, r.Key)
+			value, err := io.ReadAll(r.Value)
+			require.NoError(t, err)
+			require.Equal(t, "value-2", string(value))
+			require.Equal(t, []protocol.Header{{Key: "h", Value: []byte("v")}}, r.Headers)
+
+			_, err = rs.Records.ReadRecord()
+			require.ErrorIs(t, err, io.EOF)
+		})
+	}
+
+	t.Run("not transactional", func(t *testing.T) {
+		p := &TransactionalProducer{}
+		require.ErrorIs(t, p.BeginTxn(), ErrNotTransactional)
+		require.ErrorIs(t, p.CommitTxn(t.Context()), ErrNotTransactional)
+		require.ErrorIs(t, p.AbortTxn(t.Context()), ErrNotTransactional)
+		require.ErrorIs(t, p.SendOffsetsToTxn(t.Context(), "group"), ErrNotTransactional)
+	})
+
+	t.Run("no transaction", func(t *testing.T) {
+		p := &TransactionalProducer{config: TransactionalProducerConfig{TransactionalID: "some-txn"}}
+		require.ErrorIs(t, p.Publish(t.Context(), Message{Topic: "topic"}), ErrNoTransaction)
+		require.ErrorIs(t, p.CommitTxn(t.Context()), ErrNoTransaction)
+		require.NoError(t, p.BeginTxn())
+		require.ErrorIs(t, p.BeginTxn(), ErrTransactionInProgress)
+		require.NoError(t, p.AbortTxn(t.Context())) // nothing was sent to the coordinator
+	})
+}
@@ -40,6 +40,20 @@ func (p *aggregatedCollector) Add(c Collector) error {
 	return nil
 }
 
+// Remove removes the collector with the same ID, zeroing its stats
+func (p *aggregatedCollector) Remove(c Collector) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if _, ok := p.c[c.ID()]; !ok {
+		return
+	}
+	delete(p.c, c.ID())
+	if p.gaugeFunc != nil {
+		c.Zero(p.gaugeFunc)
+	}
+}
+
 func (p *aggregatedCollector) Run(ctx context.Context) {
 	defer p.allZero()
 	p.allCollect()
@@ -0,0 +1,38 @@
+package stats
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type testCollector struct {
+	id  string
+	val uint64
+}
+
+func (c *testCollector) Collect(gaugeFunc gaugeTagsFunc) { gaugeFunc(c.id, nil, c.val) }
+func (c *testCollector) Zero(gaugeFunc gaugeTagsFunc)    { gaugeFunc(c.id, nil, 0) }
+func (c *testCollector) ID() string                      { return c.id }
+
+func TestAggregatedCollector(t *testing.T) {
+	gauges := make(map[string]uint64)
+	ac := &aggregatedCollector{gaugeFunc: func(key string, _ Tags, val uint64) { gauges[key] = val }}
+
+	c1, c2 := &testCollector{id: "c1", val: 1}, &testCollector{id: "c2", val: 2}
+	require.NoError(t, ac.Add(c1))
+	require.NoError(t, ac.Add(c2))
+	require.Error(t, ac.Add(&testCollector{id: "c1"}), "IDs must be unique")
+
+	ac.allCollect()
+	require.Equal(t, map[string]uint64{"c1": 1, "c2": 2}, gauges)
+
+	ac.Remove(c1)
+	require.Equal(t, map[string]uint64{"c1": 0, "c2": 2}, gauges, "the stats of a removed collector are zeroed")
+	c1.val, c2.val = 10, 20
+	ac.allCollect()
+	require.Equal(t, map[string]uint64{"c1": 0, "c2": 20}, gauges, "a removed collector is not collected anymore")
+
+	ac.Remove(c1) // removing twice is a no-op
+	require.NoError(t, ac.Add(c1), "a removed collector can be added again")
+}
@@ -75,6 +75,23 @@ func WithTracingSamplingRate(rate float64) TracerProviderOption {
 	}
 }
 
+// WithSampler allows to set a custom sampler for the tracer provider.
+// It takes precedence over WithTracingSamplingRate.
+func WithSampler(sampler sdktrace.Sampler) TracerProviderOption {
+	return func(c *tracerProviderConfig) {
+		c.sampler = sampler
+	}
+}
+
+// WithErrorSpansExport exports spans ending with an error status even if they were not sampled, as long as enabled
+// returns true. Since the status of a span is known only once it ends, this works only for spans the sampler
+// decided to record (i.e. sdktrace.RecordOnly).
+func WithErrorSpansExport(enabled func() bool) TracerProviderOption {
+	return func(c *tracerProviderConfig) {
+		c.exportErrorSpans = enabled
+	}
+}
+
 // WithTracingSyncer lets you register the exporter with a synchronous SimpleSpanProcessor (e.g. instead of a batching
 // asynchronous one).
 // NOT RECOMMENDED FOR PRODUCTION USE (use for testing and debugging only).
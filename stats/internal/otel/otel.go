@@ -125,18 +125,26 @@ func (m *Manager) buildTracerProviderOptions(
 	c *config,
 	res *resource.Resource, exp sdktrace.SpanExporter,
 ) []sdktrace.TracerProviderOption {
+	sampler := c.tracerProviderConfig.sampler
+	if sampler == nil {
+		sampler = sdktrace.TraceIDRatioBased(c.tracerProviderConfig.samplingRate)
+	}
 	opts := []sdktrace.TracerProviderOption{
 		sdktrace.WithResource(res),
-		sdktrace.WithSampler(sdktrace.TraceIDRatioBased(c.tracerProviderConfig.samplingRate)),
+		sdktrace.WithSampler(sampler),
 	}
 
+	var sp sdktrace.SpanProcessor
 	if c.tracerProviderConfig.withSyncer {
-		opts = append(opts, sdktrace.WithSyncer(exp))
+		sp = sdktrace.NewSimpleSpanProcessor(exp)
 	} else {
-		opts = append(opts, sdktrace.WithSpanProcessor(sdktrace.NewBatchSpanProcessor(exp)))
+		sp = sdktrace.NewBatchSpanProcessor(exp)
+	}
+	if c.tracerProviderConfig.exportErrorSpans != nil {
+		sp = &errorSpanProcessor{SpanProcessor: sp, enabled: c.tracerProviderConfig.exportErrorSpans}
 	}
 
-	return opts
+	return append(opts, sdktrace.WithSpanProcessor(sp))
 }
 
 func (m *Manager) buildMeterProvider(
@@ -291,6 +299,8 @@ type tracerProviderConfig struct {
 	enabled            bool
 	global             bool
 	samplingRate       float64
+	sampler            sdktrace.Sampler
+	exportErrorSpans   func() bool
 	textMapPropagator  propagation.TextMapPropagator
 	customSpanExporter SpanExporter
 	withSyncer         bool
@@ -0,0 +1,31 @@
+package otel
+
+import (
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// errorSpanProcessor wraps a span processor so that recorded but not sampled spans ending with an error status are
+// exported as if they were sampled.
+type errorSpanProcessor struct {
+	sdktrace.SpanProcessor
+	enabled func() bool
+}
+
+func (p *errorSpanProcessor) OnEnd(s sdktrace.ReadOnlySpan) {
+	if !s.SpanContext().IsSampled() && s.Status().Code == codes.Error && p.enabled() {
+		s = sampledSpan{ReadOnlySpan: s}
+	}
+	p.SpanProcessor.OnEnd(s)
+}
+
+// sampledSpan overrides the span context of a span, flagging it as sampled
+type sampledSpan struct {
+	sdktrace.ReadOnlySpan
+}
+
+func (s sampledSpan) SpanContext() trace.SpanContext {
+	sc := s.ReadOnlySpan.SpanContext()
+	return sc.WithTraceFlags(sc.TraceFlags().WithSampled(true))
+}
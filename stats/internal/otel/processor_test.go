@@ -0,0 +1,57 @@
+package otel
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestErrorSpansExport(t *testing.T) {
+	exp := tracetest.NewInMemoryExporter()
+	res, err := NewResource(t.Name(), "v1.2.3")
+	require.NoError(t, err)
+
+	var enabled atomic.Bool
+	enabled.Store(true)
+	recordOnly := sdktrace.Sampler(recordOnlySampler{})
+
+	var om Manager
+	tp, _, err := om.Setup(
+		context.Background(), res,
+		WithCustomTracerProvider(exp, WithSampler(recordOnly), WithErrorSpansExport(enabled.Load), WithTracingSyncer()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { require.NoError(t, om.Shutdown(context.Background())) })
+
+	tracer := tp.Tracer("my-tracer")
+	_, span := tracer.Start(context.Background(), "ok-span")
+	span.SetStatus(codes.Ok, "")
+	span.End()
+	_, span = tracer.Start(context.Background(), "error-span")
+	span.SetStatus(codes.Error, "boom")
+	span.End()
+
+	spans := exp.GetSpans()
+	require.Len(t, spans, 1)
+	require.Equal(t, "error-span", spans[0].Name)
+	require.True(t, spans[0].SpanContext.IsSampled())
+
+	enabled.Store(false)
+	_, span = tracer.Start(context.Background(), "another-error-span")
+	span.SetStatus(codes.Error, "boom")
+	span.End()
+	require.Len(t, exp.GetSpans(), 1)
+}
+
+type recordOnlySampler struct{}
+
+func (recordOnlySampler) ShouldSample(sdktrace.SamplingParameters) sdktrace.SamplingResult {
+	return sdktrace.SamplingResult{Decision: sdktrace.RecordOnly}
+}
+
+func (recordOnlySampler) Description() string { return "RecordOnly" }
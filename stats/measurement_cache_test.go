@@ -69,7 +69,7 @@ func TestCanonicalMeasurementIdentity(t *testing.T) {
 		},
 	} {
 		t.Run(tc.desc, func(t *testing.T) {
-			gotName, gotAttrs := s.canonicalMeasurementIdentity(tc.name, tc.tags)
+			gotName, gotAttrs := s.canonicalMeasurementIdentity(tc.name, tc.tags, nil)
 			require.Equal(t, tc.wantName, gotName)
 			require.Equal(t, tc.wantTags, attrsToMap(gotAttrs), "attribute set mirrors the surviving tags")
 		})
@@ -81,7 +81,7 @@ func TestCanonicalMeasurementIdentity(t *testing.T) {
 // key cardinality is asserted).
 func TestCanonicalMeasurementIdentityDedup(t *testing.T) {
 	s := &otelStats{logger: logger.NOP}
-	_, gotAttrs := s.canonicalMeasurementIdentity("lat", Tags{"a.b": "1", "a_b": "2"})
+	_, gotAttrs := s.canonicalMeasurementIdentity("lat", Tags{"a.b": "1", "a_b": "2"}, nil)
 	got := attrsToMap(gotAttrs)
 	require.Len(t, got, 1, "both raw keys sanitize to a_b")
 	require.Contains(t, []string{"1", "2"}, got["a_b"])
@@ -94,7 +94,7 @@ func TestCanonicalMeasurementIdentityDedup(t *testing.T) {
 func TestMeasurementCacheKeyIsLossless(t *testing.T) {
 	s := &otelStats{logger: logger.NOP, config: statsConfig{excludedTags: map[string]struct{}{"drop": {}}}}
 	key := func(name string, tags Tags) measurementCacheKey {
-		n, attrs := s.canonicalMeasurementIdentity(name, tags)
+		n, attrs := s.canonicalMeasurementIdentity(name, tags, nil)
 		return measurementCacheKey{name: n, attrs: attrs.Equivalent()}
 	}
 
@@ -130,7 +130,7 @@ func TestMeasurementCacheKeyIsLossless(t *testing.T) {
 func TestMeasurementCacheKeyUniquenessHighCardinality(t *testing.T) {
 	s := &otelStats{logger: logger.NOP}
 	keyOf := func(tags Tags) measurementCacheKey {
-		n, attrs := s.canonicalMeasurementIdentity("series", tags)
+		n, attrs := s.canonicalMeasurementIdentity("series", tags, nil)
 		return measurementCacheKey{name: n, attrs: attrs.Equivalent()}
 	}
 
@@ -22,8 +22,9 @@ import (
 )
 
 var (
-	_ stats.Stats       = (*Store)(nil)
-	_ stats.Measurement = (*Measurement)(nil)
+	_ stats.Stats                 = (*Store)(nil)
+	_ stats.CollectorUnregisterer = (*Store)(nil)
+	_ stats.Measurement           = (*Measurement)(nil)
 )
 
 type Store struct {
@@ -361,6 +362,12 @@ func (s *Store) RegisterCollector(c stats.Collector) error {
 	return nil
 }
 
+func (s *Store) UnregisterCollector(c stats.Collector) {
+	c.Zero(func(key string, tags stats.Tags, val uint64) {
+		s.NewTaggedStat(key, stats.GaugeType, tags).Gauge(val)
+	})
+}
+
 // getKey maps name and tags, to a store lookup key.
 func (*Store) getKey(name string, tags stats.Tags) string {
 	return name + tags.String()
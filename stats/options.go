@@ -13,6 +13,7 @@ type statsConfig struct {
 	instanceName        string
 	namespaceIdentifier string
 	excludedTags        map[string]struct{}
+	views               *metricViews
 
 	periodicStatsConfig     periodicStatsConfig
 	defaultHistogramBuckets []float64
@@ -52,6 +53,7 @@ func WithDefaultHistogramBuckets(buckets []float64) Option {
 }
 
 // WithHistogramBuckets sets the histogram buckets for a measurement.
+// Buckets configured through Stats.views.<histogramName>.buckets take precedence.
 func WithHistogramBuckets(histogramName string, buckets []float64) Option {
 	return func(c *statsConfig) {
 		if c.histogramBuckets == nil {
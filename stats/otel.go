@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"maps"
 	"net/http"
 	"runtime"
 	"strings"
@@ -97,8 +98,10 @@ func (s *otelStats) Start(ctx context.Context, goFactory GoRoutineFactory) error
 	options := []otel.Option{otel.WithInsecure(), otel.WithLogger(s.logger)}
 	if s.otelConfig.tracesEndpoint != "" {
 		s.traceBaseAttributes = attrs
+		sampler := newTraceSampler(s.otelConfig.tracingSampling)
 		tpOpts := []otel.TracerProviderOption{
-			otel.WithTracingSamplingRate(s.otelConfig.tracingSamplingRate),
+			otel.WithSampler(sampler),
+			otel.WithErrorSpansExport(sampler.exportErrorSpans),
 		}
 		if s.otelConfig.withTracingSyncer {
 			tpOpts = append(tpOpts, otel.WithTracingSyncer())
@@ -145,8 +148,12 @@ func (s *otelStats) Start(ctx context.Context, goFactory GoRoutineFactory) error
 			)
 		}
 	}
-	if len(s.config.histogramBuckets) > 0 {
-		for histogramName, buckets := range s.config.histogramBuckets {
+	// Bucket overrides coming from the metric views take precedence over the ones provided via WithHistogramBuckets.
+	histogramBuckets := make(map[string][]float64, len(s.config.histogramBuckets))
+	maps.Copy(histogramBuckets, s.config.histogramBuckets)
+	maps.Copy(histogramBuckets, s.config.views.histogramBuckets())
+	if len(histogramBuckets) > 0 {
+		for histogramName, buckets := range histogramBuckets {
 			// Only apply explicit bucket config if not already configured as exponential
 			if _, isExponential := s.config.exponentialHistograms[histogramName]; !isExponential {
 				meterProviderOptions = append(
@@ -222,7 +229,11 @@ func (s *otelStats) Start(ctx context.Context, goFactory GoRoutineFactory) error
 		s.collectorAggregator.Run(backgroundCollectionCtx)
 	})
 
-	if s.config.periodicStatsConfig.enabled {
+	if s.config.periodicStatsConfig.enabled && s.config.periodicStatsConfig.collector == runtimeMetricsCollector {
+		if err := s.collectorAggregator.Add(s.config.periodicStatsConfig.newRuntimeMetricsCollector(s)); err != nil {
+			return fmt.Errorf("failed to register runtime metrics collector: %w", err)
+		}
+	} else if s.config.periodicStatsConfig.enabled {
 		s.runtimeStatsCollector = newRuntimeStatsCollector(gaugeFunc)
 		s.runtimeStatsCollector.PauseDur = time.Duration(s.config.periodicStatsConfig.statsCollectionInterval) * time.Second
 		s.runtimeStatsCollector.EnableCPU = s.config.periodicStatsConfig.enableCPUStats
@@ -250,6 +261,10 @@ func (s *otelStats) RegisterCollector(c Collector) error {
 	return s.collectorAggregator.Add(c)
 }
 
+func (s *otelStats) UnregisterCollector(c Collector) {
+	s.collectorAggregator.Remove(c)
+}
+
 func (s *otelStats) Stop() {
 	if !s.config.enabled.Load() {
 		return
@@ -349,10 +364,15 @@ func (s *otelStats) getMeasurement(name, statType string, tags Tags) Measurement
 		return s.getNoOpMeasurement(statType)
 	}
 
+	view := s.config.views.get(name)
+	if view != nil && view.disabled {
+		return s.getNoOpMeasurement(statType)
+	}
+
 	// canonicalMeasurementIdentity returns the canonical OTel attribute set so the gauge cache can key on
 	// the SDK's own attribute identity (instead of a lossy string from tags.String()) and every wrapper can
 	// record against a prebuilt attribute set.
-	name, attrs := s.canonicalMeasurementIdentity(name, tags)
+	name, attrs := s.canonicalMeasurementIdentity(view.name(name), tags, view)
 
 	switch statType {
 	case CountType:
@@ -398,7 +418,8 @@ func newOTelMeasurement(statType string, attrs attribute.Set) *otelMeasurement {
 // attribute set used both to record the measurement and to key its cache. Keying the cache on the
 // attribute set's identity (attribute.Distinct) keeps distinct series distinct — unlike the
 // export-oriented Tags.String(), whose ':'→'-' sanitisation is lossy and can merge "a:b" with "a-b".
-func (s *otelStats) canonicalMeasurementIdentity(name string, tags Tags) (string, attribute.Set) {
+// Tags dropped by the metric view (if any) are left out of the attribute set.
+func (s *otelStats) canonicalMeasurementIdentity(name string, tags Tags, view *metricView) (string, attribute.Set) {
 	if strings.Trim(name, " ") == "" {
 		byteArr := make([]byte, 2048)
 		n := runtime.Stack(byteArr, false)
@@ -431,6 +452,9 @@ func (s *otelStats) canonicalMeasurementIdentity(name string, tags Tags) (string
 		if _, ok := s.config.excludedTags[sanitizedKey]; ok {
 			continue
 		}
+		if !view.keepTag(k, sanitizedKey) {
+			continue
+		}
 		if _, ok := s.resourceAttrs[sanitizedKey]; ok {
 			s.logger.Warnn(
 				"removing tag for measurement since it is a resource attribute",
@@ -542,7 +566,7 @@ func buildOTelInstrument[T any](
 
 type otelStatsConfig struct {
 	tracesEndpoint           string
-	tracingSamplingRate      float64
+	tracingSampling          tracingSamplingConfig
 	withTracingSyncer        bool
 	withOTLPHTTP             bool
 	metricsEndpoint          string
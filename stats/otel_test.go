@@ -875,6 +875,87 @@ func TestPrometheusDuplicatedAttributes(t *testing.T) {
 	), metrics[metricName].Metric[0].Label, "Got %+v", metrics[metricName].Metric[0].Label)
 }
 
+func TestOTelViews(t *testing.T) {
+	c := config.New()
+	c.Set("INSTANCE_ID", "my-instance-id")
+	c.Set("OpenTelemetry.enabled", true)
+	c.Set("OpenTelemetry.metrics.prometheus.enabled", true)
+	c.Set("OpenTelemetry.metrics.exportInterval", 30*time.Millisecond)
+	c.Set("RuntimeStats.enabled", false)
+	c.Set("Stats.views", map[string]any{
+		"renamed_counter": map[string]any{"rename": "new_counter", "dropTags": []string{"workspaceId"}},
+		"allowed_counter": map[string]any{"allowTags": "destType, source.id"},
+		"disabled_gauge":  map[string]any{"disabled": true},
+		"custom_hist":     map[string]any{"buckets": []any{1, 5, 10}},
+	})
+	r := prometheus.NewRegistry()
+	s := NewStats(c, logger.NewFactory(c), metric.NewManager(),
+		WithServiceName(t.Name()), WithPrometheusRegistry(r, r),
+		WithHistogramBuckets("custom_hist", []float64{100, 200}),
+	)
+	require.NoError(t, s.Start(context.Background(), DefaultGoRoutineFactory))
+	t.Cleanup(s.Stop)
+
+	s.NewTaggedStat("renamed_counter", CountType, Tags{"workspaceId": "ws", "destType": "dt"}).Increment()
+	s.NewTaggedStat("allowed_counter", CountType, Tags{"workspaceId": "ws", "destType": "dt", "source.id": "s"}).Increment()
+	s.NewStat("disabled_gauge", GaugeType).Gauge(1)
+	s.NewStat("custom_hist", HistogramType).Observe(7)
+
+	gather := func() map[string]*promClient.MetricFamily {
+		mfs, err := r.Gather()
+		require.NoError(t, err)
+		metrics := make(map[string]*promClient.MetricFamily, len(mfs))
+		for _, mf := range mfs {
+			metrics[mf.GetName()] = mf
+		}
+		return metrics
+	}
+	labels := func(m *promClient.Metric) map[string]string {
+		res := make(map[string]string)
+		for _, l := range m.GetLabel() {
+			res[l.GetName()] = l.GetValue()
+		}
+		return res
+	}
+
+	metrics := gather()
+	require.NotContains(t, metrics, "renamed_counter")
+	require.Contains(t, metrics, "new_counter")
+	require.NotContains(t, labels(metrics["new_counter"].GetMetric()[0]), "workspaceId")
+	require.Equal(t, "dt", labels(metrics["new_counter"].GetMetric()[0])["destType"])
+
+	require.Contains(t, metrics, "allowed_counter")
+	allowedLabels := labels(metrics["allowed_counter"].GetMetric()[0])
+	require.NotContains(t, allowedLabels, "workspaceId")
+	require.Equal(t, "dt", allowedLabels["destType"])
+	require.Equal(t, "s", allowedLabels["source_id"])
+
+	require.NotContains(t, metrics, "disabled_gauge")
+
+	require.Contains(t, metrics, "custom_hist")
+	var bounds []float64
+	for _, b := range metrics["custom_hist"].GetMetric()[0].GetHistogram().GetBucket() {
+		bounds = append(bounds, b.GetUpperBound())
+	}
+	require.Equal(t, []float64{1, 5, 10}, bounds, "views should take precedence over WithHistogramBuckets")
+
+	t.Run("hot reload", func(t *testing.T) {
+		c.Set("Stats.views", map[string]any{
+			"disabled_gauge":  map[string]any{"disabled": false, "rename": "enabled_gauge"},
+			"renamed_counter": map[string]any{"disabled": true},
+		})
+		s.NewStat("disabled_gauge", GaugeType).Gauge(2)
+		s.NewTaggedStat("renamed_counter", CountType, Tags{"workspaceId": "ws", "destType": "dt"}).Increment()
+
+		metrics := gather()
+		require.NotContains(t, metrics, "disabled_gauge")
+		require.Contains(t, metrics, "enabled_gauge")
+		require.EqualValues(t, 2, metrics["enabled_gauge"].GetMetric()[0].GetGauge().GetValue())
+		require.NotContains(t, metrics, "renamed_counter")
+		require.EqualValues(t, 1, metrics["new_counter"].GetMetric()[0].GetCounter().GetValue())
+	})
+}
+
 func TestWithExponentialHistogramMaxScale(t *testing.T) {
 	// The option sets the statsConfig field; unset, it is the coarse default of 0.
 	var cfg statsConfig
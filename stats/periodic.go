@@ -21,7 +21,9 @@ type periodicStatsConfig struct {
 	enableCPUStats          bool
 	enableMemStats          bool
 	enableGCStats           bool
-	metricManager           metric.Manager
+	// collector is the runtime stats collector to use, either memstats (default) or runtime_metrics
+	collector     string
+	metricManager metric.Manager
 }
 
 // runtimeStatsCollector implements the periodic grabbing of informational data from the
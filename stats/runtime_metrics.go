@@ -0,0 +1,262 @@
+package stats
+
+import (
+	"math"
+	"runtime/metrics"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	// memStatsCollector is the legacy runtime collector, polling runtime.ReadMemStats
+	memStatsCollector = "memstats"
+	// runtimeMetricsCollector is the runtime collector based on runtime/metrics
+	runtimeMetricsCollector = "runtime_metrics"
+)
+
+type runtimeMetricsCategory int
+
+const (
+	runtimeMetricsCPU runtimeMetricsCategory = iota
+	runtimeMetricsMem
+	runtimeMetricsGC
+)
+
+// runtimeMetricsSamples are the runtime/metrics collected by RuntimeMetricsCollector, grouped like the stats of the
+// memstats collector so that the same RuntimeStats.enable* settings apply.
+// Metrics not supported by the running Go version are skipped.
+var runtimeMetricsSamples = []struct {
+	name     string
+	category runtimeMetricsCategory
+}{
+	// scheduler
+	{"/sched/goroutines:goroutines", runtimeMetricsCPU},
+	{"/sched/goroutines/runnable:goroutines", runtimeMetricsCPU},
+	{"/sched/goroutines/running:goroutines", runtimeMetricsCPU},
+	{"/sched/goroutines/waiting:goroutines", runtimeMetricsCPU},
+	{"/sched/goroutines-created:goroutines", runtimeMetricsCPU},
+	{"/sched/gomaxprocs:threads", runtimeMetricsCPU},
+	{"/sched/threads/total:threads", runtimeMetricsCPU},
+	{"/sched/latencies:seconds", runtimeMetricsCPU},
+	{"/sched/pauses/total/other:seconds", runtimeMetricsCPU},
+	{"/sync/mutex/wait/total:seconds", runtimeMetricsCPU},
+	{"/cgo/go-to-c-calls:calls", runtimeMetricsCPU},
+
+	// memory
+	{"/memory/classes/total:bytes", runtimeMetricsMem},
+	{"/memory/classes/heap/objects:bytes", runtimeMetricsMem},
+	{"/memory/classes/heap/free:bytes", runtimeMetricsMem},
+	{"/memory/classes/heap/released:bytes", runtimeMetricsMem},
+	{"/memory/classes/heap/stacks:bytes", runtimeMetricsMem},
+	{"/memory/classes/heap/unused:bytes", runtimeMetricsMem},
+
+	// garbage collector
+	{"/gc/cycles/total:gc-cycles", runtimeMetricsGC},
+	{"/gc/heap/goal:bytes", runtimeMetricsGC},
+	{"/gc/heap/live:bytes", runtimeMetricsGC},
+	{"/gc/heap/objects:objects", runtimeMetricsGC},
+	{"/gc/heap/allocs:bytes", runtimeMetricsGC},
+	{"/gc/heap/frees:bytes", runtimeMetricsGC},
+	{"/gc/gogc:percent", runtimeMetricsGC},
+	{"/gc/gomemlimit:bytes", runtimeMetricsGC},
+	{"/sched/pauses/total/gc:seconds", runtimeMetricsGC},
+	{"/cpu/classes/gc/total:cpu-seconds", runtimeMetricsGC},
+}
+
+// RuntimeMetricsOption configures a RuntimeMetricsCollector
+type RuntimeMetricsOption func(*runtimeMetricsConfig)
+
+type runtimeMetricsConfig struct {
+	enableCPU bool
+	enableMem bool
+	enableGC  bool
+}
+
+// WithRuntimeMetricsCPU enables or disables the scheduler stats (goroutines, threads, latencies, etc.). Defaults to true.
+func WithRuntimeMetricsCPU(enabled bool) RuntimeMetricsOption {
+	return func(c *runtimeMetricsConfig) { c.enableCPU = enabled }
+}
+
+// WithRuntimeMetricsMem enables or disables the memory stats. Defaults to true.
+func WithRuntimeMetricsMem(enabled bool) RuntimeMetricsOption {
+	return func(c *runtimeMetricsConfig) { c.enableMem = enabled }
+}
+
+// WithRuntimeMetricsGC enables or disables the garbage collector stats. Defaults to true.
+func WithRuntimeMetricsGC(enabled bool) RuntimeMetricsOption {
+	return func(c *runtimeMetricsConfig) { c.enableGC = enabled }
+}
+
+// RuntimeMetricsCollector is a Collector reading Go runtime statistics through runtime/metrics.
+// Unlike the default runtime stats collector, which relies on runtime.ReadMemStats, reading runtime/metrics doesn't
+// stop the world, and distributions like scheduler latencies and GC pauses are exposed as histograms.
+//
+// Runtime histograms have too many buckets to be exported as they are, hence they are summarised as <name> gauges
+// tagged with the quantile (0.5, 0.9, 0.99 and 1, i.e. the maximum) of the values observed since the previous
+// collection, along with a <name>_count gauge with the total number of values observed. Quantiles are approximated
+// by the upper bounds of the runtime buckets they fall in, and left unchanged when no values were observed.
+//
+// Metric names are derived from the runtime/metrics ones, e.g. /sched/latencies:seconds becomes
+// runtime_sched_latencies_seconds.
+type RuntimeMetricsCollector struct {
+	stats Stats
+
+	mu       sync.Mutex // protects the following
+	samples  []metrics.Sample
+	names    []string   // stat names, indexed like samples
+	previous [][]uint64 // histogram bucket counts at the previous collection, indexed like samples
+	gauges   map[runtimeMetricsGaugeKey]Measurement
+}
+
+// runtimeMetricsGaugeKey identifies a float gauge of the collector
+type runtimeMetricsGaugeKey struct {
+	name, quantile string
+}
+
+// runtimeHistogramQuantiles are the quantiles reported for runtime histograms, 1 being the maximum
+var runtimeHistogramQuantiles = []float64{0.5, 0.9, 0.99, 1}
+
+// NewRuntimeMetricsCollector creates a new RuntimeMetricsCollector recording float values through the provided stats.
+//
+// The collector can be enabled for the default runtime stats by setting RuntimeStats.collector to "runtime_metrics",
+// in which case RuntimeStats.enableCPUStats, RuntimeStats.enabledMemStats and RuntimeStats.enableGCStats apply,
+// otherwise it can be registered through Stats.RegisterCollector.
+func NewRuntimeMetricsCollector(s Stats, opts ...RuntimeMetricsOption) *RuntimeMetricsCollector {
+	conf := runtimeMetricsConfig{enableCPU: true, enableMem: true, enableGC: true}
+	for _, opt := range opts {
+		opt(&conf)
+	}
+	enabled := map[runtimeMetricsCategory]bool{
+		runtimeMetricsCPU: conf.enableCPU,
+		runtimeMetricsMem: conf.enableMem,
+		runtimeMetricsGC:  conf.enableGC,
+	}
+	supported := make(map[string]struct{})
+	for _, d := range metrics.All() {
+		supported[d.Name] = struct{}{}
+	}
+	c := &RuntimeMetricsCollector{stats: s, gauges: make(map[runtimeMetricsGaugeKey]Measurement)}
+	for _, sample := range runtimeMetricsSamples {
+		if _, ok := supported[sample.name]; !ok || !enabled[sample.category] {
+			continue
+		}
+		c.samples = append(c.samples, metrics.Sample{Name: sample.name})
+		c.names = append(c.names, runtimeMetricStatName(sample.name))
+	}
+	c.previous = make([][]uint64, len(c.samples))
+	return c
+}
+
+// newRuntimeMetricsCollector creates a RuntimeMetricsCollector honoring the RuntimeStats.enable* settings
+func (c periodicStatsConfig) newRuntimeMetricsCollector(s Stats) *RuntimeMetricsCollector {
+	return NewRuntimeMetricsCollector(s,
+		WithRuntimeMetricsCPU(c.enableCPUStats),
+		WithRuntimeMetricsMem(c.enableMemStats),
+		WithRuntimeMetricsGC(c.enableGCStats),
+	)
+}
+
+// runtimeMetricStatName converts a runtime/metrics name into a stat name,
+// e.g. /sched/latencies:seconds becomes runtime_sched_latencies_seconds
+func runtimeMetricStatName(name string) string {
+	name = strings.TrimPrefix(name, "/")
+	return "runtime_" + strings.Map(func(r rune) rune {
+		if r == '/' || r == ':' || r == '-' {
+			return '_'
+		}
+		return r
+	}, name)
+}
+
+func (c *RuntimeMetricsCollector) Collect(gaugeFunc func(key string, tags Tags, val uint64)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	metrics.Read(c.samples)
+	for i, sample := range c.samples {
+		name := c.names[i]
+		switch sample.Value.Kind() {
+		case metrics.KindUint64:
+			gaugeFunc(name, nil, sample.Value.Uint64())
+		case metrics.KindFloat64:
+			c.gauge(name, "", sample.Value.Float64())
+		case metrics.KindFloat64Histogram:
+			h := sample.Value.Float64Histogram()
+			// the counts are cumulative since the start of the program, the quantiles are the ones of their increase
+			counts := make([]uint64, len(h.Counts))
+			var count, observed uint64
+			for j, n := range h.Counts {
+				count += n
+				if j < len(c.previous[i]) && c.previous[i][j] <= n {
+					n -= c.previous[i][j]
+				}
+				counts[j] = n
+				observed += n
+			}
+			c.previous[i] = slices.Clone(h.Counts) // the histogram is reused by the next read
+			gaugeFunc(name+"_count", nil, count)
+			if observed == 0 {
+				continue
+			}
+			for _, q := range runtimeHistogramQuantiles {
+				c.gauge(name, strconv.FormatFloat(q, 'g', -1, 64), runtimeHistogramQuantile(h.Buckets, counts, observed, q))
+			}
+		}
+	}
+}
+
+// gauge records a float gauge, creating its measurement only once since Collect is called periodically
+func (c *RuntimeMetricsCollector) gauge(name, quantile string, val float64) {
+	key := runtimeMetricsGaugeKey{name: name, quantile: quantile}
+	m, ok := c.gauges[key]
+	if !ok {
+		var tags Tags
+		if quantile != "" {
+			tags = Tags{"quantile": quantile}
+		}
+		m = c.stats.NewTaggedStat(name, GaugeType, tags)
+		c.gauges[key] = m
+	}
+	m.Gauge(val)
+}
+
+// runtimeHistogramQuantile returns the upper bound of the bucket the q quantile of the observed values falls in,
+// or its lower bound if unbounded
+func runtimeHistogramQuantile(buckets []float64, counts []uint64, observed uint64, q float64) float64 {
+	rank := max(uint64(math.Ceil(q*float64(observed))), 1)
+	var cumulative uint64
+	for j, n := range counts {
+		cumulative += n
+		if cumulative >= rank {
+			if math.IsInf(buckets[j+1], 1) {
+				return buckets[j]
+			}
+			return buckets[j+1]
+		}
+	}
+	return buckets[len(buckets)-1]
+}
+
+func (c *RuntimeMetricsCollector) Zero(gaugeFunc func(key string, tags Tags, val uint64)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for i, sample := range c.samples {
+		name := c.names[i]
+		switch sample.Value.Kind() {
+		case metrics.KindUint64:
+			gaugeFunc(name, nil, 0)
+		case metrics.KindFloat64Histogram:
+			gaugeFunc(name+"_count", nil, 0)
+		}
+	}
+	for _, m := range c.gauges {
+		m.Gauge(0.0)
+	}
+}
+
+func (*RuntimeMetricsCollector) ID() string {
+	return runtimeMetricsCollector
+}
@@ -0,0 +1,143 @@
+package stats_test
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"runtime/metrics"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rudderlabs/rudder-go-kit/config"
+	"github.com/rudderlabs/rudder-go-kit/logger"
+	"github.com/rudderlabs/rudder-go-kit/stats"
+	"github.com/rudderlabs/rudder-go-kit/stats/memstats"
+	"github.com/rudderlabs/rudder-go-kit/stats/metric"
+	"github.com/rudderlabs/rudder-go-kit/testhelper"
+)
+
+func TestRuntimeMetricsCollector(t *testing.T) {
+	s, err := memstats.New()
+	require.NoError(t, err)
+
+	c := stats.NewRuntimeMetricsCollector(s)
+	require.Equal(t, "runtime_metrics", c.ID())
+
+	gauges := make(map[string]uint64)
+	gaugeFunc := func(key string, _ stats.Tags, val uint64) {
+		gauges[key] = val
+	}
+	quantiles := func() []float64 {
+		var values []float64
+		for _, q := range []string{"0.5", "0.9", "0.99", "1"} {
+			m := s.Get("runtime_sched_latencies_seconds", stats.Tags{"quantile": q})
+			require.NotNil(t, m, "missing quantile %s", q)
+			values = append(values, m.LastValue())
+		}
+		return values
+	}
+
+	// generate some scheduler and GC activity
+	done := make(chan struct{})
+	for range 100 {
+		go func() { <-done }()
+	}
+	runtime.GC()
+
+	c.Collect(gaugeFunc)
+	require.GreaterOrEqual(t, gauges["runtime_sched_goroutines_goroutines"], uint64(100))
+	require.Positive(t, gauges["runtime_memory_classes_total_bytes"])
+	require.Positive(t, gauges["runtime_gc_cycles_total_gc_cycles"])
+	require.NotNil(t, s.Get("runtime_sync_mutex_wait_total_seconds", nil))
+
+	// histograms are summarised by their count and a few quantiles, approximated by the runtime bucket bounds
+	h := []metrics.Sample{{Name: "/sched/latencies:seconds"}}
+	metrics.Read(h)
+	buckets := h[0].Value.Float64Histogram().Buckets
+	count := gauges["runtime_sched_latencies_seconds_count"]
+	require.Positive(t, count)
+	values := quantiles()
+	require.True(t, slices.IsSorted(values), "quantiles should be increasing: %v", values)
+	for _, v := range values {
+		require.Contains(t, buckets, v)
+	}
+	require.Len(t, s.GetByName("runtime_sched_latencies_seconds"), 4, "only a few series should be reported")
+
+	close(done)
+	runtime.GC()
+	c.Collect(gaugeFunc)
+	require.Greater(t, gauges["runtime_sched_latencies_seconds_count"], count, "new scheduling events should be counted")
+	require.Positive(t, gauges["runtime_sched_pauses_total_gc_seconds_count"])
+
+	c.Zero(gaugeFunc)
+	require.Zero(t, gauges["runtime_sched_goroutines_goroutines"])
+	require.Zero(t, gauges["runtime_memory_classes_total_bytes"])
+	require.Zero(t, gauges["runtime_sched_latencies_seconds_count"])
+	require.Equal(t, []float64{0, 0, 0, 0}, quantiles())
+
+	t.Run("disabled categories", func(t *testing.T) {
+		c := stats.NewRuntimeMetricsCollector(s, stats.WithRuntimeMetricsCPU(false), stats.WithRuntimeMetricsGC(false))
+		gauges = make(map[string]uint64)
+		c.Collect(gaugeFunc)
+		require.Contains(t, gauges, "runtime_memory_classes_total_bytes")
+		require.NotContains(t, gauges, "runtime_sched_goroutines_goroutines")
+		require.NotContains(t, gauges, "runtime_sched_latencies_seconds_count")
+		require.NotContains(t, gauges, "runtime_gc_cycles_total_gc_cycles")
+	})
+}
+
+func TestRuntimeMetricsCollectorConfig(t *testing.T) {
+	c := config.New()
+	c.Set("OpenTelemetry.enabled", true)
+	c.Set("OpenTelemetry.metrics.prometheus.enabled", true)
+	c.Set("RuntimeStats.collector", "runtime_metrics")
+	r := prometheus.NewRegistry()
+	s := stats.NewStats(c, logger.NewFactory(c), metric.NewManager(), stats.WithPrometheusRegistry(r, r))
+	require.NoError(t, s.Start(context.Background(), stats.DefaultGoRoutineFactory))
+	t.Cleanup(s.Stop)
+
+	require.Eventually(t, func() bool {
+		mfs, err := r.Gather()
+		if err != nil {
+			t.Logf("gathering metrics: %v", err)
+			return false
+		}
+		var goroutines, latencies, memStats bool
+		for _, mf := range mfs {
+			switch mf.GetName() {
+			case "runtime_sched_goroutines_goroutines":
+				goroutines = true
+			case "runtime_sched_latencies_seconds":
+				latencies = true
+			case "runtime_mem_alloc":
+				memStats = true
+			}
+		}
+		return goroutines && latencies && !memStats
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestRuntimeMetricsCollectorRegistrationError(t *testing.T) {
+	for _, otel := range []bool{true, false} {
+		t.Run(fmt.Sprintf("otel=%t", otel), func(t *testing.T) {
+			freePort, err := testhelper.GetFreePort()
+			require.NoError(t, err)
+			c := config.New()
+			c.Set("OpenTelemetry.enabled", otel)
+			c.Set("OpenTelemetry.metrics.prometheus.enabled", true)
+			c.Set("OpenTelemetry.metrics.prometheus.port", freePort)
+			c.Set("RuntimeStats.collector", "runtime_metrics")
+			r := prometheus.NewRegistry()
+			s := stats.NewStats(c, logger.NewFactory(c), metric.NewManager(), stats.WithPrometheusRegistry(r, r))
+			require.NoError(t, s.RegisterCollector(stats.NewRuntimeMetricsCollector(s)))
+
+			err = s.Start(context.Background(), stats.DefaultGoRoutineFactory)
+			require.ErrorContains(t, err, "failed to register runtime metrics collector")
+			s.Stop()
+		})
+	}
+}
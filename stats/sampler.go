@@ -0,0 +1,232 @@
+package stats
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/rudderlabs/rudder-go-kit/config"
+)
+
+const (
+	// samplingStrategyRatio samples a given ratio of the traces (see OpenTelemetry.traces.samplingRate)
+	samplingStrategyRatio = "ratio"
+	// samplingStrategyRateLimited samples up to a given number of traces per second
+	// (see OpenTelemetry.traces.sampling.tracesPerSecond)
+	samplingStrategyRateLimited = "rate_limited"
+	// samplingStrategyAlwaysOn samples all traces
+	samplingStrategyAlwaysOn = "always_on"
+	// samplingStrategyAlwaysOff samples no traces, unless a rule says otherwise
+	samplingStrategyAlwaysOff = "always_off"
+)
+
+// tracingSamplingConfig holds the hot-reloadable trace sampling configuration, so that sampling can be tuned (e.g.
+// bumped during an incident) without restarting the service.
+type tracingSamplingConfig struct {
+	// strategy is one of ratio (default), rate_limited, always_on or always_off
+	strategy *config.Reloadable[string]
+	// ratio is the ratio of traces sampled by the ratio strategy
+	ratio *config.Reloadable[float64]
+	// tracesPerSecond is the maximum number of traces per second sampled by the rate_limited strategy
+	tracesPerSecond *config.Reloadable[float64]
+	// parentBased makes spans honour the sampling decision of their parent, applying the strategy to root spans only
+	parentBased *config.Reloadable[bool]
+	// sampleErrors exports spans ending with an error status even if they were not sampled.
+	// Since the status of a span is known only once it ends, not sampled spans have to be recorded anyway, which
+	// makes them more expensive.
+	sampleErrors *config.Reloadable[bool]
+	// attributes is a list of key=value pairs: spans started with any of these attributes are always sampled
+	attributes *config.Reloadable[[]string]
+}
+
+func newTracingSamplingConfig(c *config.Config) tracingSamplingConfig {
+	return tracingSamplingConfig{
+		strategy:        c.GetReloadableStringVar(samplingStrategyRatio, "OpenTelemetry.traces.sampling.strategy"),
+		ratio:           c.GetReloadableFloat64Var(0.1, "OpenTelemetry.traces.samplingRate"),
+		tracesPerSecond: c.GetReloadableFloat64Var(10, "OpenTelemetry.traces.sampling.tracesPerSecond"),
+		parentBased:     c.GetReloadableBoolVar(false, "OpenTelemetry.traces.sampling.parentBased"),
+		sampleErrors:    c.GetReloadableBoolVar(false, "OpenTelemetry.traces.sampling.sampleErrors"),
+		attributes:      c.GetReloadableStringSliceVar(nil, "OpenTelemetry.traces.sampling.attributes"),
+	}
+}
+
+// samplerSettings is a snapshot of the tracingSamplingConfig
+type samplerSettings struct {
+	strategy        string
+	ratio           float64
+	tracesPerSecond float64
+	parentBased     bool
+	sampleErrors    bool
+	attributes      []string
+}
+
+func (s *samplerSettings) equal(o *samplerSettings) bool {
+	return s.strategy == o.strategy &&
+		s.ratio == o.ratio &&
+		s.tracesPerSecond == o.tracesPerSecond &&
+		s.parentBased == o.parentBased &&
+		s.sampleErrors == o.sampleErrors &&
+		slices.Equal(s.attributes, o.attributes)
+}
+
+// traceSampler is a sdktrace.Sampler rebuilding itself whenever the tracingSamplingConfig changes
+type traceSampler struct {
+	config tracingSamplingConfig
+
+	mu      sync.Mutex // serialises rebuilds
+	current atomic.Pointer[builtSampler]
+}
+
+type builtSampler struct {
+	settings   samplerSettings
+	sampler    sdktrace.Sampler
+	attributes []attribute.KeyValue
+}
+
+func newTraceSampler(c tracingSamplingConfig) *traceSampler {
+	return &traceSampler{config: c}
+}
+
+// exportErrorSpans returns whether not sampled spans ending with an error status should be exported
+func (s *traceSampler) exportErrorSpans() bool {
+	return s.config.sampleErrors.Load()
+}
+
+func (s *traceSampler) ShouldSample(p sdktrace.SamplingParameters) sdktrace.SamplingResult {
+	b := s.load()
+	for _, rule := range b.attributes {
+		for _, attr := range p.Attributes {
+			if attr.Key == rule.Key && attr.Value.Emit() == rule.Value.Emit() {
+				return sdktrace.SamplingResult{
+					Decision:   sdktrace.RecordAndSample,
+					Tracestate: trace.SpanContextFromContext(p.ParentContext).TraceState(),
+				}
+			}
+		}
+	}
+	res := b.sampler.ShouldSample(p)
+	if res.Decision == sdktrace.Drop && b.settings.sampleErrors {
+		res.Decision = sdktrace.RecordOnly
+	}
+	return res
+}
+
+func (s *traceSampler) Description() string {
+	b := s.load()
+	desc := b.sampler.Description()
+	if len(b.attributes) > 0 || b.settings.sampleErrors {
+		desc = fmt.Sprintf("RuleBased{sampleErrors:%t,attributes:%v,fallback:%s}",
+			b.settings.sampleErrors, b.settings.attributes, desc,
+		)
+	}
+	return desc
+}
+
+func (s *traceSampler) load() *builtSampler {
+	settings := samplerSettings{
+		strategy:        s.config.strategy.Load(),
+		ratio:           s.config.ratio.Load(),
+		tracesPerSecond: s.config.tracesPerSecond.Load(),
+		parentBased:     s.config.parentBased.Load(),
+		sampleErrors:    s.config.sampleErrors.Load(),
+		attributes:      s.config.attributes.Load(),
+	}
+	if b := s.current.Load(); b != nil && b.settings.equal(&settings) {
+		return b
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if b := s.current.Load(); b != nil && b.settings.equal(&settings) { // double check for race
+		return b
+	}
+	b := buildSampler(settings)
+	s.current.Store(b)
+	return b
+}
+
+func buildSampler(settings samplerSettings) *builtSampler {
+	b := &builtSampler{settings: settings}
+	switch strings.ToLower(settings.strategy) {
+	case samplingStrategyAlwaysOn:
+		b.sampler = sdktrace.AlwaysSample()
+	case samplingStrategyAlwaysOff:
+		b.sampler = sdktrace.NeverSample()
+	case samplingStrategyRateLimited:
+		b.sampler = newRateLimitingSampler(settings.tracesPerSecond, time.Now)
+	default:
+		b.sampler = sdktrace.TraceIDRatioBased(settings.ratio)
+	}
+	if settings.parentBased {
+		b.sampler = sdktrace.ParentBased(b.sampler)
+	}
+	for _, kv := range settings.attributes {
+		key, value, ok := strings.Cut(kv, "=")
+		if !ok || strings.TrimSpace(key) == "" {
+			continue // invalid rules are ignored
+		}
+		b.attributes = append(b.attributes, attribute.String(strings.TrimSpace(key), strings.TrimSpace(value)))
+	}
+	return b
+}
+
+// rateLimitingSampler samples up to tracesPerSecond traces per second using a token bucket holding up to one second
+// worth of tokens.
+// Tokens are consumed by root spans only, the other spans inheriting the decision taken for their parent so that
+// traces are sampled as a whole.
+type rateLimitingSampler struct {
+	tracesPerSecond float64
+	now             func() time.Time
+
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+}
+
+func newRateLimitingSampler(tracesPerSecond float64, now func() time.Time) *rateLimitingSampler {
+	return &rateLimitingSampler{
+		tracesPerSecond: tracesPerSecond,
+		now:             now,
+		tokens:          max(tracesPerSecond, 1),
+		last:            now(),
+	}
+}
+
+func (s *rateLimitingSampler) ShouldSample(p sdktrace.SamplingParameters) sdktrace.SamplingResult {
+	parent := trace.SpanContextFromContext(p.ParentContext)
+	res := sdktrace.SamplingResult{
+		Decision:   sdktrace.Drop,
+		Tracestate: parent.TraceState(),
+	}
+	if parent.IsValid() {
+		if parent.IsSampled() {
+			res.Decision = sdktrace.RecordAndSample
+		}
+		return res
+	}
+	if s.tracesPerSecond <= 0 {
+		return res
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.now()
+	s.tokens = min(s.tokens+now.Sub(s.last).Seconds()*s.tracesPerSecond, max(s.tracesPerSecond, 1))
+	s.last = now
+	if s.tokens >= 1 {
+		s.tokens--
+		res.Decision = sdktrace.RecordAndSample
+	}
+	return res
+}
+
+func (s *rateLimitingSampler) Description() string {
+	return fmt.Sprintf("RateLimitingSampler{%g}", s.tracesPerSecond)
+}
@@ -0,0 +1,121 @@
+package stats
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/rudderlabs/rudder-go-kit/config"
+)
+
+func TestTraceSampler(t *testing.T) {
+	traceID := trace.TraceID{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}
+	params := func(attrs ...attribute.KeyValue) sdktrace.SamplingParameters {
+		return sdktrace.SamplingParameters{ParentContext: context.Background(), TraceID: traceID, Attributes: attrs}
+	}
+
+	t.Run("ratio is hot-reloadable", func(t *testing.T) {
+		c := config.New()
+		c.Set("OpenTelemetry.traces.samplingRate", 0.0)
+		s := newTraceSampler(newTracingSamplingConfig(c))
+		require.Equal(t, sdktrace.Drop, s.ShouldSample(params()).Decision)
+		require.Equal(t, "TraceIDRatioBased{0}", s.Description())
+
+		c.Set("OpenTelemetry.traces.samplingRate", 1.0)
+		require.Equal(t, sdktrace.RecordAndSample, s.ShouldSample(params()).Decision)
+	})
+
+	t.Run("always on and off", func(t *testing.T) {
+		c := config.New()
+		c.Set("OpenTelemetry.traces.sampling.strategy", "always_on")
+		s := newTraceSampler(newTracingSamplingConfig(c))
+		require.Equal(t, sdktrace.RecordAndSample, s.ShouldSample(params()).Decision)
+
+		c.Set("OpenTelemetry.traces.sampling.strategy", "always_off")
+		require.Equal(t, sdktrace.Drop, s.ShouldSample(params()).Decision)
+	})
+
+	t.Run("attribute rules", func(t *testing.T) {
+		c := config.New()
+		c.Set("OpenTelemetry.traces.sampling.strategy", "always_off")
+		c.Set("OpenTelemetry.traces.sampling.attributes", []string{"workspaceId=ws-1", "invalid"})
+		s := newTraceSampler(newTracingSamplingConfig(c))
+		require.Equal(t, sdktrace.RecordAndSample, s.ShouldSample(params(attribute.String("workspaceId", "ws-1"))).Decision)
+		require.Equal(t, sdktrace.Drop, s.ShouldSample(params(attribute.String("workspaceId", "ws-2"))).Decision)
+		require.Equal(t, sdktrace.Drop, s.ShouldSample(params()).Decision)
+	})
+
+	t.Run("sample errors", func(t *testing.T) {
+		c := config.New()
+		c.Set("OpenTelemetry.traces.sampling.strategy", "always_off")
+		c.Set("OpenTelemetry.traces.sampling.sampleErrors", true)
+		s := newTraceSampler(newTracingSamplingConfig(c))
+		require.True(t, s.exportErrorSpans())
+		require.Equal(t, sdktrace.RecordOnly, s.ShouldSample(params()).Decision)
+
+		c.Set("OpenTelemetry.traces.sampling.sampleErrors", false)
+		require.False(t, s.exportErrorSpans())
+		require.Equal(t, sdktrace.Drop, s.ShouldSample(params()).Decision)
+	})
+
+	t.Run("parent based", func(t *testing.T) {
+		c := config.New()
+		c.Set("OpenTelemetry.traces.sampling.strategy", "always_off")
+		c.Set("OpenTelemetry.traces.sampling.parentBased", true)
+		s := newTraceSampler(newTracingSamplingConfig(c))
+
+		parent := trace.NewSpanContext(trace.SpanContextConfig{
+			TraceID:    traceID,
+			SpanID:     trace.SpanID{1},
+			TraceFlags: trace.FlagsSampled,
+			Remote:     true,
+		})
+		p := params()
+		p.ParentContext = trace.ContextWithRemoteSpanContext(context.Background(), parent)
+		require.Equal(t, sdktrace.RecordAndSample, s.ShouldSample(p).Decision)
+		require.Equal(t, sdktrace.Drop, s.ShouldSample(params()).Decision)
+	})
+
+	t.Run("rate limited", func(t *testing.T) {
+		now := time.Now()
+		s := newRateLimitingSampler(2, func() time.Time { return now })
+		require.Equal(t, sdktrace.RecordAndSample, s.ShouldSample(params()).Decision)
+		require.Equal(t, sdktrace.RecordAndSample, s.ShouldSample(params()).Decision)
+		require.Equal(t, sdktrace.Drop, s.ShouldSample(params()).Decision)
+
+		now = now.Add(500 * time.Millisecond)
+		require.Equal(t, sdktrace.RecordAndSample, s.ShouldSample(params()).Decision)
+		require.Equal(t, sdktrace.Drop, s.ShouldSample(params()).Decision)
+
+		now = now.Add(time.Hour) // the bucket holds at most one second worth of traces
+		for range 2 {
+			require.Equal(t, sdktrace.RecordAndSample, s.ShouldSample(params()).Decision)
+		}
+		require.Equal(t, sdktrace.Drop, s.ShouldSample(params()).Decision)
+		require.Equal(t, "RateLimitingSampler{2}", s.Description())
+
+		require.Equal(t, sdktrace.Drop, newRateLimitingSampler(0, time.Now).ShouldSample(params()).Decision)
+
+		// child spans inherit the decision of their parent, without consuming tokens
+		child := func(flags trace.TraceFlags) sdktrace.SamplingParameters {
+			p := params()
+			p.ParentContext = trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(
+				trace.SpanContextConfig{TraceID: traceID, SpanID: trace.SpanID{1}, TraceFlags: flags},
+			))
+			return p
+		}
+		now = now.Add(time.Hour)
+		for range 3 {
+			require.Equal(t, sdktrace.RecordAndSample, s.ShouldSample(child(trace.FlagsSampled)).Decision)
+			require.Equal(t, sdktrace.Drop, s.ShouldSample(child(0)).Decision)
+		}
+		require.Equal(t, sdktrace.RecordAndSample, s.ShouldSample(params()).Decision)
+		require.Equal(t, sdktrace.RecordAndSample, s.ShouldSample(params()).Decision)
+		require.Equal(t, sdktrace.Drop, s.ShouldSample(params()).Decision)
+	})
+}
@@ -64,6 +64,17 @@ type Stats interface {
 	RegisterCollector(c Collector) error
 }
 
+// CollectorUnregisterer is implemented by the Stats which support unregistering collectors, zeroing their stats.
+// Collectors of resources that are closed should be unregistered, otherwise they keep being collected.
+type CollectorUnregisterer interface {
+	UnregisterCollector(c Collector)
+}
+
+var (
+	_ CollectorUnregisterer = (*otelStats)(nil)
+	_ CollectorUnregisterer = (*statsdStats)(nil)
+)
+
 type loggerFactory interface {
 	NewLogger() logger.Logger
 }
@@ -91,10 +102,12 @@ func NewStats(
 		excludedTags[tag] = struct{}{}
 	}
 
+	log := loggerFactory.NewLogger().Child("stats")
 	enabled := atomic.Bool{}
 	enabled.Store(config.GetBoolVar(true, "enableStats"))
 	statsConfig := statsConfig{
 		excludedTags:        excludedTags,
+		views:               newMetricViews(config, log),
 		enabled:             &enabled,
 		instanceName:        config.GetStringVar("", "INSTANCE_ID"),
 		namespaceIdentifier: os.Getenv("KUBE_NAMESPACE"),
@@ -104,6 +117,7 @@ func NewStats(
 			enableCPUStats:          config.GetBoolVar(true, "RuntimeStats.enableCPUStats"),
 			enableMemStats:          config.GetBoolVar(true, "RuntimeStats.enabledMemStats"),
 			enableGCStats:           config.GetBoolVar(true, "RuntimeStats.enableGCStats"),
+			collector:               config.GetStringVar(memStatsCollector, "RuntimeStats.collector"),
 			metricManager:           metricManager,
 		},
 	}
@@ -124,13 +138,13 @@ func NewStats(
 			config:                   statsConfig,
 			stopBackgroundCollection: func() {},
 			meter:                    otel.GetMeterProvider().Meter(defaultMeterName),
-			logger:                   loggerFactory.NewLogger().Child("stats"),
+			logger:                   log,
 			prometheusRegisterer:     registerer,
 			prometheusGatherer:       gatherer,
 			tracerProvider:           noop.NewTracerProvider(),
 			otelConfig: otelStatsConfig{
 				tracesEndpoint:           config.GetStringVar("", "OpenTelemetry.traces.endpoint"),
-				tracingSamplingRate:      config.GetFloat64Var(0.1, "OpenTelemetry.traces.samplingRate"),
+				tracingSampling:          newTracingSamplingConfig(config),
 				withTracingSyncer:        config.GetBoolVar(false, "OpenTelemetry.traces.withSyncer"),
 				withOTLPHTTP:             config.GetBoolVar(false, "OpenTelemetry.traces.withOTLPHTTP"),
 				metricsEndpoint:          config.GetStringVar("", "OpenTelemetry.metrics.endpoint"),
@@ -146,7 +160,7 @@ func NewStats(
 
 	return &statsdStats{
 		config:                     statsConfig,
-		logger:                     loggerFactory.NewLogger().Child("stats"),
+		logger:                     log,
 		backgroundCollectionCtx:    backgroundCollectionCtx,
 		backgroundCollectionCancel: backgroundCollectionCancel,
 		tracer:                     noop.NewTracerProvider().Tracer(""),
@@ -35,6 +35,13 @@ func (s *statsdStats) Start(ctx context.Context, goFactory GoRoutineFactory) err
 		return nil
 	}
 
+	// registering the runtime metrics collector upfront, so that a failure is returned like in OpenTelemetry mode
+	if s.config.periodicStatsConfig.enabled && s.config.periodicStatsConfig.collector == runtimeMetricsCollector {
+		if err := s.state.ac.Add(s.config.periodicStatsConfig.newRuntimeMetricsCollector(s)); err != nil {
+			return fmt.Errorf("failed to register runtime metrics collector: %w", err)
+		}
+	}
+
 	s.state.conn = statsd.Address(s.statsdConfig.statsdServerURL)
 	// since, we don't want setup to be a blocking call, creating a separate `go routine` for retry to get statsd client.
 
@@ -115,11 +122,14 @@ func (s *statsdStats) collectPeriodicStats(goFactory GoRoutineFactory) {
 	gaugeFunc := func(key string, val uint64) {
 		s.NewStat("runtime_"+key, GaugeType).Gauge(val)
 	}
-	s.state.rc = newRuntimeStatsCollector(gaugeFunc)
-	s.state.rc.PauseDur = time.Duration(s.config.periodicStatsConfig.statsCollectionInterval) * time.Second
-	s.state.rc.EnableCPU = s.config.periodicStatsConfig.enableCPUStats
-	s.state.rc.EnableMem = s.config.periodicStatsConfig.enableMemStats
-	s.state.rc.EnableGC = s.config.periodicStatsConfig.enableGCStats
+	useRuntimeMetrics := s.config.periodicStatsConfig.collector == runtimeMetricsCollector
+	if !useRuntimeMetrics {
+		s.state.rc = newRuntimeStatsCollector(gaugeFunc)
+		s.state.rc.PauseDur = time.Duration(s.config.periodicStatsConfig.statsCollectionInterval) * time.Second
+		s.state.rc.EnableCPU = s.config.periodicStatsConfig.enableCPUStats
+		s.state.rc.EnableMem = s.config.periodicStatsConfig.enableMemStats
+		s.state.rc.EnableGC = s.config.periodicStatsConfig.enableGCStats
+	}
 	s.state.mc = newMetricStatsCollector(s, s.config.periodicStatsConfig.metricManager)
 
 	gaugeTagsFunc := func(key string, tags Tags, val uint64) {
@@ -130,11 +140,15 @@ func (s *statsdStats) collectPeriodicStats(goFactory GoRoutineFactory) {
 
 	if s.config.periodicStatsConfig.enabled {
 		var wg sync.WaitGroup
-		wg.Add(3)
-		goFactory.Go(func() {
-			defer wg.Done()
-			s.state.rc.run(s.backgroundCollectionCtx)
-		})
+		// the runtime metrics collector, if used, is registered by Start and run by the aggregated collector
+		if !useRuntimeMetrics {
+			wg.Add(1)
+			goFactory.Go(func() {
+				defer wg.Done()
+				s.state.rc.run(s.backgroundCollectionCtx)
+			})
+		}
+		wg.Add(2)
 		goFactory.Go(func() {
 			defer wg.Done()
 			s.state.mc.run(s.backgroundCollectionCtx)
@@ -151,6 +165,10 @@ func (s *statsdStats) RegisterCollector(c Collector) error {
 	return s.state.ac.Add(c)
 }
 
+func (s *statsdStats) UnregisterCollector(c Collector) {
+	s.state.ac.Remove(c)
+}
+
 // Stop stops periodic collection of stats.
 func (s *statsdStats) Stop() {
 	if !s.config.enabled.Load() || !s.state.connEstablished {
@@ -189,6 +207,12 @@ func (s *statsdStats) internalNewTaggedStat(name, statType string, tags Tags, sa
 		return s.newStatsdMeasurement(name, statType, &statsdClient{})
 	}
 
+	view := s.config.views.get(name)
+	if view != nil && view.disabled {
+		return s.newStatsdMeasurement(name, statType, &statsdClient{})
+	}
+	name = view.name(name)
+
 	// Clean up tags based on deployment type. No need to send workspace id tag for free tier customers.
 	newTags := make(Tags)
 	for k, v := range tags {
@@ -207,6 +231,9 @@ func (s *statsdStats) internalNewTaggedStat(name, statType string, tags Tags, sa
 		if _, ok := s.config.excludedTags[sanitizedKey]; ok {
 			continue
 		}
+		if !view.keepTag(k, sanitizedKey) {
+			continue
+		}
 		newTags[sanitizedKey] = v
 	}
 
@@ -529,6 +529,56 @@ func TestStatsdExcludedTags(t *testing.T) {
 	}, 2*time.Second, time.Millisecond)
 }
 
+func TestStatsdViews(t *testing.T) {
+	received := make(chan string, 10)
+	server := newStatsdServer(t, func(s string) {
+		if s != "" {
+			received <- s
+		}
+	})
+	defer server.Close()
+
+	c := config.New()
+	c.Set("STATSD_SERVER_URL", server.addr)
+	c.Set("INSTANCE_ID", "test")
+	c.Set("enableStats", true)
+	c.Set("RuntimeStats.enabled", false)
+	c.Set("Stats.views", map[string]any{
+		"test-renamed":  map[string]any{"rename": "test-new-name", "dropTags": []string{"workspaceId"}},
+		"test-allowed":  map[string]any{"allowTags": []string{"destType"}},
+		"test-disabled": map[string]any{"disabled": true},
+	})
+
+	s := stats.NewStats(c, logger.NewFactory(c), metric.NewManager())
+	require.NoError(t, s.Start(t.Context(), stats.DefaultGoRoutineFactory))
+	defer s.Stop()
+
+	requireReceived := func(t *testing.T, expected string) {
+		t.Helper()
+		select {
+		case got := <-received:
+			require.Equal(t, expected, got)
+		case <-time.After(2 * time.Second):
+			require.Fail(t, "timeout waiting for metric", expected)
+		}
+	}
+
+	s.NewTaggedStat("test-disabled", stats.CountType, stats.Tags{"destType": "dt"}).Increment()
+	s.NewTaggedStat("test-renamed", stats.CountType, stats.Tags{"workspaceId": "ws", "destType": "dt"}).Increment()
+	requireReceived(t, "test-new-name,instanceName=test,destType=dt:1|c")
+
+	s.NewTaggedStat("test-allowed", stats.CountType, stats.Tags{"workspaceId": "ws", "destType": "dt"}).Increment()
+	requireReceived(t, "test-allowed,instanceName=test,destType=dt:1|c")
+
+	t.Run("hot reload", func(t *testing.T) {
+		c.Set("Stats.views", map[string]any{
+			"test-disabled": map[string]any{"disabled": false},
+		})
+		s.NewTaggedStat("test-disabled", stats.CountType, stats.Tags{"destType": "dt"}).Increment()
+		requireReceived(t, "test-disabled,instanceName=test,destType=dt:1|c")
+	})
+}
+
 type statsdServer struct {
 	t      *testing.T
 	addr   string
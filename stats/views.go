@@ -0,0 +1,220 @@
+package stats
+
+import (
+	"reflect"
+	"strings"
+	"sync/atomic"
+
+	"github.com/spf13/cast"
+
+	obskit "github.com/rudderlabs/rudder-observability-kit/go/labels"
+
+	"github.com/rudderlabs/rudder-go-kit/config"
+	"github.com/rudderlabs/rudder-go-kit/logger"
+)
+
+// viewsConfigKey is the configuration key holding the per-metric views, e.g.
+//
+//	Stats:
+//	  views:
+//	    router_delivery_time:
+//	      rename: router_delivery_latency
+//	      dropTags: [workspaceId]
+//	      buckets: [0.01, 0.1, 1, 10]
+//	    noisy_gauge:
+//	      disabled: true
+const viewsConfigKey = "Stats.views"
+
+// metricView describes how a metric is altered before being recorded. Views are looked up by the metric name the
+// caller passes to NewStat/NewTaggedStat, case-insensitively (the underlying configuration is case-insensitive).
+type metricView struct {
+	// disabled drops the metric altogether.
+	disabled bool
+	// rename replaces the metric name, if not empty.
+	rename string
+	// dropTags lists the tags to be removed from the metric.
+	dropTags map[string]struct{}
+	// allowTags, if not nil, lists the only tags that are kept (dropTags still apply on top of it).
+	allowTags map[string]struct{}
+	// buckets overrides the explicit histogram bucket boundaries (OpenTelemetry only).
+	buckets []float64
+}
+
+// name returns the name the metric should be recorded with
+func (v *metricView) name(name string) string {
+	if v == nil || v.rename == "" {
+		return name
+	}
+	return v.rename
+}
+
+// keepTag returns true if a tag should be kept, checking both its original and sanitized key
+func (v *metricView) keepTag(key, sanitizedKey string) bool {
+	if v == nil {
+		return true
+	}
+	if _, ok := v.dropTags[key]; ok {
+		return false
+	}
+	if _, ok := v.dropTags[sanitizedKey]; ok {
+		return false
+	}
+	if v.allowTags == nil {
+		return true
+	}
+	if _, ok := v.allowTags[key]; ok {
+		return true
+	}
+	_, ok := v.allowTags[sanitizedKey]
+	return ok
+}
+
+// metricViews holds the metric views configured under Stats.views.
+// The configuration is hot-reloadable: views are re-parsed lazily whenever the underlying configuration changes and
+// are applied to every measurement resolved after the change. Measurements already resolved by callers keep the
+// view they were created with.
+type metricViews struct {
+	conf   *config.Reloadable[map[string]any]
+	parsed atomic.Pointer[parsedMetricViews]
+	logger logger.Logger
+}
+
+type parsedMetricViews struct {
+	raw   map[string]any
+	views map[string]*metricView
+}
+
+func newMetricViews(c *config.Config, log logger.Logger) *metricViews {
+	return &metricViews{
+		conf:   c.GetReloadableStringMapVar(nil, viewsConfigKey),
+		logger: log,
+	}
+}
+
+// get returns the view configured for the given metric name, or nil if there is none
+func (mv *metricViews) get(name string) *metricView {
+	if mv == nil {
+		return nil
+	}
+	views := mv.load().views
+	if len(views) == 0 {
+		return nil
+	}
+	return views[strings.ToLower(name)]
+}
+
+// histogramBuckets returns the explicit histogram bucket overrides, keyed by the (possibly renamed) metric name
+func (mv *metricViews) histogramBuckets() map[string][]float64 {
+	if mv == nil {
+		return nil
+	}
+	var buckets map[string][]float64
+	for name, v := range mv.load().views {
+		if len(v.buckets) == 0 {
+			continue
+		}
+		if buckets == nil {
+			buckets = make(map[string][]float64)
+		}
+		buckets[v.name(name)] = v.buckets
+	}
+	return buckets
+}
+
+func (mv *metricViews) load() *parsedMetricViews {
+	raw := mv.conf.Load()
+	p := mv.parsed.Load()
+	// a reload always swaps in a new map, thus comparing the map identity is enough to detect changes
+	if p != nil && reflect.ValueOf(p.raw).UnsafePointer() == reflect.ValueOf(raw).UnsafePointer() {
+		return p
+	}
+	p = &parsedMetricViews{raw: raw, views: mv.parse(raw)}
+	mv.parsed.Store(p)
+	return p
+}
+
+func (mv *metricViews) parse(raw map[string]any) map[string]*metricView {
+	views := make(map[string]*metricView, len(raw))
+	for name, value := range raw {
+		fields, err := cast.ToStringMapE(value)
+		if err != nil {
+			mv.logger.Warnn("ignoring invalid metric view",
+				logger.NewStringField("metric", name),
+				obskit.Error(err),
+			)
+			continue
+		}
+		v := &metricView{}
+		for field, fieldValue := range fields {
+			switch strings.ToLower(field) {
+			case "disabled":
+				v.disabled, err = cast.ToBoolE(fieldValue)
+			case "rename":
+				v.rename, err = cast.ToStringE(fieldValue)
+			case "droptags":
+				v.dropTags, err = toStringSet(fieldValue)
+			case "allowtags":
+				v.allowTags, err = toStringSet(fieldValue)
+				if v.allowTags == nil && err == nil {
+					v.allowTags = map[string]struct{}{}
+				}
+			case "buckets":
+				v.buckets, err = toFloat64Slice(fieldValue)
+			default:
+				mv.logger.Warnn("ignoring unknown metric view field",
+					logger.NewStringField("metric", name),
+					logger.NewStringField("field", field),
+				)
+				continue
+			}
+			if err != nil {
+				mv.logger.Warnn("ignoring invalid metric view field",
+					logger.NewStringField("metric", name),
+					logger.NewStringField("field", field),
+					obskit.Error(err),
+				)
+			}
+		}
+		views[strings.ToLower(name)] = v
+	}
+	return views
+}
+
+// toStringSet accepts either a list or a comma-separated string (e.g. coming from an environment variable)
+func toStringSet(value any) (map[string]struct{}, error) {
+	if s, ok := value.(string); ok {
+		value = strings.Split(s, ",")
+	}
+	values, err := cast.ToStringSliceE(value)
+	if err != nil {
+		return nil, err
+	}
+	var set map[string]struct{}
+	for _, v := range values {
+		if v = strings.TrimSpace(v); v == "" {
+			continue
+		}
+		if set == nil {
+			set = make(map[string]struct{}, len(values))
+		}
+		set[v] = struct{}{}
+	}
+	return set, nil
+}
+
+// toFloat64Slice accepts either a list or a comma-separated string (e.g. coming from an environment variable)
+func toFloat64Slice(value any) ([]float64, error) {
+	if s, ok := value.(string); ok {
+		parts := strings.Split(s, ",")
+		res := make([]float64, 0, len(parts))
+		for _, part := range parts {
+			f, err := cast.ToFloat64E(strings.TrimSpace(part))
+			if err != nil {
+				return nil, err
+			}
+			res = append(res, f)
+		}
+		return res, nil
+	}
+	return cast.ToFloat64SliceE(value)
+}
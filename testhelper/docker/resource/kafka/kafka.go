@@ -190,6 +190,9 @@ func Setup(pool *dockertest.Pool, cln resource.Cleaner, opts ...Option) (*Resour
 		"KAFKA_CFG_ZOOKEEPER_CONNECT=zookeeper:2181",
 		"KAFKA_CFG_INTER_BROKER_LISTENER_NAME=INTERNAL",
 		"ALLOW_PLAINTEXT_LISTENER=yes",
+		// allowing transactions on clusters with less than 3 brokers
+		"KAFKA_CFG_TRANSACTION_STATE_LOG_REPLICATION_FACTOR=" + strconv.Itoa(int(min(c.brokers, 3))),
+		"KAFKA_CFG_TRANSACTION_STATE_LOG_MIN_ISR=1",
 	}
 
 	var schemaRegistryURL string
@@ -33,9 +33,13 @@ func BenchmarkLimiters(b *testing.B) {
 		ctx            = context.Background()
 		rc             = bootstrapRedis(ctx, b, pool)
 		limiters       = map[string]*Limiter{
-			"gcra":              newLimiter(b, WithInMemoryGCRA(0)),
-			"gcra redis":        newLimiter(b, WithRedisGCRA(rc, 0)),
-			"sorted sets redis": newLimiter(b, WithRedisSortedSet(rc)),
+			"gcra":                 newLimiter(b, WithInMemoryGCRA(0)),
+			"gcra redis":           newLimiter(b, WithRedisGCRA(rc, 0)),
+			"sorted sets redis":    newLimiter(b, WithRedisSortedSet(rc)),
+			"token bucket":         newLimiter(b, WithInMemoryTokenBucket(0)),
+			"token bucket redis":   newLimiter(b, WithRedisTokenBucket(rc, 0)),
+			"sliding window":       newLimiter(b, WithInMemorySlidingWindow()),
+			"sliding window redis": newLimiter(b, WithRedisSlidingWindow(rc)),
 		}
 	)
 
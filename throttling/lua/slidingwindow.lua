@@ -0,0 +1,71 @@
+--[[
+To debug this script you can add these entries here in the script and then check the Redis server output:
+redis.log(redis.LOG_NOTICE, "some label", some_variable)
+
+For more information please refer to: https://redis.io/docs/manual/programmability/lua-debugging/
+--]]
+
+local key = KEYS[1]
+local cost = tonumber(ARGV[1])
+local rate = tonumber(ARGV[2])
+local period = tonumber(ARGV[3]) * 1000 * 1000 -- converting to microseconds
+
+-- see gcra.lua for why the time is relative to Jan 1, 2017 00:00:00 GMT
+local jan_1_2017 = 1483228800 * 1000 * 1000 -- in microseconds precision
+local current_time = redis.call("TIME")
+local microseconds = current_time[2]
+while string.len(microseconds) < 6 do
+    -- in case the microseconds part (i.e. current_time[2]) is less than 6 digits
+    microseconds = "0" .. microseconds
+end
+
+local current_time_micro = tonumber(current_time[1] .. microseconds)
+current_time = current_time_micro - jan_1_2017
+
+-- only the counters of the current and previous fixed windows are kept
+local window = math.floor(current_time / period)
+local counters = redis.call("HMGET", key, "window", "current", "previous")
+local current = tonumber(counters[2]) or 0
+local previous = tonumber(counters[3]) or 0
+local counters_window = tonumber(counters[1])
+if counters_window ~= window then
+    if counters_window == window - 1 then
+        previous = current
+    else
+        previous = 0
+    end
+    current = 0
+end
+
+-- the previous counter is weighted by the portion of the previous window still overlapping the sliding one
+local elapsed = current_time - window * period
+local estimated = previous * (period - elapsed) / period + current
+if estimated + cost > rate then
+    local retry_after
+    if current + cost <= rate and previous > 0 then
+        -- within the current window, once enough of the previous window slid out
+        retry_after = period * (1 - (rate - current - cost) / previous) - elapsed
+    else
+        -- within the next window, once enough of the current window slid out
+        retry_after = period - elapsed
+        if current > 0 then
+            retry_after = retry_after + math.max(0, period * (1 - (rate - cost) / current))
+        end
+    end
+    return {
+        current_time_micro,
+        0, -- allowed
+        math.max(1, math.ceil(retry_after)),
+    }
+end
+
+current = current + cost
+redis.call("HSET", key, "window", window, "current", current, "previous", previous)
+-- the counters are needed until the end of the next window, then both would be reset
+redis.call("PEXPIRE", key, math.ceil((2 * period - elapsed) / 1000))
+
+return {
+    current_time_micro,
+    cost,
+    0, -- no retry_after
+}
@@ -0,0 +1,60 @@
+--[[
+To debug this script you can add these entries here in the script and then check the Redis server output:
+redis.log(redis.LOG_NOTICE, "some label", some_variable)
+
+For more information please refer to: https://redis.io/docs/manual/programmability/lua-debugging/
+--]]
+
+local key = KEYS[1]
+local capacity = tonumber(ARGV[1])
+local rate = tonumber(ARGV[2])
+local period = tonumber(ARGV[3]) * 1000 * 1000 -- converting to microseconds
+local cost = tonumber(ARGV[4])
+local refill_interval = period / rate -- microseconds needed to refill one token
+
+-- see gcra.lua for why the time is relative to Jan 1, 2017 00:00:00 GMT
+local jan_1_2017 = 1483228800 * 1000 * 1000 -- in microseconds precision
+local current_time = redis.call("TIME")
+local microseconds = current_time[2]
+while string.len(microseconds) < 6 do
+    -- in case the microseconds part (i.e. current_time[2]) is less than 6 digits
+    microseconds = "0" .. microseconds
+end
+
+local current_time_micro = tonumber(current_time[1] .. microseconds)
+current_time = current_time_micro - jan_1_2017
+
+-- a missing bucket is a full one
+local bucket = redis.call("HMGET", key, "tokens", "last_refill")
+local tokens = tonumber(bucket[1])
+local last_refill = tonumber(bucket[2])
+if not tokens or not last_refill then
+    tokens = capacity
+    last_refill = current_time
+end
+
+local elapsed = current_time - last_refill
+if elapsed > 0 then
+    tokens = math.min(capacity, tokens + elapsed / refill_interval)
+    last_refill = current_time
+end
+
+local allowed = 0
+local retry_after = 0
+if tokens < cost then
+    retry_after = math.ceil((cost - tokens) * refill_interval)
+else
+    allowed = cost
+    tokens = tokens - cost
+end
+
+-- once full again the bucket is the same as a missing one, hence it expires then
+local full_after = math.ceil((capacity - tokens) * refill_interval / 1000) -- in milliseconds
+redis.call("HSET", key, "tokens", tokens, "last_refill", last_refill)
+redis.call("PEXPIRE", key, math.max(1, full_after))
+
+return {
+    current_time_micro,
+    allowed,
+    retry_after,
+}
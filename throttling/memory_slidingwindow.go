@@ -0,0 +1,87 @@
+package throttling
+
+import (
+	"math"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/rudderlabs/rudder-go-kit/cachettl"
+)
+
+// slidingWindow is an in-memory sliding window counter. It keeps only the counters of the current and previous fixed
+// windows, estimating the requests in the sliding window by weighting the previous counter with the portion of the
+// previous window still overlapping the sliding one.
+type slidingWindow struct {
+	mu      sync.Mutex
+	store   *cachettl.Cache[string, *windowCounters]
+	timeNow func() time.Time
+}
+
+type windowCounters struct {
+	window   int64 // index of the current window since the epoch
+	current  int64
+	previous int64
+}
+
+func (sw *slidingWindow) limit(key string, cost, rate, period int64) (bool, time.Duration) {
+	sw.mu.Lock()
+	defer sw.mu.Unlock()
+
+	if sw.store == nil {
+		sw.store = cachettl.New[string, *windowCounters]()
+	}
+	if sw.timeNow == nil {
+		sw.timeNow = time.Now
+	}
+
+	var (
+		now       = sw.timeNow().UnixNano()
+		periodDur = period * int64(time.Second)
+		window    = now / periodDur
+		cacheKey  = key + ":" + strconv.FormatInt(rate, 10) + ":" + strconv.FormatInt(period, 10)
+	)
+	c := sw.store.Get(cacheKey)
+	if c == nil {
+		c = &windowCounters{window: window}
+	}
+	if c.window != window {
+		if c.window == window-1 {
+			c.previous = c.current
+		} else {
+			c.previous = 0
+		}
+		c.current = 0
+		c.window = window
+	}
+
+	elapsed := now - window*periodDur
+	allowed, retryAfter := slidingWindowAllow(cost, rate, c.current, c.previous, elapsed, periodDur)
+	if allowed {
+		c.current += cost
+	}
+	// the counters are needed until the end of the next window, then both would be reset
+	sw.store.Put(cacheKey, c, time.Duration(2*periodDur-elapsed))
+	return allowed, retryAfter
+}
+
+// slidingWindowAllow returns whether cost fits within the rate given the counters of the current and previous windows
+// and the time elapsed since the start of the current window, otherwise the time after which it would fit
+func slidingWindowAllow(cost, rate, current, previous, elapsed, period int64) (bool, time.Duration) {
+	weight := float64(period-elapsed) / float64(period)
+	if float64(previous)*weight+float64(current+cost) <= float64(rate) {
+		return true, 0
+	}
+	var retryAfter float64
+	if current+cost <= rate && previous > 0 {
+		// within the current window, once enough of the previous window slid out
+		retryAfter = float64(period)*(1-float64(rate-current-cost)/float64(previous)) - float64(elapsed)
+	} else {
+		// within the next window, once enough of the current window slid out
+		retryAfter = float64(period - elapsed)
+		if current > 0 {
+			retryAfter += math.Max(0, float64(period)*(1-float64(rate-cost)/float64(current)))
+		}
+	}
+	return false, time.Duration(math.Max(1, math.Ceil(retryAfter)))
+}
@@ -0,0 +1,44 @@
+package throttling
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemorySlidingWindow(t *testing.T) {
+	var (
+		rate   = int64(10)
+		period = int64(10)
+		start  = time.Unix(1_000_000, 0) // at the start of a window
+		now    = start
+		l      = &slidingWindow{timeNow: func() time.Time { return now }}
+	)
+
+	allowed, _ := l.limit("key", rate, rate, period)
+	require.True(t, allowed)
+	allowed, retryAfter := l.limit("key", 1, rate, period)
+	require.False(t, allowed)
+	require.Equal(t, 11*time.Second, retryAfter, "10% of the next window has to elapse")
+
+	// the previous window overlaps the sliding one by 75%, so 7.5 requests are estimated
+	now = start.Add(12500 * time.Millisecond)
+	allowed, _ = l.limit("key", 2, rate, period)
+	require.True(t, allowed)
+	allowed, retryAfter = l.limit("key", 1, rate, period)
+	require.False(t, allowed)
+	require.InDelta(t, 500*time.Millisecond, retryAfter, float64(time.Microsecond), "the previous window has to overlap by 70% only")
+
+	now = now.Add(retryAfter)
+	allowed, _ = l.limit("key", 1, rate, period)
+	require.True(t, allowed)
+
+	// the previous window is not overlapping anymore after a whole window
+	now = start.Add(30 * time.Second)
+	allowed, _ = l.limit("key", rate, rate, period)
+	require.True(t, allowed)
+
+	allowed, _ = l.limit("other-key", rate, rate, period)
+	require.True(t, allowed, "counters are per key")
+}
@@ -0,0 +1,62 @@
+package throttling
+
+import (
+	"math"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/rudderlabs/rudder-go-kit/cachettl"
+)
+
+// tokenBucket is an in-memory token bucket. Buckets hold up to "capacity" tokens and are refilled with "rate" tokens
+// every "period" seconds, so that bursts are bounded by the capacity regardless of the refill rate.
+type tokenBucket struct {
+	mu      sync.Mutex
+	store   *cachettl.Cache[string, *bucket]
+	timeNow func() time.Time
+}
+
+type bucket struct {
+	tokens     float64
+	lastRefill time.Time
+}
+
+func (tb *tokenBucket) limit(key string, cost, capacity, rate, period int64) (bool, time.Duration) {
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
+
+	if tb.store == nil {
+		tb.store = cachettl.New[string, *bucket]()
+	}
+	if tb.timeNow == nil {
+		tb.timeNow = time.Now
+	}
+
+	var (
+		now            = tb.timeNow()
+		refillInterval = float64(period) * float64(time.Second) / float64(rate) // time to refill one token
+		cacheKey       = key + ":" + strconv.FormatInt(capacity, 10) + ":" + strconv.FormatInt(rate, 10) + ":" + strconv.FormatInt(period, 10)
+	)
+	b := tb.store.Get(cacheKey)
+	if b == nil {
+		b = &bucket{tokens: float64(capacity), lastRefill: now}
+	}
+	if elapsed := now.Sub(b.lastRefill); elapsed > 0 {
+		b.tokens = math.Min(float64(capacity), b.tokens+float64(elapsed)/refillInterval)
+		b.lastRefill = now
+	}
+
+	var (
+		allowed    = b.tokens >= float64(cost)
+		retryAfter time.Duration
+	)
+	if allowed {
+		b.tokens -= float64(cost)
+	} else {
+		retryAfter = time.Duration(math.Ceil((float64(cost) - b.tokens) * refillInterval))
+	}
+	// once full again the bucket is the same as a new one, hence it is cached only until then
+	tb.store.Put(cacheKey, b, time.Duration(math.Ceil((float64(capacity)-b.tokens)*refillInterval)))
+	return allowed, retryAfter
+}
@@ -0,0 +1,47 @@
+package throttling
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryTokenBucket(t *testing.T) {
+	now := time.Now()
+	l := &tokenBucket{timeNow: func() time.Time { return now }}
+
+	var (
+		capacity = int64(5)
+		rate     = int64(1)
+		period   = int64(2)
+	)
+
+	allowed, _ := l.limit("key", capacity, capacity, rate, period)
+	require.True(t, allowed, "it should be able to empty a full bucket")
+
+	allowed, retryAfter := l.limit("key", 1, capacity, rate, period)
+	require.False(t, allowed)
+	require.Equal(t, 2*time.Second, retryAfter, "one token is refilled every 2 seconds")
+
+	now = now.Add(time.Second)
+	allowed, retryAfter = l.limit("key", 1, capacity, rate, period)
+	require.False(t, allowed)
+	require.Equal(t, time.Second, retryAfter)
+
+	now = now.Add(5 * time.Second)
+	allowed, _ = l.limit("key", 3, capacity, rate, period)
+	require.True(t, allowed, "3 tokens should have been refilled")
+	allowed, retryAfter = l.limit("key", 1, capacity, rate, period)
+	require.False(t, allowed)
+	require.Equal(t, 2*time.Second, retryAfter)
+
+	now = now.Add(time.Hour)
+	allowed, _ = l.limit("key", capacity, capacity, rate, period)
+	require.True(t, allowed, "the bucket should not be refilled beyond its capacity")
+	allowed, _ = l.limit("key", 1, capacity, rate, period)
+	require.False(t, allowed)
+
+	allowed, _ = l.limit("other-key", capacity, capacity, rate, period)
+	require.True(t, allowed, "buckets are per key")
+}
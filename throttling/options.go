@@ -9,7 +9,7 @@ type Option func(*Limiter)
 // burst as rate if the provided burst is <= 0
 func WithInMemoryGCRA(burst int64) Option {
 	return func(l *Limiter) {
-		l.useGCRA = true
+		l.algo = algoGCRA
 		if burst > 0 {
 			l.gcraBurst = burst
 		}
@@ -20,7 +20,7 @@ func WithInMemoryGCRA(burst int64) Option {
 // burst as rate if the provided burst is <= 0
 func WithRedisGCRA(rc *redis.Client, burst int64) Option {
 	return func(l *Limiter) {
-		l.useGCRA = true
+		l.algo = algoGCRA
 		l.redisSpeaker = rc
 		if burst > 0 {
 			l.gcraBurst = burst
@@ -31,7 +31,48 @@ func WithRedisGCRA(rc *redis.Client, burst int64) Option {
 // WithRedisSortedSet allows to use the Redis SortedSet algorithm for rate limiting
 func WithRedisSortedSet(rc *redis.Client) Option {
 	return func(l *Limiter) {
-		l.useGCRA = false
+		l.algo = algoSortedSet
+		l.redisSpeaker = rc
+	}
+}
+
+// WithInMemoryTokenBucket allows to use the token bucket algorithm (in-memory) with the specified bucket capacity or
+// with the rate as capacity if the provided capacity is <= 0.
+// Buckets are refilled with "rate" tokens every "window" seconds and can hold up to "capacity" tokens, which bounds
+// bursts independently of the window.
+func WithInMemoryTokenBucket(capacity int64) Option {
+	return func(l *Limiter) {
+		l.algo = algoTokenBucket
+		if capacity > 0 {
+			l.tokenBucketCapacity = capacity
+		}
+	}
+}
+
+// WithRedisTokenBucket allows to use the token bucket algorithm (Redis version) with the specified bucket capacity or
+// with the rate as capacity if the provided capacity is <= 0
+func WithRedisTokenBucket(rc *redis.Client, capacity int64) Option {
+	return func(l *Limiter) {
+		l.algo = algoTokenBucket
+		l.redisSpeaker = rc
+		if capacity > 0 {
+			l.tokenBucketCapacity = capacity
+		}
+	}
+}
+
+// WithInMemorySlidingWindow allows to use the sliding window counter algorithm (in-memory).
+// Only two counters are kept per key, approximating the requests in the sliding window by assuming the ones of the
+// previous fixed window were evenly distributed.
+func WithInMemorySlidingWindow() Option {
+	return func(l *Limiter) { l.algo = algoSlidingWindow }
+}
+
+// WithRedisSlidingWindow allows to use the sliding window counter algorithm (Redis version), requiring far less
+// memory on Redis than the SortedSet algorithm at the cost of some precision
+func WithRedisSlidingWindow(rc *redis.Client) Option {
+	return func(l *Limiter) {
+		l.algo = algoSlidingWindow
 		l.redisSpeaker = rc
 	}
 }
@@ -26,13 +26,30 @@ var (
 	//go:embed lua/sortedset.lua
 	sortedSetLua    string
 	sortedSetScript *redis.Script
+	//go:embed lua/tokenbucket.lua
+	tokenBucketLua         string
+	tokenBucketRedisScript *redis.Script
+	//go:embed lua/slidingwindow.lua
+	slidingWindowLua         string
+	slidingWindowRedisScript *redis.Script
 )
 
 func init() {
 	gcraRedisScript = redis.NewScript(gcraLua)
 	sortedSetScript = redis.NewScript(sortedSetLua)
+	tokenBucketRedisScript = redis.NewScript(tokenBucketLua)
+	slidingWindowRedisScript = redis.NewScript(slidingWindowLua)
 }
 
+type algorithm uint8
+
+const (
+	algoGCRA algorithm = iota
+	algoSortedSet
+	algoTokenBucket
+	algoSlidingWindow
+)
+
 type redisSpeaker interface {
 	redis.Scripter
 	redisSortedSetRemover
@@ -50,11 +67,14 @@ type Limiter struct {
 	redisSpeaker redisSpeaker
 
 	// for in-memory configurations
-	gcra *gcra
+	gcra          *gcra
+	tokenBucket   *tokenBucket
+	slidingWindow *slidingWindow
 
 	// other flags
-	useGCRA   bool
-	gcraBurst int64
+	algo                algorithm
+	gcraBurst           int64
+	tokenBucketCapacity int64
 
 	// metrics
 	statsCollector statsCollector
@@ -81,9 +101,16 @@ func New(options ...Option) (*Limiter, error) {
 	if rl.redisSpeaker != nil {
 		return rl, nil
 	}
-	// Default to in-memory GCRA
-	rl.gcra = &gcra{}
-	rl.useGCRA = true
+	switch rl.algo {
+	case algoTokenBucket:
+		rl.tokenBucket = &tokenBucket{}
+	case algoSlidingWindow:
+		rl.slidingWindow = &slidingWindow{}
+	default:
+		// Default to in-memory GCRA
+		rl.gcra = &gcra{}
+		rl.algo = algoGCRA
+	}
 	return rl, nil
 }
 
@@ -120,10 +147,19 @@ func (l *Limiter) allow(ctx context.Context, cost, rate, window int64, key strin
 	}
 
 	if l.redisSpeaker != nil {
-		if l.useGCRA {
+		switch l.algo {
+		case algoGCRA:
 			defer l.getTimer(key, "redis-gcra", rate, window)()
 			_, allowed, retryAfter, tr, err := l.redisGCRA(ctx, cost, rate, window, key)
 			return allowed, retryAfter, tr, err
+		case algoTokenBucket:
+			defer l.getTimer(key, "redis-token-bucket", rate, window)()
+			_, allowed, retryAfter, tr, err := l.redisTokenBucket(ctx, cost, rate, window, key)
+			return allowed, retryAfter, tr, err
+		case algoSlidingWindow:
+			defer l.getTimer(key, "redis-sliding-window", rate, window)()
+			_, allowed, retryAfter, tr, err := l.redisSlidingWindow(ctx, cost, rate, window, key)
+			return allowed, retryAfter, tr, err
 		}
 
 		defer l.getTimer(key, "redis-sorted-set", rate, window)()
@@ -131,6 +167,15 @@ func (l *Limiter) allow(ctx context.Context, cost, rate, window int64, key strin
 		return allowed, retryAfter, tr, err
 	}
 
+	switch l.algo {
+	case algoTokenBucket:
+		defer l.getTimer(key, "token-bucket", rate, window)()
+		return l.tokenBucketLimit(cost, rate, window, key)
+	case algoSlidingWindow:
+		defer l.getTimer(key, "sliding-window", rate, window)()
+		return l.slidingWindowLimit(cost, rate, window, key)
+	}
+
 	defer l.getTimer(key, "gcra", rate, window)()
 	allowed, retryAfter, tr, err := l.gcraLimit(ctx, cost, rate, window, key)
 	return allowed, retryAfter, tr, err
@@ -220,6 +265,65 @@ func (l *Limiter) redisGCRA(ctx context.Context, cost, rate, window int64, key s
 	return redisTime, true, 0, r.Return, nil
 }
 
+func (l *Limiter) redisTokenBucket(ctx context.Context, cost, rate, window int64, key string) (
+	time.Duration, bool, time.Duration, func(context.Context) error, error,
+) {
+	capacity := l.getTokenBucketCapacity(rate)
+	if cost > capacity {
+		return 0, false, 0, nil, fmt.Errorf("cost must not be greater than the bucket capacity %d", capacity)
+	}
+	return l.runRedisScript(ctx, tokenBucketRedisScript, "TokenBucket", key, capacity, rate, window, cost)
+}
+
+func (l *Limiter) redisSlidingWindow(ctx context.Context, cost, rate, window int64, key string) (
+	time.Duration, bool, time.Duration, func(context.Context) error, error,
+) {
+	if cost > rate {
+		return 0, false, 0, nil, fmt.Errorf("cost must not be greater than the rate")
+	}
+	return l.runRedisScript(ctx, slidingWindowRedisScript, "SlidingWindow", key, cost, rate, window)
+}
+
+// runRedisScript runs a script returning the Redis time, the allowed cost (0 if the limit is exceeded) and the
+// time after which to retry in microseconds
+func (l *Limiter) runRedisScript(ctx context.Context, script *redis.Script, name, key string, args ...any) (
+	time.Duration, bool, time.Duration, func(context.Context) error, error,
+) {
+	res, err := script.Run(ctx, l.redisSpeaker, []string{key}, args...).Result()
+	if err != nil {
+		return 0, false, 0, nil, fmt.Errorf("could not run %s Redis script: %v", name, err)
+	}
+
+	result, ok := res.([]any)
+	if !ok {
+		return 0, false, 0, nil, fmt.Errorf("unexpected result from %s Redis script of type %T: %v", name, res, res)
+	}
+	if len(result) != 3 {
+		return 0, false, 0, nil, fmt.Errorf("unexpected result from %s Redis script of length %d: %+v", name, len(result), result)
+	}
+
+	t, ok := result[0].(int64)
+	if !ok {
+		return 0, false, 0, nil, fmt.Errorf("unexpected result[0] from %s Redis script of type %T: %v", name, result[0], result[0])
+	}
+	redisTime := time.Duration(t) * time.Microsecond
+
+	allowed, ok := result[1].(int64)
+	if !ok {
+		return redisTime, false, 0, nil, fmt.Errorf("unexpected result[1] from %s Redis script of type %T: %v", name, result[1], result[1])
+	}
+	if allowed < 1 { // limit exceeded
+		retryAfter, ok := result[2].(int64)
+		if !ok {
+			return redisTime, false, 0, nil, fmt.Errorf("unexpected result[2] from %s Redis script of type %T: %v", name, result[2], result[2])
+		}
+		return redisTime, false, time.Duration(retryAfter) * time.Microsecond, nil, nil
+	}
+
+	r := &unsupportedReturn{}
+	return redisTime, true, 0, r.Return, nil
+}
+
 func (l *Limiter) gcraLimit(ctx context.Context, cost, rate, window int64, key string) (
 	bool, time.Duration, func(context.Context) error, error,
 ) {
@@ -238,6 +342,43 @@ func (l *Limiter) gcraLimit(ctx context.Context, cost, rate, window int64, key s
 	return true, 0, r.Return, nil
 }
 
+func (l *Limiter) tokenBucketLimit(cost, rate, window int64, key string) (
+	bool, time.Duration, func(context.Context) error, error,
+) {
+	capacity := l.getTokenBucketCapacity(rate)
+	if cost > capacity {
+		return false, 0, nil, fmt.Errorf("cost must not be greater than the bucket capacity %d", capacity)
+	}
+	allowed, retryAfter := l.tokenBucket.limit(key, cost, capacity, rate, window)
+	if !allowed {
+		return false, retryAfter, nil, nil // limit exceeded
+	}
+	r := &unsupportedReturn{}
+	return true, 0, r.Return, nil
+}
+
+func (l *Limiter) slidingWindowLimit(cost, rate, window int64, key string) (
+	bool, time.Duration, func(context.Context) error, error,
+) {
+	if cost > rate {
+		return false, 0, nil, fmt.Errorf("cost must not be greater than the rate")
+	}
+	allowed, retryAfter := l.slidingWindow.limit(key, cost, rate, window)
+	if !allowed {
+		return false, retryAfter, nil, nil // limit exceeded
+	}
+	r := &unsupportedReturn{}
+	return true, 0, r.Return, nil
+}
+
+// getTokenBucketCapacity returns the configured bucket capacity, or the rate if none was configured
+func (l *Limiter) getTokenBucketCapacity(rate int64) int64 {
+	if l.tokenBucketCapacity > 0 {
+		return l.tokenBucketCapacity
+	}
+	return rate
+}
+
 func (l *Limiter) getTimer(key, algo string, rate, window int64) func() {
 	m := l.statsCollector.NewTaggedStat("throttling", stats.TimerType, l.statsTagger(key, algo, rate, window))
 	start := time.Now()
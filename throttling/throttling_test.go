@@ -48,6 +48,26 @@ func TestThrottling(t *testing.T) {
 				limiter:     newLimiter(t, WithRedisSortedSet(rc)),
 				concurrency: 5000,
 			},
+			{
+				name:        "token bucket",
+				limiter:     newLimiter(t, WithInMemoryTokenBucket(0)),
+				concurrency: 100,
+			},
+			{
+				name:        "token bucket redis",
+				limiter:     newLimiter(t, WithRedisTokenBucket(rc, 0)),
+				concurrency: 100,
+			},
+			{
+				name:        "sliding window",
+				limiter:     newLimiter(t, WithInMemorySlidingWindow()),
+				concurrency: 100,
+			},
+			{
+				name:        "sliding window redis",
+				limiter:     newLimiter(t, WithRedisSlidingWindow(rc)),
+				concurrency: 100,
+			},
 		}
 	)
 
@@ -88,19 +108,28 @@ func testLimiter(
 
 	run := func() (allowed bool, redisTime time.Duration, err error) {
 		switch {
-		case l.redisSpeaker != nil && l.useGCRA:
+		case l.redisSpeaker != nil && l.algo == algoGCRA:
 			redisTime, allowed, _, _, err = l.redisGCRA(ctx, cost, rate, window, key)
-		case l.redisSpeaker != nil && !l.useGCRA:
+		case l.redisSpeaker != nil && l.algo == algoTokenBucket:
+			redisTime, allowed, _, _, err = l.redisTokenBucket(ctx, cost, rate, window, key)
+		case l.redisSpeaker != nil && l.algo == algoSlidingWindow:
+			redisTime, allowed, _, _, err = l.redisSlidingWindow(ctx, cost, rate, window, key)
+		case l.redisSpeaker != nil:
 			redisTime, allowed, _, _, err = l.redisSortedSet(ctx, cost, rate, window, key)
 		default:
 			allowed, _, err = l.Allow(ctx, cost, rate, window, key)
 		}
 		return allowed, redisTime, err
 	}
-	if l.useGCRA { // warm up the GCRA algorithms
+	switch l.algo {
+	case algoGCRA, algoTokenBucket: // warm up the GCRA and token bucket algorithms by consuming the burst
 		for i := 0; i < int(rate); i++ {
 			_, _, _ = run()
 		}
+	case algoSlidingWindow: // warm up the sliding window for a whole window so that the previous counter is saturated
+		for deadline := time.Now().Add(time.Duration(window) * time.Second); time.Now().Before(deadline); {
+			_, _, _ = run()
+		}
 	}
 loop:
 
@@ -253,9 +282,13 @@ func TestBadData(t *testing.T) {
 		ctx      = context.Background()
 		rc       = bootstrapRedis(ctx, t, pool)
 		limiters = map[string]*Limiter{
-			"gcra":              newLimiter(t, WithInMemoryGCRA(0)),
-			"gcra redis":        newLimiter(t, WithRedisGCRA(rc, 0)),
-			"sorted sets redis": newLimiter(t, WithRedisSortedSet(rc)),
+			"gcra":                 newLimiter(t, WithInMemoryGCRA(0)),
+			"gcra redis":           newLimiter(t, WithRedisGCRA(rc, 0)),
+			"sorted sets redis":    newLimiter(t, WithRedisSortedSet(rc)),
+			"token bucket":         newLimiter(t, WithInMemoryTokenBucket(0)),
+			"token bucket redis":   newLimiter(t, WithRedisTokenBucket(rc, 0)),
+			"sliding window":       newLimiter(t, WithInMemorySlidingWindow()),
+			"sliding window redis": newLimiter(t, WithRedisSlidingWindow(rc)),
 		}
 	)
 
@@ -344,6 +377,54 @@ func TestRetryAfter(t *testing.T) {
 				expectedSleepsCount:       3,
 				expectedSleepsCountDelta:  0, // this algorithm is the most precise but requires more memory on Redis
 			},
+			{
+				name:                      "token bucket",
+				limiter:                   newLimiter(t, WithInMemoryTokenBucket(0)),
+				rate:                      2,
+				window:                    1,
+				runFor:                    3 * time.Second,
+				warmUp:                    true,
+				expectedAllowedCount:      6,
+				expectedAllowedCountDelta: 3,
+				expectedSleepsCount:       3,
+				expectedSleepsCountDelta:  2,
+			},
+			{
+				name:                      "token bucket redis",
+				limiter:                   newLimiter(t, WithRedisTokenBucket(rc, 0)),
+				rate:                      2,
+				window:                    1,
+				runFor:                    3 * time.Second,
+				warmUp:                    true,
+				expectedAllowedCount:      6,
+				expectedAllowedCountDelta: 3,
+				expectedSleepsCount:       3,
+				expectedSleepsCountDelta:  2,
+			},
+			{
+				name:                      "sliding window",
+				limiter:                   newLimiter(t, WithInMemorySlidingWindow()),
+				rate:                      2,
+				window:                    1,
+				runFor:                    3 * time.Second,
+				warmUp:                    true,
+				expectedAllowedCount:      6,
+				expectedAllowedCountDelta: 3,
+				expectedSleepsCount:       3,
+				expectedSleepsCountDelta:  2,
+			},
+			{
+				name:                      "sliding window redis",
+				limiter:                   newLimiter(t, WithRedisSlidingWindow(rc)),
+				rate:                      2,
+				window:                    1,
+				runFor:                    3 * time.Second,
+				warmUp:                    true,
+				expectedAllowedCount:      6,
+				expectedAllowedCountDelta: 3,
+				expectedSleepsCount:       3,
+				expectedSleepsCountDelta:  2,
+			},
 		}
 	)
 